@@ -0,0 +1,584 @@
+package bot
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
+)
+
+// raidClusterWindow is how far back joins are compared for account-age, name
+// and avatar clusters; raids that trickle in slower than the join threshold
+// still tend to share these
+const raidClusterWindow = 5 * time.Minute
+
+// raidLockdownDeny is what @everyone loses in text channels during a lockdown
+const raidLockdownDeny = discordgo.PermissionSendMessages |
+	discordgo.PermissionSendMessagesInThreads |
+	discordgo.PermissionCreatePublicThreads |
+	discordgo.PermissionCreatePrivateThreads |
+	discordgo.PermissionAddReactions
+
+// RaidLockdown is what was changed when a lockdown started, so it can be undone
+// (also after a restart)
+type RaidLockdown struct {
+	GuildID              string
+	Reason               string
+	StartedAt            time.Time
+	EndsAt               time.Time
+	PreviousVerification int // -1 if the verification level wasn't raised
+	Channels             []LockedChannel
+}
+
+// LockedChannel is the @everyone overwrite a channel had before the lockdown
+type LockedChannel struct {
+	ChannelID    string
+	HadOverwrite bool
+	Allow        int64
+	Deny         int64
+}
+
+// raidJoin is one tracked join
+type raidJoin struct {
+	UserID    string
+	Username  string
+	Avatar    string
+	JoinedAt  time.Time
+	CreatedAt time.Time
+}
+
+// ============================================================================
+// RAID MONITOR - Per-guild join tracking
+// ============================================================================
+
+type RaidMonitor struct {
+	bot    *Bot
+	guilds map[string]*raidTracker
+	mu     sync.Mutex
+	lockMu sync.Mutex // Serialises starting and ending lockdowns
+}
+
+type raidTracker struct {
+	joins    []raidJoin
+	actioned map[string]time.Time // Raid members already kicked/banned
+}
+
+func NewRaidMonitor(b *Bot) *RaidMonitor {
+	return &RaidMonitor{
+		bot:    b,
+		guilds: make(map[string]*raidTracker),
+	}
+}
+
+// HandleJoin records a join and responds if it's part of a raid
+func (rm *RaidMonitor) HandleJoin(m *discordgo.GuildMemberAdd) {
+	defer RecoverFromPanic("RaidMonitor.HandleJoin")
+
+	settings := rm.bot.GetRaidSettings(m.GuildID)
+	if !settings.Enabled {
+		return
+	}
+
+	now := time.Now()
+	created, _ := discordgo.SnowflakeTimestamp(m.User.ID)
+	join := raidJoin{
+		UserID:    m.User.ID,
+		Username:  m.User.Username,
+		Avatar:    m.User.Avatar,
+		JoinedAt:  now,
+		CreatedAt: created,
+	}
+
+	rm.mu.Lock()
+	tracker, exists := rm.guilds[m.GuildID]
+	if !exists {
+		tracker = &raidTracker{actioned: make(map[string]time.Time)}
+		rm.guilds[m.GuildID] = tracker
+	}
+
+	keep := max(raidClusterWindow, time.Duration(settings.WindowSeconds)*time.Second)
+	tracker.prune(now.Add(-keep))
+	tracker.joins = append(tracker.joins, join)
+
+	active := rm.bot.GetRaidStatus(m.GuildID).Active
+	var reasons, wave []string
+	if active {
+		// Everyone joining during a lockdown is treated as part of the wave
+		wave = []string{join.UserID}
+	} else {
+		reasons, wave = detectRaid(tracker.joins, settings, now)
+	}
+
+	// Don't act on the same member twice
+	pending := wave[:0:0]
+	for _, userID := range wave {
+		if _, done := tracker.actioned[userID]; !done {
+			tracker.actioned[userID] = now
+			pending = append(pending, userID)
+		}
+	}
+	rm.mu.Unlock()
+
+	if len(reasons) > 0 {
+		reason := strings.Join(reasons, "; ")
+		log.Printf("🚨 Raid detected in guild %s: %s", m.GuildID, reason)
+		if err := rm.bot.startRaid(m.GuildID, reason, settings, len(pending)); err != nil {
+			log.Printf("Failed to start raid lockdown in %s: %v", m.GuildID, err)
+		}
+	}
+
+	if len(pending) > 0 && settings.Action != commands.RaidActionNone {
+		go rm.bot.actOnRaidMembers(m.GuildID, settings.Action, pending)
+	}
+}
+
+// forget drops a guild's tracked joins so the raid that just ended doesn't
+// immediately trigger another lockdown
+func (rm *RaidMonitor) forget(guildID string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	delete(rm.guilds, guildID)
+}
+
+// prune forgets joins older than cutoff
+func (t *raidTracker) prune(cutoff time.Time) {
+	kept := t.joins[:0]
+	for _, join := range t.joins {
+		if join.JoinedAt.After(cutoff) {
+			kept = append(kept, join)
+		}
+	}
+	t.joins = kept
+
+	for userID, at := range t.actioned {
+		if at.Before(cutoff) {
+			delete(t.actioned, userID)
+		}
+	}
+}
+
+// detectRaid looks for a join spike or a cluster of look-alike accounts among
+// recent joins. It returns why it thinks this is a raid and who is part of it.
+func detectRaid(joins []raidJoin, settings *commands.RaidSettings, now time.Time) (reasons, wave []string) {
+	inWave := make(map[string]bool)
+	addWave := func(group []raidJoin) {
+		for _, join := range group {
+			if !inWave[join.UserID] {
+				inWave[join.UserID] = true
+				wave = append(wave, join.UserID)
+			}
+		}
+	}
+
+	// Join rate
+	window := time.Duration(settings.WindowSeconds) * time.Second
+	var recent []raidJoin
+	for _, join := range joins {
+		if now.Sub(join.JoinedAt) <= window {
+			recent = append(recent, join)
+		}
+	}
+	if len(recent) >= settings.JoinThreshold {
+		reasons = append(reasons, fmt.Sprintf("%d joins in %ds", len(recent), settings.WindowSeconds))
+		addWave(recent)
+	}
+
+	// Clusters only need half the threshold, since each one is suspicious on its own
+	clusterSize := max(3, (settings.JoinThreshold+1)/2)
+	if len(joins) < clusterSize {
+		return reasons, wave
+	}
+
+	// New accounts created around the same time
+	if settings.AccountAgeDays > 0 {
+		maxAge := time.Duration(settings.AccountAgeDays) * 24 * time.Hour
+		var young []raidJoin
+		for _, join := range joins {
+			if !join.CreatedAt.IsZero() && now.Sub(join.CreatedAt) < maxAge {
+				young = append(young, join)
+			}
+		}
+		if group := largestAgeCluster(young, 24*time.Hour); len(group) >= clusterSize {
+			reasons = append(reasons, fmt.Sprintf("%d accounts under %d days old created within a day of each other",
+				len(group), settings.AccountAgeDays))
+			addWave(group)
+		}
+	}
+
+	// Similar usernames
+	byName := make(map[string][]raidJoin)
+	for _, join := range joins {
+		if stem := nameStem(join.Username); len(stem) >= 3 {
+			byName[stem] = append(byName[stem], join)
+		}
+	}
+	for stem, group := range byName {
+		if len(group) >= clusterSize {
+			reasons = append(reasons, fmt.Sprintf("%d similar usernames (%s…)", len(group), stem))
+			addWave(group)
+		}
+	}
+
+	// Identical custom avatars
+	byAvatar := make(map[string][]raidJoin)
+	for _, join := range joins {
+		if join.Avatar != "" {
+			byAvatar[join.Avatar] = append(byAvatar[join.Avatar], join)
+		}
+	}
+	for _, group := range byAvatar {
+		if len(group) >= clusterSize {
+			reasons = append(reasons, fmt.Sprintf("%d joins with the same avatar", len(group)))
+			addWave(group)
+		}
+	}
+
+	return reasons, wave
+}
+
+// largestAgeCluster returns the biggest group of accounts created within span
+// of each other
+func largestAgeCluster(joins []raidJoin, span time.Duration) []raidJoin {
+	sorted := append([]raidJoin(nil), joins...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].CreatedAt.Before(sorted[j].CreatedAt) })
+
+	var best []raidJoin
+	start := 0
+	for end := range sorted {
+		for sorted[end].CreatedAt.Sub(sorted[start].CreatedAt) > span {
+			start++
+		}
+		if end-start+1 > len(best) {
+			best = sorted[start : end+1]
+		}
+	}
+	return best
+}
+
+// nameStem reduces a username to its letters so "raider_01" and "Raider.77" match
+func nameStem(username string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(username) {
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// actOnRaidMembers kicks or bans the members of a raid wave
+func (b *Bot) actOnRaidMembers(guildID, action string, userIDs []string) {
+	defer RecoverFromPanic(fmt.Sprintf("actOnRaidMembers(guild=%s)", guildID))
+
+	reason := "[Anti-raid] Joined during a raid"
+	for _, userID := range userIDs {
+		caseAction := commands.CaseKick
+		if action == commands.RaidActionBan {
+			caseAction = commands.CaseBan
+		}
+		if b.blockProtected(guildID, userID, caseAction, b.Session.State.User.ID) {
+			continue
+		}
+
+		var err error
+		if action == commands.RaidActionBan {
+			err = b.Session.GuildBanCreateWithReason(guildID, userID, reason, 1)
+		} else {
+			err = b.Session.GuildMemberDeleteWithReason(guildID, userID, reason)
+		}
+		if err != nil {
+			log.Printf("[Anti-raid] Failed to %s %s in %s: %v", action, userID, guildID, err)
+			continue
+		}
+
+		b.RecordModCase(&commands.ModCase{
+			GuildID:     guildID,
+			Action:      caseAction,
+			UserID:      userID,
+			ModeratorID: b.Session.State.User.ID,
+			Reason:      reason,
+		})
+	}
+}
+
+// ============================================================================
+// SETTINGS & LOCKDOWNS
+// ============================================================================
+
+// defaultRaidSettings is used until a guild configures anti-raid
+func defaultRaidSettings(guildID string) *commands.RaidSettings {
+	return &commands.RaidSettings{
+		GuildID:           guildID,
+		Enabled:           false,
+		JoinThreshold:     10,
+		WindowSeconds:     10,
+		AccountAgeDays:    7,
+		Action:            commands.RaidActionNone,
+		Lockdown:          true,
+		RaiseVerification: true,
+		DurationMinutes:   15,
+	}
+}
+
+// GetRaidSettings returns a guild's anti-raid settings
+func (b *Bot) GetRaidSettings(guildID string) *commands.RaidSettings {
+	if b.RaidSettingsCache != nil {
+		// Cached by value, so commands can edit what they get
+		if settings, ok := b.RaidSettingsCache.Get(guildID); ok {
+			return &settings
+		}
+	}
+
+	settings, err := b.DB.GetRaidSettings(guildID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error loading anti-raid settings for guild %s: %v", guildID, err)
+		}
+		settings = defaultRaidSettings(guildID)
+	}
+
+	if b.RaidSettingsCache != nil {
+		b.RaidSettingsCache.Set(guildID, *settings)
+	}
+	return settings
+}
+
+// SaveRaidSettings stores a guild's anti-raid settings
+func (b *Bot) SaveRaidSettings(settings *commands.RaidSettings) error {
+	if err := b.DB.SaveRaidSettings(settings); err != nil {
+		return err
+	}
+	if b.RaidSettingsCache != nil {
+		b.RaidSettingsCache.Invalidate(settings.GuildID)
+	}
+	return nil
+}
+
+// GetRaidStatus reports whether a guild is currently locked down
+func (b *Bot) GetRaidStatus(guildID string) commands.RaidStatus {
+	if b.RaidStatusCache != nil {
+		if status, ok := b.RaidStatusCache.Get(guildID); ok {
+			return status
+		}
+	}
+
+	var status commands.RaidStatus
+	lockdown, err := b.DB.GetRaidLockdown(guildID)
+	if err == nil {
+		status = commands.RaidStatus{
+			Active:    true,
+			StartedAt: lockdown.StartedAt,
+			EndsAt:    lockdown.EndsAt,
+			Reason:    lockdown.Reason,
+		}
+	} else if err != sql.ErrNoRows {
+		// Not cached, so the next join tries again
+		log.Printf("Error loading raid lockdown for guild %s: %v", guildID, err)
+		return status
+	}
+
+	if b.RaidStatusCache != nil {
+		b.RaidStatusCache.Set(guildID, status)
+	}
+	return status
+}
+
+// invalidateRaidStatus drops a guild's cached lockdown status once a
+// lockdown starts or ends
+func (b *Bot) invalidateRaidStatus(guildID string) {
+	if b.RaidStatusCache != nil {
+		b.RaidStatusCache.Invalidate(guildID)
+	}
+}
+
+// StartRaid locks a guild down by hand
+func (b *Bot) StartRaid(guildID, reason string) error {
+	return b.startRaid(guildID, reason, b.GetRaidSettings(guildID), 0)
+}
+
+// startRaid applies the guild's raid response: channel lockdown, a higher
+// verification level, a scheduled end and an alert in the log channel. Starting
+// a raid that is already running does nothing.
+func (b *Bot) startRaid(guildID, reason string, settings *commands.RaidSettings, waveSize int) error {
+	b.RaidMonitor.lockMu.Lock()
+	defer b.RaidMonitor.lockMu.Unlock()
+
+	if b.GetRaidStatus(guildID).Active {
+		return nil
+	}
+
+	guild, err := b.Session.State.Guild(guildID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	lockdown := &RaidLockdown{
+		GuildID:              guildID,
+		Reason:               reason,
+		StartedAt:            now,
+		EndsAt:               now.Add(time.Duration(settings.DurationMinutes) * time.Minute),
+		PreviousVerification: -1,
+	}
+
+	if settings.Lockdown {
+		for _, channel := range guild.Channels {
+			if channel.Type != discordgo.ChannelTypeGuildText &&
+				channel.Type != discordgo.ChannelTypeGuildNews &&
+				channel.Type != discordgo.ChannelTypeGuildForum {
+				continue
+			}
+			locked := LockedChannel{ChannelID: channel.ID}
+			for _, overwrite := range channel.PermissionOverwrites {
+				if overwrite.ID == guildID && overwrite.Type == discordgo.PermissionOverwriteTypeRole {
+					locked.HadOverwrite = true
+					locked.Allow, locked.Deny = overwrite.Allow, overwrite.Deny
+				}
+			}
+			// Channels @everyone already can't talk in stay as they are
+			if locked.Deny&discordgo.PermissionSendMessages != 0 {
+				continue
+			}
+			lockdown.Channels = append(lockdown.Channels, locked)
+		}
+	}
+
+	raiseTo := discordgo.VerificationLevelHigh
+	if settings.RaiseVerification && guild.VerificationLevel < raiseTo {
+		lockdown.PreviousVerification = int(guild.VerificationLevel)
+	}
+
+	// Saved before anything changes so a crash mid-lockdown can still be undone
+	if err := b.DB.SaveRaidLockdown(lockdown); err != nil {
+		return err
+	}
+	b.invalidateRaidStatus(guildID)
+
+	failed := 0
+	for _, locked := range lockdown.Channels {
+		allow := locked.Allow &^ raidLockdownDeny
+		deny := locked.Deny | raidLockdownDeny
+		if err := b.Session.ChannelPermissionSet(locked.ChannelID, guildID,
+			discordgo.PermissionOverwriteTypeRole, allow, deny); err != nil {
+			DebugLog("[Anti-raid] Failed to lock channel %s: %v", locked.ChannelID, err)
+			failed++
+		}
+	}
+
+	if lockdown.PreviousVerification >= 0 {
+		if _, err := b.Session.GuildEdit(guildID, &discordgo.GuildParams{VerificationLevel: &raiseTo}); err != nil {
+			log.Printf("[Anti-raid] Failed to raise verification level in %s: %v", guildID, err)
+		}
+	}
+
+	if err := b.ScheduleAction(&commands.ScheduledAction{
+		GuildID:   guildID,
+		Action:    commands.ScheduledEndRaid,
+		ExecuteAt: lockdown.EndsAt,
+	}); err != nil {
+		log.Printf("[Anti-raid] Failed to schedule lockdown end in %s: %v", guildID, err)
+	}
+
+	response := []string{}
+	if settings.Lockdown {
+		response = append(response, fmt.Sprintf("🔒 Locked %d channels", len(lockdown.Channels)-failed))
+	}
+	if lockdown.PreviousVerification >= 0 {
+		response = append(response, "🛡️ Verification level raised to High")
+	}
+	if waveSize > 0 && settings.Action != commands.RaidActionNone {
+		response = append(response, fmt.Sprintf("🔨 %d raid members %s", waveSize, raidActionVerb(settings.Action)))
+	}
+	if len(response) == 0 {
+		response = append(response, "Alert only")
+	}
+
+	b.sendRaidAlert(guildID, &discordgo.MessageEmbed{
+		Title:       "🚨 Raid Detected",
+		Description: reason,
+		Color:       0xFF0000,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Response", Value: strings.Join(response, "\n"), Inline: false},
+			{Name: "Lifts", Value: fmt.Sprintf("<t:%d:R> (or `anti-raid end`)", lockdown.EndsAt.Unix()), Inline: false},
+		},
+		Timestamp: now.Format(time.RFC3339),
+	})
+	return nil
+}
+
+// EndRaid restores the channels and verification level changed by a lockdown
+func (b *Bot) EndRaid(guildID, reason string) error {
+	b.RaidMonitor.lockMu.Lock()
+	defer b.RaidMonitor.lockMu.Unlock()
+
+	lockdown, err := b.DB.GetRaidLockdown(guildID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, locked := range lockdown.Channels {
+		if locked.HadOverwrite {
+			err = b.Session.ChannelPermissionSet(locked.ChannelID, guildID,
+				discordgo.PermissionOverwriteTypeRole, locked.Allow, locked.Deny)
+		} else {
+			err = b.Session.ChannelPermissionDelete(locked.ChannelID, guildID)
+		}
+		if err != nil {
+			DebugLog("[Anti-raid] Failed to unlock channel %s: %v", locked.ChannelID, err)
+		}
+	}
+
+	if lockdown.PreviousVerification >= 0 {
+		level := discordgo.VerificationLevel(lockdown.PreviousVerification)
+		if _, err := b.Session.GuildEdit(guildID, &discordgo.GuildParams{VerificationLevel: &level}); err != nil {
+			log.Printf("[Anti-raid] Failed to restore verification level in %s: %v", guildID, err)
+		}
+	}
+
+	if err := b.DB.DeleteRaidLockdown(guildID); err != nil {
+		return err
+	}
+	b.invalidateRaidStatus(guildID)
+	b.RaidMonitor.forget(guildID)
+	b.CancelScheduledActions(guildID, "", commands.ScheduledEndRaid)
+
+	log.Printf("🔓 Raid lockdown lifted in guild %s: %s", guildID, reason)
+
+	b.sendRaidAlert(guildID, &discordgo.MessageEmbed{
+		Title:       "🔓 Raid Lockdown Lifted",
+		Description: reason,
+		Color:       0x43CC24,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Started", Value: fmt.Sprintf("<t:%d:R>", lockdown.StartedAt.Unix()), Inline: true},
+			{Name: "Channels Restored", Value: fmt.Sprintf("%d", len(lockdown.Channels)), Inline: true},
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	})
+	return nil
+}
+
+// sendRaidAlert posts to the guild's mod action log channel
+func (b *Bot) sendRaidAlert(guildID string, embed *discordgo.MessageEmbed) {
+	config, err := b.GetLoggingConfigCached(guildID)
+	if err != nil || !config.Enabled || config.ChannelFor(commands.LogModActions) == "" {
+		DebugLog("[Anti-raid] No log channel for alert in %s", guildID)
+		return
+	}
+	b.LogDelivery.Send(guildID, config.ChannelFor(commands.LogModActions), embed)
+}
+
+func raidActionVerb(action string) string {
+	if action == commands.RaidActionBan {
+		return "banned"
+	}
+	return "kicked"
+}
@@ -0,0 +1,377 @@
+package bot
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
+)
+
+// customEmojiRegex matches <:name:id> and <a:name:id>
+var customEmojiRegex = regexp.MustCompile(`<a?:\w+:\d+>`)
+
+// capsMinLetters keeps short shouts like "OK" or "LOL" from tripping the caps detector
+const capsMinLetters = 12
+
+// ============================================================================
+// ANTI-SPAM SETTINGS - Checked on every message
+// ============================================================================
+
+// defaultAntiSpamSettings is used for detectors a guild hasn't configured.
+// The default ladder stops at timeouts; kicks and bans are opt-in through
+// anti-spam escalation.
+func defaultAntiSpamSettings(sfs *commands.SpamFilterSettings) *commands.AntiSpamSettings {
+	flood := sfs.MaxConsecutiveMessages
+	if flood <= 0 {
+		flood = 6
+	}
+
+	detector := func(name string, threshold, window int) *commands.SpamDetector {
+		return &commands.SpamDetector{Name: name, Enabled: true, Threshold: threshold, WindowSeconds: window}
+	}
+
+	return &commands.AntiSpamSettings{
+		GuildID: sfs.GuildID,
+		Detectors: map[string]*commands.SpamDetector{
+			commands.DetectorFlood:     detector(commands.DetectorFlood, flood, 5),
+			commands.DetectorDuplicate: detector(commands.DetectorDuplicate, 3, 30),
+			commands.DetectorMentions:  detector(commands.DetectorMentions, 8, 15),
+			commands.DetectorEmoji:     detector(commands.DetectorEmoji, 15, 0),
+			commands.DetectorCaps:      detector(commands.DetectorCaps, 80, 0),
+			commands.DetectorNewlines:  detector(commands.DetectorNewlines, 20, 0),
+		},
+		Escalation: []commands.EscalationStep{
+			{Action: commands.EscalateDelete},
+			{Action: commands.EscalateWarn},
+			{Action: commands.EscalateTimeout, Duration: 10 * time.Minute},
+			{Action: commands.EscalateTimeout, Duration: time.Hour},
+		},
+		StrikeResetMinutes: 60,
+	}
+}
+
+// GetAntiSpamSettings returns a guild's detectors, filling gaps with the defaults
+func (b *Bot) GetAntiSpamSettings(guildID string) *commands.AntiSpamSettings {
+	if b.AntiSpamCache != nil {
+		if settings, ok := b.AntiSpamCache.Get(guildID); ok {
+			return copyAntiSpamSettings(settings)
+		}
+	}
+
+	settings := defaultAntiSpamSettings(b.GetSpamFilterSettings(guildID))
+	if err := b.DB.LoadAntiSpamSettings(settings); err != nil && err != sql.ErrNoRows {
+		log.Printf("Error loading anti-spam settings for guild %s: %v", guildID, err)
+	}
+
+	if b.AntiSpamCache != nil {
+		b.AntiSpamCache.Set(guildID, settings)
+	}
+	return copyAntiSpamSettings(settings)
+}
+
+// copyAntiSpamSettings copies the detector map and the escalation ladder
+func copyAntiSpamSettings(settings *commands.AntiSpamSettings) *commands.AntiSpamSettings {
+	copied := *settings
+	copied.Detectors = make(map[string]*commands.SpamDetector, len(settings.Detectors))
+	for name, detector := range settings.Detectors {
+		d := *detector
+		copied.Detectors[name] = &d
+	}
+	copied.Escalation = append([]commands.EscalationStep(nil), settings.Escalation...)
+	return &copied
+}
+
+// SaveAntiSpamSettings stores a guild's detectors and escalation ladder
+func (b *Bot) SaveAntiSpamSettings(settings *commands.AntiSpamSettings) error {
+	if err := b.DB.SaveAntiSpamSettings(settings); err != nil {
+		return err
+	}
+	if b.AntiSpamCache != nil {
+		b.AntiSpamCache.Invalidate(settings.GuildID)
+	}
+	return nil
+}
+
+// ResetAntiSpamSettings drops a guild's overrides
+func (b *Bot) ResetAntiSpamSettings(guildID string) error {
+	if err := b.DB.DeleteAntiSpamSettings(guildID); err != nil {
+		return err
+	}
+	if b.AntiSpamCache != nil {
+		b.AntiSpamCache.Invalidate(guildID)
+	}
+	return nil
+}
+
+// ============================================================================
+// SPAM TRACKER - Sliding windows of recent messages per user
+// ============================================================================
+
+type SpamTracker struct {
+	users    map[spamKey]*spamHistory
+	mu       sync.Mutex
+	messages int // Messages seen since the last sweep
+}
+
+type spamKey struct {
+	guildID string
+	userID  string
+}
+
+type spamHistory struct {
+	messages   []spamMessage
+	strikes    int
+	lastStrike time.Time
+}
+
+type spamMessage struct {
+	at        time.Time
+	id        string
+	channelID string
+	content   string // Normalised for duplicate detection
+	mentions  int
+}
+
+// spamSweepEvery is how many messages pass between sweeps of idle users
+const spamSweepEvery = 1000
+
+func NewSpamTracker() *SpamTracker {
+	return &SpamTracker{users: make(map[spamKey]*spamHistory)}
+}
+
+// Check records a message and runs the guild's detectors on it. It returns nil
+// if nothing fired.
+func (st *SpamTracker) Check(m *discordgo.MessageCreate, settings *commands.AntiSpamSettings) *FilterResult {
+	now := time.Now()
+
+	// Per-message detectors don't need history
+	reason, detector := checkMessageContent(m.Content, settings)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.messages++
+	if st.messages >= spamSweepEvery {
+		st.sweep(now)
+		st.messages = 0
+	}
+
+	key := spamKey{m.GuildID, m.Author.ID}
+	history, exists := st.users[key]
+	if !exists {
+		history = &spamHistory{}
+		st.users[key] = history
+	}
+
+	history.messages = append(history.messages, spamMessage{
+		at:        now,
+		id:        m.ID,
+		channelID: m.ChannelID,
+		content:   normaliseSpamContent(m.Content),
+		mentions:  countMentions(m),
+	})
+	history.prune(now.Add(-maxSpamWindow(settings)))
+
+	var related []spamMessage
+	if detector == "" {
+		reason, detector, related = history.checkWindows(now, settings)
+	}
+	if detector == "" {
+		return nil
+	}
+
+	// Start counting afresh so one burst is one strike
+	history.messages = nil
+
+	reset := time.Duration(settings.StrikeResetMinutes) * time.Minute
+	if now.Sub(history.lastStrike) > reset {
+		history.strikes = 0
+	}
+	history.strikes++
+	history.lastStrike = now
+
+	ladder := settings.Escalation
+	if len(ladder) == 0 {
+		ladder = []commands.EscalationStep{{Action: commands.EscalateDelete}}
+	}
+	step := ladder[min(history.strikes, len(ladder))-1]
+
+	result := &FilterResult{
+		ShouldTakeAction: true,
+		Action:           FilterAction(step.Action),
+		Reason:           fmt.Sprintf("Spam (%s): %s — strike %d", detector, reason, history.strikes),
+		Duration:         step.Duration,
+		Detector:         detector,
+	}
+	for _, msg := range related {
+		if msg.id != m.ID {
+			result.addRelated(msg.channelID, msg.id)
+		}
+	}
+	return result
+}
+
+// Forget drops a user's history, e.g. when they turn out to be exempt
+func (st *SpamTracker) Forget(guildID, userID string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.users, spamKey{guildID, userID})
+}
+
+// sweep drops users who have gone quiet. Strikes are kept for the longest
+// reset a guild can configure.
+func (st *SpamTracker) sweep(now time.Time) {
+	for key, history := range st.users {
+		history.prune(now.Add(-5 * time.Minute))
+		if len(history.messages) == 0 && now.Sub(history.lastStrike) > 7*24*time.Hour {
+			delete(st.users, key)
+		}
+	}
+}
+
+// prune forgets messages older than cutoff
+func (h *spamHistory) prune(cutoff time.Time) {
+	i := 0
+	for i < len(h.messages) && h.messages[i].at.Before(cutoff) {
+		i++
+	}
+	h.messages = h.messages[i:]
+}
+
+// checkWindows runs the detectors that look across several messages. It
+// returns the messages that were part of the spam so they can be cleaned up.
+func (h *spamHistory) checkWindows(now time.Time, settings *commands.AntiSpamSettings) (string, string, []spamMessage) {
+	within := func(seconds int) []spamMessage {
+		cutoff := now.Add(-time.Duration(seconds) * time.Second)
+		for i, msg := range h.messages {
+			if !msg.at.Before(cutoff) {
+				return h.messages[i:]
+			}
+		}
+		return nil
+	}
+	latest := h.messages[len(h.messages)-1]
+
+	if d := settings.Detectors[commands.DetectorFlood]; d != nil && d.Enabled {
+		if recent := within(d.WindowSeconds); len(recent) >= d.Threshold {
+			return fmt.Sprintf("%d messages in %ds", len(recent), d.WindowSeconds), d.Name, recent
+		}
+	}
+
+	if d := settings.Detectors[commands.DetectorDuplicate]; d != nil && d.Enabled && latest.content != "" {
+		var same []spamMessage
+		for _, msg := range within(d.WindowSeconds) {
+			if msg.content == latest.content {
+				same = append(same, msg)
+			}
+		}
+		if len(same) >= d.Threshold {
+			return fmt.Sprintf("%d identical messages in %ds", len(same), d.WindowSeconds), d.Name, same
+		}
+	}
+
+	if d := settings.Detectors[commands.DetectorMentions]; d != nil && d.Enabled && latest.mentions > 0 {
+		total := 0
+		var mentioning []spamMessage
+		for _, msg := range within(d.WindowSeconds) {
+			if msg.mentions > 0 {
+				total += msg.mentions
+				mentioning = append(mentioning, msg)
+			}
+		}
+		if total >= d.Threshold {
+			return fmt.Sprintf("%d mentions in %ds", total, d.WindowSeconds), d.Name, mentioning
+		}
+	}
+
+	return "", "", nil
+}
+
+// checkMessageContent runs the detectors that only look at one message
+func checkMessageContent(content string, settings *commands.AntiSpamSettings) (string, string) {
+	if content == "" {
+		return "", ""
+	}
+
+	if d := settings.Detectors[commands.DetectorNewlines]; d != nil && d.Enabled {
+		if lines := strings.Count(content, "\n"); lines >= d.Threshold {
+			return fmt.Sprintf("%d line breaks", lines), d.Name
+		}
+	}
+
+	if d := settings.Detectors[commands.DetectorEmoji]; d != nil && d.Enabled {
+		if emoji := countEmoji(content); emoji >= d.Threshold {
+			return fmt.Sprintf("%d emoji", emoji), d.Name
+		}
+	}
+
+	if d := settings.Detectors[commands.DetectorCaps]; d != nil && d.Enabled {
+		// Mentions and custom emoji are all digits/names, not shouting
+		text := customEmojiRegex.ReplaceAllString(content, "")
+		letters, upper := 0, 0
+		for _, r := range text {
+			if unicode.IsLetter(r) {
+				letters++
+				if unicode.IsUpper(r) {
+					upper++
+				}
+			}
+		}
+		if letters >= capsMinLetters && upper*100 >= d.Threshold*letters {
+			return fmt.Sprintf("%d%% capitals", upper*100/letters), d.Name
+		}
+	}
+
+	return "", ""
+}
+
+// maxSpamWindow is how much history the windowed detectors need
+func maxSpamWindow(settings *commands.AntiSpamSettings) time.Duration {
+	longest := 0
+	for _, d := range settings.Detectors {
+		if d.Windowed() && d.Enabled && d.WindowSeconds > longest {
+			longest = d.WindowSeconds
+		}
+	}
+	return time.Duration(longest) * time.Second
+}
+
+// normaliseSpamContent makes "Hello  World" and "hello world" count as duplicates
+func normaliseSpamContent(content string) string {
+	return strings.Join(strings.Fields(strings.ToLower(content)), " ")
+}
+
+// countMentions counts distinct users and roles pinged by a message
+func countMentions(m *discordgo.MessageCreate) int {
+	seen := make(map[string]bool)
+	for _, user := range m.Mentions {
+		if user.ID != m.Author.ID {
+			seen[user.ID] = true
+		}
+	}
+	for _, roleID := range m.MentionRoles {
+		seen["role:"+roleID] = true
+	}
+	return len(seen)
+}
+
+// countEmoji counts custom emoji and unicode pictographs
+func countEmoji(content string) int {
+	count := len(customEmojiRegex.FindAllStringIndex(content, -1))
+	content = customEmojiRegex.ReplaceAllString(content, "")
+
+	for _, r := range content {
+		// Symbol-other covers emoji; joiners and variation selectors are skipped
+		if unicode.Is(unicode.So, r) || (r >= 0x1F1E6 && r <= 0x1F1FF) {
+			count++
+		}
+	}
+	return count
+}
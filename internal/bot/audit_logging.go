@@ -0,0 +1,861 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// newAccountAge is how young an account has to be for join logs to flag it
+const newAccountAge = 7 * 24 * time.Hour
+
+// ============================================================================
+// AUDIT SNAPSHOTS - What roles, emojis and settings looked like before an event
+// ============================================================================
+
+// discordgo updates its state before handlers run, so role, emoji and server
+// setting changes are diffed against our own copy instead
+
+type AuditSnapshots struct {
+	guilds map[string]*guildSnapshot
+	mu     sync.Mutex
+}
+
+type guildSnapshot struct {
+	settings guildSettings
+	roles    map[string]discordgo.Role
+	emojis   map[string]discordgo.Emoji
+}
+
+// guildSettings are the server settings guild update logs compare
+type guildSettings struct {
+	Name                        string
+	Icon                        string
+	Banner                      string
+	Description                 string
+	OwnerID                     string
+	AfkChannelID                string
+	AfkTimeout                  int
+	SystemChannelID             string
+	RulesChannelID              string
+	VanityURLCode               string
+	VerificationLevel           discordgo.VerificationLevel
+	ExplicitContentFilter       discordgo.ExplicitContentFilterLevel
+	DefaultMessageNotifications discordgo.MessageNotifications
+	MfaLevel                    discordgo.MfaLevel
+}
+
+func NewAuditSnapshots() *AuditSnapshots {
+	return &AuditSnapshots{
+		guilds: make(map[string]*guildSnapshot),
+	}
+}
+
+func settingsOf(g *discordgo.Guild) guildSettings {
+	return guildSettings{
+		Name:                        g.Name,
+		Icon:                        g.Icon,
+		Banner:                      g.Banner,
+		Description:                 g.Description,
+		OwnerID:                     g.OwnerID,
+		AfkChannelID:                g.AfkChannelID,
+		AfkTimeout:                  g.AfkTimeout,
+		SystemChannelID:             g.SystemChannelID,
+		RulesChannelID:              g.RulesChannelID,
+		VanityURLCode:               g.VanityURLCode,
+		VerificationLevel:           g.VerificationLevel,
+		ExplicitContentFilter:       g.ExplicitContentFilter,
+		DefaultMessageNotifications: g.DefaultMessageNotifications,
+		MfaLevel:                    g.MfaLevel,
+	}
+}
+
+// Store records a guild as it arrived in GUILD_CREATE
+func (as *AuditSnapshots) Store(g *discordgo.Guild) {
+	snapshot := &guildSnapshot{
+		settings: settingsOf(g),
+		roles:    make(map[string]discordgo.Role, len(g.Roles)),
+		emojis:   make(map[string]discordgo.Emoji, len(g.Emojis)),
+	}
+	for _, r := range g.Roles {
+		snapshot.roles[r.ID] = *r
+	}
+	for _, e := range g.Emojis {
+		snapshot.emojis[e.ID] = *e
+	}
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.guilds[g.ID] = snapshot
+}
+
+func (as *AuditSnapshots) Remove(guildID string) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	delete(as.guilds, guildID)
+}
+
+// SwapSettings stores new settings and returns the old ones
+func (as *AuditSnapshots) SwapSettings(g *discordgo.Guild) (guildSettings, bool) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	snapshot, ok := as.guilds[g.ID]
+	if !ok {
+		return guildSettings{}, false
+	}
+	old := snapshot.settings
+	snapshot.settings = settingsOf(g)
+	return old, true
+}
+
+// SwapRole stores a role (or deletes it when role is nil) and returns the old one
+func (as *AuditSnapshots) SwapRole(guildID, roleID string, role *discordgo.Role) (discordgo.Role, bool) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	snapshot, ok := as.guilds[guildID]
+	if !ok {
+		return discordgo.Role{}, false
+	}
+	old, existed := snapshot.roles[roleID]
+	if role == nil {
+		delete(snapshot.roles, roleID)
+	} else {
+		snapshot.roles[roleID] = *role
+	}
+	return old, existed
+}
+
+// SwapEmojis stores a guild's emoji list and returns the old one
+func (as *AuditSnapshots) SwapEmojis(guildID string, emojis []*discordgo.Emoji) (map[string]discordgo.Emoji, bool) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	snapshot, ok := as.guilds[guildID]
+	if !ok {
+		return nil, false
+	}
+	old := snapshot.emojis
+	snapshot.emojis = make(map[string]discordgo.Emoji, len(emojis))
+	for _, e := range emojis {
+		snapshot.emojis[e.ID] = *e
+	}
+	return old, true
+}
+
+func (b *Bot) onGuildCreateAudit(s *discordgo.Session, g *discordgo.GuildCreate) {
+	if g.Unavailable {
+		return
+	}
+	b.AuditSnapshots.Store(g.Guild)
+}
+
+func (b *Bot) onGuildDeleteAudit(s *discordgo.Session, g *discordgo.GuildDelete) {
+	if g.Unavailable {
+		return
+	}
+	b.AuditSnapshots.Remove(g.ID)
+}
+
+// ============================================================================
+// AUDIT LOG HANDLERS
+// ============================================================================
+
+// auditLogChannel returns where to post an audit event, or "" if the guild
+// doesn't log this type. channelID is the channel the event is about, so its
+// channel override applies; guild-wide events pass "".
+func (b *Bot) auditLogChannel(guildID, channelID, logType string) string {
+	config, err := b.GetLoggingConfigCached(guildID)
+	if err != nil || !config.Enabled || !config.Logs(logType) {
+		return ""
+	}
+	logChannelID := config.ChannelFor(logType)
+	if logChannelID == "" {
+		return ""
+	}
+
+	if channelID != "" {
+		if enabled, _ := b.IsChannelLoggingEnabled(guildID, channelID, logType); !enabled {
+			return ""
+		}
+	}
+	return logChannelID
+}
+
+func (b *Bot) sendAuditLog(guildID, logChannelID string, embed *discordgo.MessageEmbed) {
+	embed.Timestamp = time.Now().Format(time.RFC3339)
+	b.LogDelivery.Send(guildID, logChannelID, embed)
+}
+
+// onMemberJoinLogging logs joins with the account's age
+func (b *Bot) onMemberJoinLogging(s *discordgo.Session, m *discordgo.GuildMemberAdd) {
+	logChannelID := b.auditLogChannel(m.GuildID, "", "member_join")
+	if logChannelID == "" || m.User == nil {
+		return
+	}
+
+	created, _ := discordgo.SnowflakeTimestamp(m.User.ID)
+	age := time.Since(created)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Member Joined",
+		Description: fmt.Sprintf("<@%s> (%s)", m.User.ID, m.User.String()),
+		Color:       0x2ecc71,
+		Thumbnail:   &discordgo.MessageEmbedThumbnail{URL: m.User.AvatarURL("")},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Account Created", Value: fmt.Sprintf("<t:%d:F>", created.Unix()), Inline: true},
+			{Name: "Account Age", Value: formatAccountAge(age), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: "User ID: " + m.User.ID},
+	}
+	if m.User.Bot {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Bot", Value: "Yes", Inline: true})
+	}
+	if age < newAccountAge {
+		embed.Color = 0xf39c12
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "⚠️ New Account",
+			Value:  "Created less than a week ago",
+			Inline: false,
+		})
+	}
+
+	b.sendAuditLog(m.GuildID, logChannelID, embed)
+}
+
+// onMemberLeaveLogging logs leaves (kicks included; Discord doesn't tell them apart)
+func (b *Bot) onMemberLeaveLogging(s *discordgo.Session, m *discordgo.GuildMemberRemove) {
+	logChannelID := b.auditLogChannel(m.GuildID, "", "member_leave")
+	if logChannelID == "" || m.User == nil {
+		return
+	}
+
+	created, _ := discordgo.SnowflakeTimestamp(m.User.ID)
+	embed := &discordgo.MessageEmbed{
+		Title:       "Member Left",
+		Description: fmt.Sprintf("<@%s> (%s)", m.User.ID, m.User.String()),
+		Color:       0xe74c3c,
+		Thumbnail:   &discordgo.MessageEmbedThumbnail{URL: m.User.AvatarURL("")},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Account Age", Value: formatAccountAge(time.Since(created)), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: "User ID: " + m.User.ID},
+	}
+	if !m.JoinedAt.IsZero() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Joined",
+			Value:  fmt.Sprintf("<t:%d:R>", m.JoinedAt.Unix()),
+			Inline: true,
+		})
+	}
+
+	b.sendAuditLog(m.GuildID, logChannelID, embed)
+}
+
+func (b *Bot) onGuildBanAddLogging(s *discordgo.Session, e *discordgo.GuildBanAdd) {
+	logChannelID := b.auditLogChannel(e.GuildID, "", "member_ban")
+	if logChannelID == "" || e.User == nil {
+		return
+	}
+
+	reason := "No reason given"
+	if ban, err := s.GuildBan(e.GuildID, e.User.ID); err == nil && ban.Reason != "" {
+		reason = truncateLogField(ban.Reason)
+	}
+
+	b.sendAuditLog(e.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:       "Member Banned",
+		Description: fmt.Sprintf("<@%s> (%s)", e.User.ID, e.User.String()),
+		Color:       0xc0392b,
+		Thumbnail:   &discordgo.MessageEmbedThumbnail{URL: e.User.AvatarURL("")},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Reason", Value: reason, Inline: false},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: "User ID: " + e.User.ID},
+	})
+}
+
+func (b *Bot) onGuildBanRemoveLogging(s *discordgo.Session, e *discordgo.GuildBanRemove) {
+	logChannelID := b.auditLogChannel(e.GuildID, "", "member_unban")
+	if logChannelID == "" || e.User == nil {
+		return
+	}
+
+	b.sendAuditLog(e.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:       "Member Unbanned",
+		Description: fmt.Sprintf("<@%s> (%s)", e.User.ID, e.User.String()),
+		Color:       0x2ecc71,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "User ID: " + e.User.ID},
+	})
+}
+
+// logMemberRoleChanges logs roles granted to or removed from a member
+func (b *Bot) logMemberRoleChanges(m *discordgo.GuildMemberUpdate) {
+	if m.BeforeUpdate == nil || m.User == nil {
+		return
+	}
+	logChannelID := b.auditLogChannel(m.GuildID, "", "member_role_change")
+	if logChannelID == "" {
+		return
+	}
+
+	added := missingFrom(m.Roles, m.BeforeUpdate.Roles)
+	removed := missingFrom(m.BeforeUpdate.Roles, m.Roles)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Member Roles Updated",
+		Description: fmt.Sprintf("<@%s> (%s)", m.User.ID, m.User.String()),
+		Color:       0x3498db,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "User ID: " + m.User.ID},
+	}
+	if len(added) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Granted", Value: roleMentions(added), Inline: false})
+	}
+	if len(removed) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Removed", Value: roleMentions(removed), Inline: false})
+	}
+
+	b.sendAuditLog(m.GuildID, logChannelID, embed)
+}
+
+func (b *Bot) onGuildRoleCreateLogging(s *discordgo.Session, e *discordgo.GuildRoleCreate) {
+	b.AuditSnapshots.SwapRole(e.GuildID, e.Role.ID, e.Role)
+
+	logChannelID := b.auditLogChannel(e.GuildID, "", "role_change")
+	if logChannelID == "" {
+		return
+	}
+
+	b.sendAuditLog(e.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:       "Role Created",
+		Description: fmt.Sprintf("<@&%s> (`%s`)", e.Role.ID, e.Role.Name),
+		Color:       0x2ecc71,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Colour", Value: fmt.Sprintf("#%06x", e.Role.Color), Inline: true},
+			{Name: "Hoisted", Value: yesNo(e.Role.Hoist), Inline: true},
+			{Name: "Mentionable", Value: yesNo(e.Role.Mentionable), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: "Role ID: " + e.Role.ID},
+	})
+}
+
+func (b *Bot) onGuildRoleUpdateLogging(s *discordgo.Session, e *discordgo.GuildRoleUpdate) {
+	old, ok := b.AuditSnapshots.SwapRole(e.GuildID, e.Role.ID, e.Role)
+	if !ok {
+		return
+	}
+
+	logChannelID := b.auditLogChannel(e.GuildID, "", "role_change")
+	if logChannelID == "" {
+		return
+	}
+
+	// Reordering roles sends an update for every role that moved; only
+	// position changed, so nothing below picks it up
+	var changes []*discordgo.MessageEmbedField
+	if old.Name != e.Role.Name {
+		changes = append(changes, changeField("Name", old.Name, e.Role.Name))
+	}
+	if old.Color != e.Role.Color {
+		changes = append(changes, changeField("Colour", fmt.Sprintf("#%06x", old.Color), fmt.Sprintf("#%06x", e.Role.Color)))
+	}
+	if old.Hoist != e.Role.Hoist {
+		changes = append(changes, changeField("Hoisted", yesNo(old.Hoist), yesNo(e.Role.Hoist)))
+	}
+	if old.Mentionable != e.Role.Mentionable {
+		changes = append(changes, changeField("Mentionable", yesNo(old.Mentionable), yesNo(e.Role.Mentionable)))
+	}
+	if old.Permissions != e.Role.Permissions {
+		granted := permissionNames(e.Role.Permissions &^ old.Permissions)
+		revoked := permissionNames(old.Permissions &^ e.Role.Permissions)
+		if len(granted) > 0 {
+			changes = append(changes, &discordgo.MessageEmbedField{Name: "Permissions Granted", Value: truncateLogField(strings.Join(granted, ", ")), Inline: false})
+		}
+		if len(revoked) > 0 {
+			changes = append(changes, &discordgo.MessageEmbedField{Name: "Permissions Revoked", Value: truncateLogField(strings.Join(revoked, ", ")), Inline: false})
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	b.sendAuditLog(e.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:       "Role Updated",
+		Description: fmt.Sprintf("<@&%s>", e.Role.ID),
+		Color:       0xf39c12,
+		Fields:      changes,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Role ID: " + e.Role.ID},
+	})
+}
+
+func (b *Bot) onGuildRoleDeleteLogging(s *discordgo.Session, e *discordgo.GuildRoleDelete) {
+	old, ok := b.AuditSnapshots.SwapRole(e.GuildID, e.RoleID, nil)
+
+	logChannelID := b.auditLogChannel(e.GuildID, "", "role_change")
+	if logChannelID == "" {
+		return
+	}
+
+	name := "Unknown role"
+	if ok {
+		name = "`" + old.Name + "`"
+	}
+
+	b.sendAuditLog(e.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:       "Role Deleted",
+		Description: name,
+		Color:       0xe74c3c,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Role ID: " + e.RoleID},
+	})
+}
+
+func (b *Bot) onChannelCreateLogging(s *discordgo.Session, c *discordgo.ChannelCreate) {
+	if c.GuildID == "" {
+		return
+	}
+	logChannelID := b.auditLogChannel(c.GuildID, c.ID, "channel_change")
+	if logChannelID == "" {
+		return
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Type", Value: channelTypeName(c.Type), Inline: true},
+	}
+	if c.ParentID != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Category", Value: fmt.Sprintf("<#%s>", c.ParentID), Inline: true})
+	}
+
+	b.sendAuditLog(c.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:       "Channel Created",
+		Description: fmt.Sprintf("<#%s> (`%s`)", c.ID, c.Name),
+		Color:       0x2ecc71,
+		Fields:      fields,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Channel ID: " + c.ID},
+	})
+}
+
+func (b *Bot) onChannelUpdateLogging(s *discordgo.Session, c *discordgo.ChannelUpdate) {
+	if c.GuildID == "" || c.BeforeUpdate == nil {
+		return
+	}
+	logChannelID := b.auditLogChannel(c.GuildID, c.ID, "channel_change")
+	if logChannelID == "" {
+		return
+	}
+
+	old := c.BeforeUpdate
+	var changes []*discordgo.MessageEmbedField
+	if old.Name != c.Name {
+		changes = append(changes, changeField("Name", old.Name, c.Name))
+	}
+	if old.Topic != c.Topic {
+		changes = append(changes, changeField("Topic", old.Topic, c.Topic))
+	}
+	if old.NSFW != c.NSFW {
+		changes = append(changes, changeField("NSFW", yesNo(old.NSFW), yesNo(c.NSFW)))
+	}
+	if old.RateLimitPerUser != c.RateLimitPerUser {
+		changes = append(changes, changeField("Slowmode", fmt.Sprintf("%ds", old.RateLimitPerUser), fmt.Sprintf("%ds", c.RateLimitPerUser)))
+	}
+	if old.ParentID != c.ParentID {
+		changes = append(changes, changeField("Category", channelMention(old.ParentID), channelMention(c.ParentID)))
+	}
+	if old.Bitrate != c.Bitrate {
+		changes = append(changes, changeField("Bitrate", fmt.Sprintf("%dkbps", old.Bitrate/1000), fmt.Sprintf("%dkbps", c.Bitrate/1000)))
+	}
+	if old.UserLimit != c.UserLimit {
+		changes = append(changes, changeField("User Limit", fmt.Sprint(old.UserLimit), fmt.Sprint(c.UserLimit)))
+	}
+	if !sameOverwrites(old.PermissionOverwrites, c.PermissionOverwrites) {
+		changes = append(changes, &discordgo.MessageEmbedField{Name: "Permissions", Value: "Permission overwrites changed", Inline: false})
+	}
+	// Position-only changes come in bursts when channels are dragged around
+	if len(changes) == 0 {
+		return
+	}
+
+	b.sendAuditLog(c.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:       "Channel Updated",
+		Description: fmt.Sprintf("<#%s>", c.ID),
+		Color:       0xf39c12,
+		Fields:      changes,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Channel ID: " + c.ID},
+	})
+}
+
+func (b *Bot) onChannelDeleteLogging(s *discordgo.Session, c *discordgo.ChannelDelete) {
+	if c.GuildID == "" {
+		return
+	}
+	logChannelID := b.auditLogChannel(c.GuildID, c.ID, "channel_change")
+	if logChannelID == "" {
+		return
+	}
+
+	b.sendAuditLog(c.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:       "Channel Deleted",
+		Description: fmt.Sprintf("`%s`", c.Name),
+		Color:       0xe74c3c,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Type", Value: channelTypeName(c.Type), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: "Channel ID: " + c.ID},
+	})
+}
+
+func (b *Bot) onInviteCreateLogging(s *discordgo.Session, i *discordgo.InviteCreate) {
+	if i.GuildID == "" {
+		return
+	}
+	logChannelID := b.auditLogChannel(i.GuildID, i.ChannelID, "invite_change")
+	if logChannelID == "" {
+		return
+	}
+
+	maxUses := "Unlimited"
+	if i.MaxUses > 0 {
+		maxUses = fmt.Sprint(i.MaxUses)
+	}
+	expires := "Never"
+	if i.MaxAge > 0 {
+		expires = fmt.Sprintf("<t:%d:R>", i.CreatedAt.Add(time.Duration(i.MaxAge)*time.Second).Unix())
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Channel", Value: fmt.Sprintf("<#%s>", i.ChannelID), Inline: true},
+		{Name: "Max Uses", Value: maxUses, Inline: true},
+		{Name: "Expires", Value: expires, Inline: true},
+	}
+	if i.Inviter != nil {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Created By", Value: fmt.Sprintf("<@%s>", i.Inviter.ID), Inline: true})
+	}
+	if i.Temporary {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Temporary", Value: "Yes", Inline: true})
+	}
+
+	b.sendAuditLog(i.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:       "Invite Created",
+		Description: fmt.Sprintf("`discord.gg/%s`", i.Code),
+		Color:       0x2ecc71,
+		Fields:      fields,
+	})
+}
+
+func (b *Bot) onInviteDeleteLogging(s *discordgo.Session, i *discordgo.InviteDelete) {
+	if i.GuildID == "" {
+		return
+	}
+	logChannelID := b.auditLogChannel(i.GuildID, i.ChannelID, "invite_change")
+	if logChannelID == "" {
+		return
+	}
+
+	b.sendAuditLog(i.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:       "Invite Deleted",
+		Description: fmt.Sprintf("`discord.gg/%s`", i.Code),
+		Color:       0xe74c3c,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Channel", Value: fmt.Sprintf("<#%s>", i.ChannelID), Inline: true},
+		},
+	})
+}
+
+func (b *Bot) onGuildEmojisUpdateLogging(s *discordgo.Session, e *discordgo.GuildEmojisUpdate) {
+	old, ok := b.AuditSnapshots.SwapEmojis(e.GuildID, e.Emojis)
+	if !ok {
+		return
+	}
+
+	logChannelID := b.auditLogChannel(e.GuildID, "", "emoji_change")
+	if logChannelID == "" {
+		return
+	}
+
+	var added, renamed []string
+	for _, emoji := range e.Emojis {
+		before, existed := old[emoji.ID]
+		switch {
+		case !existed:
+			added = append(added, fmt.Sprintf("%s `:%s:`", emoji.MessageFormat(), emoji.Name))
+		case before.Name != emoji.Name:
+			renamed = append(renamed, fmt.Sprintf("%s `:%s:` → `:%s:`", emoji.MessageFormat(), before.Name, emoji.Name))
+		}
+		delete(old, emoji.ID)
+	}
+	var removed []string
+	for _, emoji := range old {
+		removed = append(removed, fmt.Sprintf("`:%s:`", emoji.Name))
+	}
+	sort.Strings(removed)
+
+	var fields []*discordgo.MessageEmbedField
+	for _, group := range []struct {
+		name   string
+		emojis []string
+	}{
+		{"Added", added},
+		{"Renamed", renamed},
+		{"Removed", removed},
+	} {
+		if len(group.emojis) > 0 {
+			fields = append(fields, &discordgo.MessageEmbedField{Name: group.name, Value: truncateLogField(strings.Join(group.emojis, "\n")), Inline: false})
+		}
+	}
+	if len(fields) == 0 {
+		return
+	}
+
+	b.sendAuditLog(e.GuildID, logChannelID, &discordgo.MessageEmbed{
+		Title:  "Emojis Updated",
+		Color:  0x9b59b6,
+		Fields: fields,
+	})
+}
+
+func (b *Bot) onGuildUpdateLogging(s *discordgo.Session, g *discordgo.GuildUpdate) {
+	old, ok := b.AuditSnapshots.SwapSettings(g.Guild)
+	if !ok {
+		return
+	}
+
+	logChannelID := b.auditLogChannel(g.ID, "", "guild_update")
+	if logChannelID == "" {
+		return
+	}
+
+	now := settingsOf(g.Guild)
+	var changes []*discordgo.MessageEmbedField
+	if old.Name != now.Name {
+		changes = append(changes, changeField("Name", old.Name, now.Name))
+	}
+	if old.Description != now.Description {
+		changes = append(changes, changeField("Description", old.Description, now.Description))
+	}
+	if old.Icon != now.Icon {
+		changes = append(changes, &discordgo.MessageEmbedField{Name: "Icon", Value: "Server icon changed", Inline: false})
+	}
+	if old.Banner != now.Banner {
+		changes = append(changes, &discordgo.MessageEmbedField{Name: "Banner", Value: "Server banner changed", Inline: false})
+	}
+	if old.OwnerID != now.OwnerID {
+		changes = append(changes, changeField("Owner", "<@"+old.OwnerID+">", "<@"+now.OwnerID+">"))
+	}
+	if old.VanityURLCode != now.VanityURLCode {
+		changes = append(changes, changeField("Vanity URL", old.VanityURLCode, now.VanityURLCode))
+	}
+	if old.VerificationLevel != now.VerificationLevel {
+		changes = append(changes, changeField("Verification Level",
+			levelName(verificationLevelNames, int(old.VerificationLevel)), levelName(verificationLevelNames, int(now.VerificationLevel))))
+	}
+	if old.ExplicitContentFilter != now.ExplicitContentFilter {
+		changes = append(changes, changeField("Explicit Content Filter",
+			levelName(contentFilterNames, int(old.ExplicitContentFilter)), levelName(contentFilterNames, int(now.ExplicitContentFilter))))
+	}
+	if old.DefaultMessageNotifications != now.DefaultMessageNotifications {
+		changes = append(changes, changeField("Default Notifications",
+			levelName(notificationNames, int(old.DefaultMessageNotifications)), levelName(notificationNames, int(now.DefaultMessageNotifications))))
+	}
+	if old.MfaLevel != now.MfaLevel {
+		changes = append(changes, changeField("2FA For Moderation",
+			levelName(mfaLevelNames, int(old.MfaLevel)), levelName(mfaLevelNames, int(now.MfaLevel))))
+	}
+	if old.AfkChannelID != now.AfkChannelID {
+		changes = append(changes, changeField("AFK Channel", channelMention(old.AfkChannelID), channelMention(now.AfkChannelID)))
+	}
+	if old.AfkTimeout != now.AfkTimeout {
+		changes = append(changes, changeField("AFK Timeout", fmt.Sprintf("%dm", old.AfkTimeout/60), fmt.Sprintf("%dm", now.AfkTimeout/60)))
+	}
+	if old.SystemChannelID != now.SystemChannelID {
+		changes = append(changes, changeField("System Channel", channelMention(old.SystemChannelID), channelMention(now.SystemChannelID)))
+	}
+	if old.RulesChannelID != now.RulesChannelID {
+		changes = append(changes, changeField("Rules Channel", channelMention(old.RulesChannelID), channelMention(now.RulesChannelID)))
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	b.sendAuditLog(g.ID, logChannelID, &discordgo.MessageEmbed{
+		Title:  "Server Updated",
+		Color:  0xf39c12,
+		Fields: changes,
+	})
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+var (
+	verificationLevelNames = []string{"None", "Low", "Medium", "High", "Very High"}
+	contentFilterNames     = []string{"Off", "Members without roles", "All members"}
+	notificationNames      = []string{"All messages", "Only mentions"}
+	mfaLevelNames          = []string{"Off", "Required"}
+)
+
+func levelName(names []string, level int) string {
+	if level >= 0 && level < len(names) {
+		return names[level]
+	}
+	return fmt.Sprint(level)
+}
+
+// changeField renders a before → after change
+func changeField(name, before, after string) *discordgo.MessageEmbedField {
+	if before == "" {
+		before = "None"
+	}
+	if after == "" {
+		after = "None"
+	}
+	return &discordgo.MessageEmbedField{
+		Name:   name,
+		Value:  truncateLogField(before + " → " + after),
+		Inline: false,
+	}
+}
+
+func truncateLogField(s string) string {
+	if len(s) > 1024 {
+		return s[:1021] + "..."
+	}
+	return s
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
+
+func channelMention(channelID string) string {
+	if channelID == "" {
+		return ""
+	}
+	return "<#" + channelID + ">"
+}
+
+func roleMentions(roleIDs []string) string {
+	mentions := make([]string, len(roleIDs))
+	for i, id := range roleIDs {
+		mentions[i] = "<@&" + id + ">"
+	}
+	return truncateLogField(strings.Join(mentions, ", "))
+}
+
+// missingFrom returns the IDs in a that aren't in b
+func missingFrom(a, b []string) []string {
+	seen := make(map[string]bool, len(b))
+	for _, id := range b {
+		seen[id] = true
+	}
+	var missing []string
+	for _, id := range a {
+		if !seen[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+func sameOverwrites(a, b []*discordgo.PermissionOverwrite) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	byID := make(map[string]discordgo.PermissionOverwrite, len(a))
+	for _, o := range a {
+		byID[o.ID] = *o
+	}
+	for _, o := range b {
+		if old, ok := byID[o.ID]; !ok || old != *o {
+			return false
+		}
+	}
+	return true
+}
+
+// formatAccountAge renders an account age as the two largest units ("2y 3mo", "5d 4h")
+func formatAccountAge(age time.Duration) string {
+	days := int(age.Hours() / 24)
+	switch {
+	case days >= 365:
+		return fmt.Sprintf("%dy %dmo", days/365, days%365/30)
+	case days >= 30:
+		return fmt.Sprintf("%dmo %dd", days/30, days%30)
+	case days >= 1:
+		return fmt.Sprintf("%dd %dh", days, int(age.Hours())%24)
+	case age >= time.Hour:
+		return fmt.Sprintf("%dh %dm", int(age.Hours()), int(age.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm", int(age.Minutes()))
+}
+
+func channelTypeName(t discordgo.ChannelType) string {
+	switch t {
+	case discordgo.ChannelTypeGuildText:
+		return "Text"
+	case discordgo.ChannelTypeGuildVoice:
+		return "Voice"
+	case discordgo.ChannelTypeGuildCategory:
+		return "Category"
+	case discordgo.ChannelTypeGuildNews:
+		return "Announcement"
+	case discordgo.ChannelTypeGuildStageVoice:
+		return "Stage"
+	case discordgo.ChannelTypeGuildForum:
+		return "Forum"
+	}
+	return fmt.Sprintf("Type %d", t)
+}
+
+// permissionNames names the permission bits set in perms
+func permissionNames(perms int64) []string {
+	var names []string
+	for _, p := range []struct {
+		bit  int64
+		name string
+	}{
+		{discordgo.PermissionAdministrator, "Administrator"},
+		{discordgo.PermissionManageServer, "Manage Server"},
+		{discordgo.PermissionManageRoles, "Manage Roles"},
+		{discordgo.PermissionManageChannels, "Manage Channels"},
+		{discordgo.PermissionManageWebhooks, "Manage Webhooks"},
+		{discordgo.PermissionManageEmojis, "Manage Emojis"},
+		{discordgo.PermissionViewAuditLogs, "View Audit Log"},
+		{discordgo.PermissionKickMembers, "Kick Members"},
+		{discordgo.PermissionBanMembers, "Ban Members"},
+		{discordgo.PermissionModerateMembers, "Timeout Members"},
+		{discordgo.PermissionManageMessages, "Manage Messages"},
+		{discordgo.PermissionManageNicknames, "Manage Nicknames"},
+		{discordgo.PermissionMentionEveryone, "Mention Everyone"},
+		{discordgo.PermissionCreateInstantInvite, "Create Invite"},
+		{discordgo.PermissionViewChannel, "View Channels"},
+		{discordgo.PermissionSendMessages, "Send Messages"},
+		{discordgo.PermissionEmbedLinks, "Embed Links"},
+		{discordgo.PermissionAttachFiles, "Attach Files"},
+		{discordgo.PermissionAddReactions, "Add Reactions"},
+		{discordgo.PermissionVoiceConnect, "Connect"},
+		{discordgo.PermissionVoiceSpeak, "Speak"},
+		{discordgo.PermissionVoiceMuteMembers, "Mute Members"},
+		{discordgo.PermissionVoiceDeafenMembers, "Deafen Members"},
+		{discordgo.PermissionVoiceMoveMembers, "Move Members"},
+	} {
+		if perms&p.bit != 0 {
+			names = append(names, p.name)
+			perms &^= p.bit
+		}
+	}
+	if perms != 0 {
+		names = append(names, fmt.Sprintf("Other (%d)", perms))
+	}
+	return names
+}
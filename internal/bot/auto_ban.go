@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
+)
+
+// ============================================================================
+// AUTO-BAN SETTINGS - Observe mode and rule overrides
+// ============================================================================
+
+// defaultAutoBanSettings follows the guild's auto_ban_on_* spam filter settings
+func defaultAutoBanSettings(sfs *commands.SpamFilterSettings) *commands.AutoBanSettings {
+	rule := func(name string, enabled bool) *commands.AutoBanRule {
+		return &commands.AutoBanRule{
+			Name:    name,
+			Enabled: enabled,
+			Step:    commands.EscalationStep{Action: commands.EscalateBan},
+		}
+	}
+
+	return &commands.AutoBanSettings{
+		GuildID: sfs.GuildID,
+		Rules: map[string]*commands.AutoBanRule{
+			commands.AutoBanEveryone:     rule(commands.AutoBanEveryone, sfs.AutoBanOnEveryoneMention),
+			commands.AutoBanHere:         rule(commands.AutoBanHere, sfs.AutoBanOnHereMention),
+			commands.AutoBanUnauthorized: rule(commands.AutoBanUnauthorized, sfs.AutoBanOnUnauthorizedCommands),
+			commands.AutoBanHierarchy:    rule(commands.AutoBanHierarchy, sfs.AutoBanOnHierarchyViolation),
+		},
+	}
+}
+
+// GetAutoBanSettings returns a guild's auto-ban rules with its overrides applied
+func (b *Bot) GetAutoBanSettings(guildID string) *commands.AutoBanSettings {
+	if b.AutoBanCache != nil {
+		if settings, ok := b.AutoBanCache.Get(guildID); ok {
+			return copyAutoBanSettings(settings)
+		}
+	}
+
+	settings := defaultAutoBanSettings(b.GetSpamFilterSettings(guildID))
+	if err := b.DB.LoadAutoBanSettings(settings); err != nil && err != sql.ErrNoRows {
+		log.Printf("Error loading auto-ban settings for guild %s: %v", guildID, err)
+	}
+
+	if b.AutoBanCache != nil {
+		b.AutoBanCache.Set(guildID, settings)
+	}
+	return copyAutoBanSettings(settings)
+}
+
+// copyAutoBanSettings copies the settings down to each rule
+func copyAutoBanSettings(settings *commands.AutoBanSettings) *commands.AutoBanSettings {
+	copied := *settings
+	copied.Rules = make(map[string]*commands.AutoBanRule, len(settings.Rules))
+	for name, rule := range settings.Rules {
+		r := *rule
+		copied.Rules[name] = &r
+	}
+	return &copied
+}
+
+// SaveAutoBanSettings stores observe mode and the overridden rules
+func (b *Bot) SaveAutoBanSettings(settings *commands.AutoBanSettings) error {
+	if err := b.DB.SaveAutoBanSettings(settings); err != nil {
+		return err
+	}
+	if b.AutoBanCache != nil {
+		b.AutoBanCache.Invalidate(settings.GuildID)
+	}
+	return nil
+}
+
+// autoBanResult turns a rule that fired into a filter result, or nil if the
+// rule is off in this guild
+func (b *Bot) autoBanResult(guildID, ruleName, reason string) *FilterResult {
+	rule, ok := b.GetAutoBanSettings(guildID).Rules[ruleName]
+	if !ok || !rule.Enabled {
+		return nil
+	}
+	return &FilterResult{
+		ShouldTakeAction: true,
+		Action:           FilterAction(rule.Step.Action),
+		Reason:           reason,
+		Duration:         rule.Step.Duration,
+		AutoBanRule:      ruleName,
+	}
+}
+
+// observing reports whether a result should only be logged. Observe mode
+// covers the auto-ban rules and anything else that would ban.
+func (b *Bot) observing(guildID string, result *FilterResult) bool {
+	if result.AutoBanRule == "" && result.Action != ActionBan {
+		return false
+	}
+	return b.GetAutoBanSettings(guildID).Observe
+}
+
+// reportObserved posts what a rule would have done to the guild's log channel
+func (b *Bot) reportObserved(m *discordgo.MessageCreate, result *FilterResult) {
+	rule := result.AutoBanRule
+	if rule == "" {
+		rule = "filter"
+		if result.Detector != "" {
+			rule = "spam detector: " + result.Detector
+		}
+	}
+
+	action := string(result.Action)
+	if result.Action == ActionTimeout {
+		action = fmt.Sprintf("timeout (%s)", result.Duration)
+	}
+	log.Printf("👁️  [Observe] Would have applied %s to %s: %s", action, m.Author.ID, result.Reason)
+
+	config, err := b.GetLoggingConfigCached(m.GuildID)
+	if err != nil || !config.Enabled || config.ChannelFor(commands.LogModActions) == "" {
+		return
+	}
+
+	fields := []*discordgo.MessageEmbedField{
+		{Name: "Rule", Value: rule, Inline: true},
+		{Name: "Would Have", Value: action, Inline: true},
+		{Name: "Reason", Value: result.Reason, Inline: false},
+		{Name: "Channel", Value: "<#" + m.ChannelID + ">", Inline: true},
+	}
+	if m.Content != "" {
+		content := m.Content
+		if len(content) > 1000 {
+			content = content[:1000] + "..."
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Message", Value: content, Inline: false})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "👁️ Observe Mode",
+		Description: fmt.Sprintf("<@%s> triggered an auto-ban rule. Nothing was done.", m.Author.ID),
+		Color:       0xFFAA00,
+		Fields:      fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "User ID: " + m.Author.ID,
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	b.LogDelivery.Send(m.GuildID, config.ChannelFor(commands.LogModActions), embed)
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -16,15 +17,35 @@ type Bot struct {
 	DB                  *Database
 	Commands            *commands.Manager
 	CleanWorker         *AutoCleanWorker
+	ScheduleWorker      *ScheduleWorker
 	PresenceBatcher     *PresenceBatcher
 	SpamFilter          *SpamFilter
+	RaidMonitor         *RaidMonitor
 	PermChecker         *PermissionChecker
 	VoiceXPTracker      *VoiceXPTracker
 	ConfigCache         *ConfigCache
 	MessageCacheBatcher *MessageCacheBatcher
 	EventLogBatcher     *EventLogBatcher
+	AuditSnapshots      *AuditSnapshots
+	LogDelivery         *LogDelivery
 	XPBatcher           *XPBatcher
 	VoiceXPConfigCache  *VoiceXPConfigCache
+	PrefixCache         *ttlCache[*commands.GuildPrefixes]
+	LevelCurveCache     *ttlCache[cachedLevelCurve]
+	XPSettingsCache     *ttlCache[commands.XPSettings]
+	XPMultiplierCache   *ttlCache[[]commands.XPMultiplier]
+	AntiSpamCache       *ttlCache[*commands.AntiSpamSettings]
+	AutoBanCache        *ttlCache[*commands.AutoBanSettings]
+	SpamConfigCache     *ttlCache[*commands.SpamFilterSettings]
+	ProtectionCache     *ttlCache[[]commands.ProtectedTarget]
+	LinkFilterCache     *ttlCache[*cachedLinkFilter]
+	InviteResolver      *InviteResolver
+	ChannelPolicyCache  *ttlCache[[]commands.ChannelPolicy]
+	RaidSettingsCache   *ttlCache[commands.RaidSettings]
+	RaidStatusCache     *ttlCache[commands.RaidStatus]
+	FilterEngine        *RegexFilterEngine
+
+	slashOnce sync.Once
 }
 
 // RecoverFromPanic recovers from panics and logs stack trace if enabled
@@ -72,7 +93,7 @@ func New() (*Bot, error) {
 		Session:  dg,
 		DB:       db,
 		Commands: commands.NewManager(
-			Global.Bot.Prefix,
+			defaultPrefix(),
 			Global.Bot.OwnerIDs,
 			Global.AGPL.SourceURL,
 			Global.Paths.BanImagesFolder,
@@ -82,6 +103,9 @@ func New() (*Bot, error) {
 	// Initialize auto-clean worker
 	b.CleanWorker = NewAutoCleanWorker(b)
 
+	// Initialize schedule worker (tempban/mute expirations)
+	b.ScheduleWorker = NewScheduleWorker(b)
+
 	// Initialize presence batcher
 	b.PresenceBatcher = NewPresenceBatcher(b)
 
@@ -97,6 +121,14 @@ func New() (*Bot, error) {
 	b.EventLogBatcher = NewEventLogBatcher(b)
 	DebugLog("Event log batcher initialized")
 
+	// Initialize log delivery (queues log embeds, posts through webhooks)
+	b.LogDelivery = NewLogDelivery(b)
+	DebugLog("Log delivery initialized")
+
+	// Initialize audit snapshots (roles/emojis/settings to diff updates against)
+	b.AuditSnapshots = NewAuditSnapshots()
+	DebugLog("Audit snapshots initialized")
+
 	// Initialize XP batcher (batches XP updates)
 	b.XPBatcher = NewXPBatcher(b)
 	DebugLog("XP batcher initialized")
@@ -105,12 +137,68 @@ func New() (*Bot, error) {
 	b.VoiceXPConfigCache = NewVoiceXPConfigCache(30 * time.Second)
 	DebugLog("Voice XP config cache initialized")
 
+	// Initialize prefix cache (60 second TTL)
+	b.PrefixCache = newTTLCache[*commands.GuildPrefixes](60 * time.Second)
+	b.Commands.SetPrefixResolver(b.GetGuildPrefixesCached)
+	DebugLog("Prefix cache initialized")
+
+	// Initialize level curve cache (60 second TTL)
+	b.LevelCurveCache = newTTLCache[cachedLevelCurve](60 * time.Second)
+	DebugLog("Level curve cache initialized")
+
+	// Initialize XP settings cache (60 second TTL)
+	b.XPSettingsCache = newTTLCache[commands.XPSettings](60 * time.Second)
+	DebugLog("XP settings cache initialized")
+
+	// Initialize XP multiplier cache (60 second TTL)
+	b.XPMultiplierCache = newTTLCache[[]commands.XPMultiplier](60 * time.Second)
+	DebugLog("XP multiplier cache initialized")
+
+	// Initialize anti-spam settings cache (60 second TTL)
+	b.AntiSpamCache = newTTLCache[*commands.AntiSpamSettings](60 * time.Second)
+	DebugLog("Anti-spam settings cache initialized")
+
+	// Initialize spam filter settings cache (30 second TTL, like ConfigCache)
+	b.SpamConfigCache = newTTLCache[*commands.SpamFilterSettings](30 * time.Second)
+	DebugLog("Spam filter settings cache initialized")
+
+	// Initialize protected roles/users cache (60 second TTL)
+	b.ProtectionCache = newTTLCache[[]commands.ProtectedTarget](60 * time.Second)
+	DebugLog("Protection cache initialized")
+
+	// Initialize auto-ban rule cache (60 second TTL)
+	b.AutoBanCache = newTTLCache[*commands.AutoBanSettings](60 * time.Second)
+	DebugLog("Auto-ban rule cache initialized")
+
+	// Initialize link filter cache (60 second TTL) and invite resolver
+	b.LinkFilterCache = newTTLCache[*cachedLinkFilter](60 * time.Second)
+	b.InviteResolver = NewInviteResolver(b)
+	DebugLog("Link filter initialized")
+
+	// Initialize channel policy cache (60 second TTL)
+	b.ChannelPolicyCache = newTTLCache[[]commands.ChannelPolicy](60 * time.Second)
+	DebugLog("Channel policy cache initialized")
+
+	// Initialize anti-raid settings and lockdown status caches (60 second TTL),
+	// read on every join
+	b.RaidSettingsCache = newTTLCache[commands.RaidSettings](60 * time.Second)
+	b.RaidStatusCache = newTTLCache[commands.RaidStatus](60 * time.Second)
+	DebugLog("Anti-raid caches initialized")
+
+	// Initialize regex filter engine (compiled filters kept 5 minutes)
+	b.FilterEngine = NewRegexFilterEngine(b, 5*time.Minute)
+	DebugLog("Regex filter engine initialized")
+
 	// Initialize spam filter
 	b.SpamFilter = NewSpamFilter(b)
 	DebugLog("Spam filter initialized")
 
+	// Initialize raid monitor
+	b.RaidMonitor = NewRaidMonitor(b)
+	DebugLog("Raid monitor initialized")
+
 	// Initialize permission checker
-	b.PermChecker = NewPermissionChecker(dg)
+	b.PermChecker = NewPermissionChecker(b)
 	DebugLog("Permission checker initialized")
 
 	// Initialize voice XP tracker
@@ -126,14 +214,35 @@ func New() (*Bot, error) {
 	dg.AddHandler(b.onMessageCreate)
 	dg.AddHandler(b.onVoiceStateUpdate)
 	dg.AddHandler(b.onMemberJoin)
+	dg.AddHandler(b.onMemberLeave)
+	dg.AddHandler(b.onInteractionCreate)
 
 	// Logging handlers
 	dg.AddHandler(b.onMessageDelete)
+	dg.AddHandler(b.onMessageDeleteBulk)
 	dg.AddHandler(b.onMessageUpdate)
 	dg.AddHandler(b.onVoiceStateUpdateLogging)
 	dg.AddHandler(b.onGuildMemberUpdate)
 	dg.AddHandler(b.onPresenceUpdate)
 
+	// Audit logging handlers
+	dg.AddHandler(b.onGuildCreateAudit)
+	dg.AddHandler(b.onGuildDeleteAudit)
+	dg.AddHandler(b.onMemberJoinLogging)
+	dg.AddHandler(b.onMemberLeaveLogging)
+	dg.AddHandler(b.onGuildBanAddLogging)
+	dg.AddHandler(b.onGuildBanRemoveLogging)
+	dg.AddHandler(b.onGuildRoleCreateLogging)
+	dg.AddHandler(b.onGuildRoleUpdateLogging)
+	dg.AddHandler(b.onGuildRoleDeleteLogging)
+	dg.AddHandler(b.onChannelCreateLogging)
+	dg.AddHandler(b.onChannelUpdateLogging)
+	dg.AddHandler(b.onChannelDeleteLogging)
+	dg.AddHandler(b.onInviteCreateLogging)
+	dg.AddHandler(b.onInviteDeleteLogging)
+	dg.AddHandler(b.onGuildEmojisUpdateLogging)
+	dg.AddHandler(b.onGuildUpdateLogging)
+
 	// All intents we need
 	dg.Identify.Intents = discordgo.IntentsAllWithoutPrivileged |
 		discordgo.IntentsGuildMembers |
@@ -152,26 +261,77 @@ func (b *Bot) registerCommands() {
 	b.Commands.Register(&commands.DelayCommand{})
 	
 	// Leveling commands
-	b.Commands.Register(&commands.XPCommand{})
-	b.Commands.Register(&commands.SetLevelCommand{})
-	b.Commands.Register(&commands.SyncLevelsCommand{})
+	b.Commands.Register(&commands.XPCommand{DB: b})
+	b.Commands.Register(&commands.LeaderboardCommand{Leaderboard: b})
+	b.Commands.Register(&commands.SetLevelCommand{DB: b})
+	b.Commands.Register(&commands.SyncLevelsCommand{DB: b})
 	b.Commands.Register(&commands.PreviewLevelsCommand{})
+	b.Commands.Register(&commands.LevelConfigCommand{Levels: b})
+	b.Commands.Register(&commands.RecalcLevelsCommand{Levels: b})
+	b.Commands.Register(&commands.XPSettingsCommand{Settings: b})
+	b.Commands.Register(&commands.XPMultiplierCommand{Multipliers: b})
+
+	// Mass XP tools
+	b.Commands.Register(&commands.MassAddXPCommand{DB: b})
+	b.Commands.Register(&commands.MassSetXPCommand{DB: b})
+	b.Commands.Register(&commands.MassLevelUpCommand{DB: b})
+	b.Commands.Register(&commands.SyncXPFromRolesCommand{DB: b})
 	
 	// Rank role management
 	b.Commands.Register(&commands.SyncRanksCommand{})
 	b.Commands.Register(&commands.AddRankCommand{})
 	b.Commands.Register(&commands.RemoveRankCommand{})
 	b.Commands.Register(&commands.ListRanksCommand{})
-	b.Commands.Register(&commands.ApplyRanksCommand{})
+	b.Commands.Register(&commands.ApplyRanksCommand{Ranks: b})
+	b.Commands.Register(&commands.RankModeCommand{Ranks: b})
 	
+	// Configuration commands
+	b.Commands.Register(&commands.SetPrefixCommand{Prefixes: b})
+	b.Commands.Register(&commands.WelcomeCommand{Welcome: b})
+	b.Commands.Register(&commands.GoodbyeCommand{Welcome: b})
+
 	// Moderation commands
-	b.Commands.Register(&commands.BanCommand{})
-	b.Commands.Register(&commands.KickCommand{})
+	b.Commands.Register(&commands.BanCommand{Cases: b, Schedule: b, Protection: b})
+	b.Commands.Register(&commands.KickCommand{Cases: b, Protection: b})
+	b.Commands.Register(&commands.ProtectCommand{Protection: b})
+	b.Commands.Register(&commands.WarnCommand{Cases: b, Points: b, Hierarchy: b})
+	b.Commands.Register(&commands.WarnConfigCommand{Points: b})
+	b.Commands.Register(&commands.PointsCommand{Points: b})
+	b.Commands.Register(&commands.ClearPointsCommand{Points: b})
+	b.Commands.Register(&commands.TimeoutCommand{Cases: b, Hierarchy: b})
+	b.Commands.Register(&commands.UnbanCommand{Cases: b, Schedule: b})
+	b.Commands.Register(&commands.MuteCommand{Cases: b, Schedule: b, MuteRoles: b, Hierarchy: b})
+	b.Commands.Register(&commands.UnmuteCommand{Cases: b, Schedule: b, MuteRoles: b, Hierarchy: b})
+	b.Commands.Register(&commands.SetMuteRoleCommand{MuteRoles: b})
+	b.Commands.Register(&commands.AntiRaidCommand{Raids: b})
+	b.Commands.Register(&commands.AntiSpamCommand{Settings: b})
+	b.Commands.Register(&commands.AutoBanCommand{Settings: b})
+	b.Commands.Register(&commands.SetSpamFilterCommand{Settings: b})
+	b.Commands.Register(&commands.LinkFilterCommand{Links: b})
+	b.Commands.Register(&commands.ChannelPolicyCommand{Policies: b})
+
+	// Moderation case commands
+	b.Commands.Register(&commands.NoteCommand{Cases: b})
+	b.Commands.Register(&commands.CaseCommand{Cases: b})
+	b.Commands.Register(&commands.ReasonCommand{Cases: b})
+	b.Commands.Register(&commands.DelCaseCommand{Cases: b})
+	b.Commands.Register(&commands.HistoryCommand{Cases: b})
+
+	// Ban list import/export
+	b.Commands.Register(&commands.ExportBansCommand{})
+	b.Commands.Register(&commands.ImportBansCommand{Cases: b, Protection: b})
+	b.Commands.Register(&commands.ScanBansCommand{})
 
 	// Spam filter commands
-	b.Commands.Register(&commands.AddFilterCommand{})
-	b.Commands.Register(&commands.RemoveFilterCommand{})
-	b.Commands.Register(&commands.ListFiltersCommand{})
+	b.Commands.Register(&commands.AddFilterCommand{Filters: b})
+	b.Commands.Register(&commands.RemoveFilterCommand{Filters: b})
+	b.Commands.Register(&commands.ListFiltersCommand{Filters: b})
+	b.Commands.Register(&commands.AddChannelFilterCommand{Filters: b})
+	b.Commands.Register(&commands.RemoveChannelFilterCommand{Filters: b})
+	b.Commands.Register(&commands.ListChannelFiltersCommand{Filters: b})
+	b.Commands.Register(&commands.EnableFilterCommand{Filters: b})
+	b.Commands.Register(&commands.DisableFilterCommand{Filters: b})
+	b.Commands.Register(&commands.TestFilterCommand{Tester: b})
 
 	// Owner commands
 	b.Commands.Register(&commands.ShutdownCommand{})
@@ -200,13 +360,16 @@ func (b *Bot) registerCommands() {
 	b.Commands.Register(&commands.VCXPStatusCommand{})
 
 	// Logging commands
-	b.Commands.Register(&commands.SetLogChannelCommand{})
-	b.Commands.Register(&commands.ToggleLoggingCommand{})
-	b.Commands.Register(&commands.ConfigureLogTypeCommand{})
-	b.Commands.Register(&commands.SetPresenceBatchCommand{})
-	b.Commands.Register(&commands.DisableChannelLoggingCommand{})
-	b.Commands.Register(&commands.EnableChannelLoggingCommand{})
-	b.Commands.Register(&commands.LogStatusCommand{})
+	logStore := loggingStore{b}
+	b.Commands.Register(&commands.SetLogChannelCommand{Store: logStore})
+	b.Commands.Register(&commands.ToggleLoggingCommand{Store: logStore})
+	b.Commands.Register(&commands.LogWebhooksCommand{Store: logStore})
+	b.Commands.Register(&commands.LogAttachmentsCommand{Store: logStore})
+	b.Commands.Register(&commands.ConfigureLogTypeCommand{Store: logStore})
+	b.Commands.Register(&commands.SetPresenceBatchCommand{Store: logStore})
+	b.Commands.Register(&commands.DisableChannelLoggingCommand{Store: logStore})
+	b.Commands.Register(&commands.EnableChannelLoggingCommand{Store: logStore})
+	b.Commands.Register(&commands.LogStatusCommand{Store: logStore})
 
 	log.Printf("Registered %d commands", len(b.Commands.GetAll()))
 }
@@ -215,6 +378,9 @@ func (b *Bot) Start() error {
 	// Start auto-clean worker
 	b.CleanWorker.Start()
 
+	// Start schedule worker
+	b.ScheduleWorker.Start()
+
 	// Start presence batcher
 	b.PresenceBatcher.Start()
 
@@ -224,22 +390,31 @@ func (b *Bot) Start() error {
 	// Start event log batcher
 	b.EventLogBatcher.Start()
 
+	// Start log delivery queue
+	b.LogDelivery.Start()
+
 	// Start XP batcher
 	b.XPBatcher.Start()
 
 	// Start voice XP tracker
 	b.VoiceXPTracker.Start()
 
+	// Start regex filter hit counter writer
+	b.FilterEngine.Start()
+
 	return b.Session.Open()
 }
 
 func (b *Bot) Stop() {
 	b.CleanWorker.Stop()
+	b.ScheduleWorker.Stop()
 	b.PresenceBatcher.Stop()
 	b.MessageCacheBatcher.Stop()
 	b.EventLogBatcher.Stop()
+	b.LogDelivery.Stop()
 	b.XPBatcher.Stop()
 	b.VoiceXPTracker.Stop()
+	b.FilterEngine.Stop()
 	b.Session.Close()
 	b.DB.Close()
 }
@@ -294,6 +469,14 @@ func (b *Bot) onReady(s *discordgo.Session, r *discordgo.Ready) {
 		log.Printf("Warning: Could not set status: %v", err)
 	}
 
+	// Register slash commands once per process (Ready fires again on reconnect)
+	b.slashOnce.Do(func() {
+		go b.registerSlashCommands(s)
+	})
+
+	// Catch up on tempbans/mutes that expired while offline
+	go b.ScheduleWorker.RunDue()
+
 	// Start daily cleanup task for message cache
 	go b.dailyCleanupTask()
 }
@@ -335,26 +518,25 @@ func (b *Bot) onMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate)
 			m.Author.Username, m.GuildID, m.Content)
 	}
 
+	// Check if message starts with one of the guild's prefixes
+	content, isCommand := b.matchCommandPrefix(m.GuildID, m.Content, s.State.User.ID)
+
 	// Check if the bot is mentioned - trigger delay command
-	for _, mention := range m.Mentions {
-		if mention.ID == s.State.User.ID {
-			// Bot was mentioned, run delay command
-			ctx := &commands.Context{
-				Session: s,
-				Message: m,
-				Bot:     b,
+	if !isCommand {
+		for _, mention := range m.Mentions {
+			if mention.ID == s.State.User.ID {
+				// Bot was mentioned, run delay command
+				ctx := &commands.Context{
+					Session: s,
+					Message: m,
+					Bot:     b,
+				}
+				b.Commands.Execute(ctx, "delay")
+				return
 			}
-			b.Commands.Execute(ctx, "delay")
-			return
 		}
 	}
 
-	// Check if message starts with prefix
-	prefix := Global.Bot.Prefix
-	if prefix == "" {
-		prefix = "?"
-	}
-
 	// Check spam filter first (before commands)
 	if filterResult := b.SpamFilter.CheckMessage(m); filterResult != nil {
 		DebugLog("Message triggered spam filter: %s", filterResult.Reason)
@@ -362,9 +544,8 @@ func (b *Bot) onMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate)
 		return
 	}
 
-	if strings.HasPrefix(m.Content, prefix) {
+	if isCommand {
 		// Handle command
-		content := strings.TrimPrefix(m.Content, prefix)
 		DebugLog("Command received: %s from %s", content, m.Author.Username)
 
 		// Check for moderation command authorization
@@ -379,15 +560,15 @@ func (b *Bot) onMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate)
 				}
 
 				// Check authorization
-				shouldBan, reason := b.SpamFilter.CheckCommandAuthorization(
+				result := b.SpamFilter.CheckCommandAuthorization(
 					m.GuildID,
 					m.Author.ID,
 					targetID,
 					cmdName,
 				)
-				if shouldBan {
-					DebugLog("Auto-banning %s for unauthorized command: %s", m.Author.ID, reason)
-					b.PermChecker.AutoBanViolator(m.GuildID, m.Author.ID, reason)
+				if result != nil {
+					DebugLog("Punishing %s for unauthorized command: %s", m.Author.ID, result.Reason)
+					b.SpamFilter.ExecuteAction(s, m, result)
 					return
 				}
 			}
@@ -416,12 +597,22 @@ func (b *Bot) onMessageCreate(s *discordgo.Session, m *discordgo.MessageCreate)
 }
 
 func (b *Bot) giveXPAsync(s *discordgo.Session, m *discordgo.MessageCreate) {
-	// Pseudo-random 15-25 XP based on message ID
-	xp := 15 + (int(m.ID[0]) % 11)
+	settings := b.GetXPSettings(m.GuildID)
+	xp := rollMessageXP(settings)
+
+	var roles []string
+	if m.Member != nil {
+		roles = m.Member.Roles
+	}
+	xp = applyXPMultiplier(xp, b.xpMultiplier(m.GuildID, m.ChannelID, roles))
+	if xp <= 0 {
+		return
+	}
 
-	// Use XP batcher for efficient batched updates
+	// Use XP batcher for efficient batched updates (it enforces the cooldown)
 	if b.XPBatcher != nil {
-		b.XPBatcher.AddXP(m.GuildID, m.Author.ID, m.ChannelID, xp)
+		cooldown := time.Duration(settings.CooldownSeconds) * time.Second
+		b.XPBatcher.AddMessageXP(m.GuildID, m.Author.ID, m.ChannelID, xp, cooldown)
 		return
 	}
 
@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"log"
+	"time"
+
+	"yuno-go/internal/commands"
+)
+
+// RecordModCase stores a moderation case and posts it to the guild's log channel
+func (b *Bot) RecordModCase(modCase *commands.ModCase) (*commands.ModCase, error) {
+	if modCase.CreatedAt.IsZero() {
+		modCase.CreatedAt = time.Now()
+	}
+
+	if err := b.DB.CreateModCase(modCase); err != nil {
+		log.Printf("Failed to record %s case for %s: %v", modCase.Action, modCase.UserID, err)
+		return nil, err
+	}
+
+	DebugLog("Recorded case #%d (%s) for %s in guild %s", modCase.CaseNumber, modCase.Action, modCase.UserID, modCase.GuildID)
+
+	// Warnings, manual or automatic, count towards punishment thresholds
+	if modCase.Action == commands.CaseWarn {
+		defer b.addWarningPoints(modCase)
+	}
+
+	config, err := b.GetLoggingConfigCached(modCase.GuildID)
+	if err == nil && config.Enabled {
+		b.LogDelivery.Send(modCase.GuildID, config.ChannelFor(commands.LogModActions), commands.ModCaseEmbed(modCase))
+	}
+
+	return modCase, nil
+}
+
+// GetModCase loads a single case
+func (b *Bot) GetModCase(guildID string, caseNumber int) (*commands.ModCase, error) {
+	return b.DB.GetModCase(guildID, caseNumber)
+}
+
+// UpdateModCaseReason changes the reason of a case
+func (b *Bot) UpdateModCaseReason(guildID string, caseNumber int, reason string) error {
+	return b.DB.UpdateModCaseReason(guildID, caseNumber, reason)
+}
+
+// DeleteModCase removes a case from a guild's history, along with any
+// warning points it gave
+func (b *Bot) DeleteModCase(guildID string, caseNumber int) error {
+	if err := b.DB.DeleteModCase(guildID, caseNumber); err != nil {
+		return err
+	}
+	return b.DB.DeleteWarningPointsForCase(guildID, caseNumber)
+}
+
+// GetUserModCases lists a user's cases, newest first
+func (b *Bot) GetUserModCases(guildID, userID string) ([]*commands.ModCase, error) {
+	return b.DB.GetUserModCases(guildID, userID)
+}
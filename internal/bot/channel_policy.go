@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"log"
+	"path"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
+)
+
+// mediaExtensions are treated as media when Discord doesn't send a content type
+var mediaExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true,
+	".mp4": true, ".mov": true, ".webm": true, ".mp3": true, ".ogg": true, ".wav": true,
+}
+
+// ============================================================================
+// CHANNEL POLICIES - Checked on every message
+// ============================================================================
+
+// GetChannelPolicies returns a guild's stored channel policies
+func (b *Bot) GetChannelPolicies(guildID string) []commands.ChannelPolicy {
+	if b.ChannelPolicyCache != nil {
+		if policies, ok := b.ChannelPolicyCache.Get(guildID); ok {
+			return append([]commands.ChannelPolicy(nil), policies...)
+		}
+	}
+
+	policies, err := b.DB.GetChannelPolicies(guildID)
+	if err != nil {
+		log.Printf("Error loading channel policies for guild %s: %v", guildID, err)
+		return nil
+	}
+
+	if b.ChannelPolicyCache != nil {
+		b.ChannelPolicyCache.Set(guildID, policies)
+	}
+	return append([]commands.ChannelPolicy(nil), policies...)
+}
+
+// SetChannelPolicy adds or replaces a channel or prefix policy
+func (b *Bot) SetChannelPolicy(policy commands.ChannelPolicy) error {
+	if err := b.DB.SetChannelPolicy(policy); err != nil {
+		return err
+	}
+	if b.ChannelPolicyCache != nil {
+		b.ChannelPolicyCache.Invalidate(policy.GuildID)
+	}
+	return nil
+}
+
+// RemoveChannelPolicy drops a channel or prefix policy
+func (b *Bot) RemoveChannelPolicy(guildID, targetType, target string) (bool, error) {
+	removed, err := b.DB.RemoveChannelPolicy(guildID, targetType, target)
+	if err != nil {
+		return false, err
+	}
+	if b.ChannelPolicyCache != nil {
+		b.ChannelPolicyCache.Invalidate(guildID)
+	}
+	return removed, nil
+}
+
+// ============================================================================
+// POLICY CHECKS
+// ============================================================================
+
+// checkChannelPolicy enforces media-only, links-only, text-only and
+// no-attachments channels
+func (sf *SpamFilter) checkChannelPolicy(m *discordgo.MessageCreate) *FilterResult {
+	result := sf.channelPolicyResult(m)
+	if result == nil {
+		return nil
+	}
+
+	if sf.permChecker.IsBotOwner(m.Author.ID) ||
+		sf.permChecker.HasPermission(m.GuildID, m.Author.ID, discordgo.PermissionManageMessages) {
+		return nil
+	}
+
+	DebugLog("Message from %s breaks channel policy in %s", m.Author.ID, m.ChannelID)
+	return result
+}
+
+// channelPolicyResult checks a message against its channel's policy, ignoring exemptions
+func (sf *SpamFilter) channelPolicyResult(m *discordgo.MessageCreate) *FilterResult {
+	channelID, channelName := m.ChannelID, ""
+	if channel, err := sf.bot.Session.State.Channel(m.ChannelID); err == nil {
+		channelName = channel.Name
+		// Threads follow their parent channel
+		if channel.IsThread() {
+			channelID = channel.ParentID
+			if parent, err := sf.bot.Session.State.Channel(channel.ParentID); err == nil {
+				channelName = parent.Name
+			}
+		}
+	}
+
+	policies := sf.bot.GetChannelPolicies(m.GuildID)
+	if prefix := sf.bot.GetSpamFilterSettings(m.GuildID).NSFWChannelPrefix; prefix != "" {
+		// Going last, the spam filter default loses to a stored rule for the same prefix
+		policies = append(policies, commands.ChannelPolicy{
+			TargetType: commands.PolicyTargetPrefix,
+			Target:     strings.ToLower(prefix),
+			Policy:     commands.PolicyMediaOnly,
+		})
+	}
+
+	policy := commands.ResolveChannelPolicy(policies, channelID, strings.ToLower(channelName))
+	if policy == commands.PolicyNone || messageFitsPolicy(m.Message, policy) {
+		return nil
+	}
+
+	return &FilterResult{
+		ShouldTakeAction: true,
+		Action:           ActionNotice,
+		Reason:           commands.PolicyNotice(policy),
+	}
+}
+
+// messageFitsPolicy reports whether a message is allowed under a policy
+func messageFitsPolicy(msg *discordgo.Message, policy string) bool {
+	hasLink := linkRegex.MatchString(msg.Content)
+	hasFiles := len(msg.Attachments) > 0
+
+	switch policy {
+	case commands.PolicyMediaOnly:
+		if hasLink || len(msg.StickerItems) > 0 {
+			return true
+		}
+		for _, attachment := range msg.Attachments {
+			if isMediaAttachment(attachment) {
+				return true
+			}
+		}
+		return false
+	case commands.PolicyLinksOnly:
+		return hasLink
+	case commands.PolicyTextOnly:
+		return !hasLink && !hasFiles && len(msg.StickerItems) == 0
+	case commands.PolicyNoAttachments:
+		return !hasFiles
+	}
+	return true
+}
+
+// isMediaAttachment reports whether an upload is an image, video or audio file
+func isMediaAttachment(attachment *discordgo.MessageAttachment) bool {
+	contentType := attachment.ContentType
+	if contentType != "" {
+		return strings.HasPrefix(contentType, "image/") ||
+			strings.HasPrefix(contentType, "video/") ||
+			strings.HasPrefix(contentType, "audio/")
+	}
+	return mediaExtensions[strings.ToLower(path.Ext(attachment.Filename))]
+}
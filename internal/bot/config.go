@@ -48,6 +48,8 @@ type LevelingConfig struct {
 	XpPerMinuteVoice  []int  `toml:"xp_per_minute_voice"`
 	LevelUpChannel    string `toml:"level_up_channel"`
 	CooldownSeconds   int    `toml:"cooldown_seconds"`
+	LevelCurve        string `toml:"level_curve"`
+	RankMode          string `toml:"rank_mode"`
 }
 
 type WelcomeConfig struct {
@@ -81,6 +83,7 @@ type PathsConfig struct {
 	BanImagesFolder     string `toml:"ban_images_folder"`
 	MentionImagesFolder string `toml:"mention_images_folder"`
 	LogsFolder          string `toml:"logs_folder"`
+	AttachmentArchiveMB int    `toml:"attachment_archive_mb"`
 }
 
 type PerformanceConfig struct {
@@ -4,6 +4,11 @@ import (
 	"database/sql"
 	"log"
 	"os"
+	"strings"
+	"time"
+
+	"yuno-go/internal/commands"
+	"yuno-go/internal/leveling"
 )
 
 type Database struct {
@@ -80,8 +85,20 @@ func NewDatabase(path string) (*Database, error) {
 			nickname_change INTEGER DEFAULT 1,
 			avatar_change INTEGER DEFAULT 1,
 			presence_change INTEGER DEFAULT 1,
+			member_join INTEGER DEFAULT 1,
+			member_leave INTEGER DEFAULT 1,
+			member_ban INTEGER DEFAULT 1,
+			member_unban INTEGER DEFAULT 1,
+			member_role_change INTEGER DEFAULT 1,
+			role_change INTEGER DEFAULT 1,
+			channel_change INTEGER DEFAULT 1,
+			invite_change INTEGER DEFAULT 1,
+			emoji_change INTEGER DEFAULT 1,
+			guild_update INTEGER DEFAULT 1,
 			presence_batch_seconds INTEGER DEFAULT 120,
-			enabled INTEGER DEFAULT 1
+			enabled INTEGER DEFAULT 1,
+			use_webhooks INTEGER DEFAULT 0,
+			archive_attachments INTEGER DEFAULT 0
 		)`,
 		`CREATE TABLE IF NOT EXISTS logging_channel_overrides (
 			guild_id TEXT,
@@ -93,14 +110,42 @@ func NewDatabase(path string) (*Database, error) {
 			nickname_change INTEGER DEFAULT 1,
 			avatar_change INTEGER DEFAULT 1,
 			presence_change INTEGER DEFAULT 1,
+			member_join INTEGER DEFAULT 1,
+			member_leave INTEGER DEFAULT 1,
+			member_ban INTEGER DEFAULT 1,
+			member_unban INTEGER DEFAULT 1,
+			member_role_change INTEGER DEFAULT 1,
+			role_change INTEGER DEFAULT 1,
+			channel_change INTEGER DEFAULT 1,
+			invite_change INTEGER DEFAULT 1,
+			emoji_change INTEGER DEFAULT 1,
+			guild_update INTEGER DEFAULT 1,
 			PRIMARY KEY (guild_id, channel_id)
 		)`,
+		`CREATE TABLE IF NOT EXISTS logging_type_channels (
+			guild_id TEXT,
+			log_type TEXT,
+			channel_id TEXT,
+			PRIMARY KEY (guild_id, log_type)
+		)`,
+		`CREATE TABLE IF NOT EXISTS log_webhooks (
+			channel_id TEXT PRIMARY KEY,
+			guild_id TEXT,
+			webhook_id TEXT,
+			webhook_token TEXT,
+			created_at TEXT
+		)`,
 		`CREATE TABLE IF NOT EXISTS message_cache (
 			message_id TEXT PRIMARY KEY,
 			guild_id TEXT,
 			channel_id TEXT,
 			author_id TEXT,
+			author_tag TEXT DEFAULT '',
 			content TEXT,
+			attachments TEXT DEFAULT '',
+			embeds TEXT DEFAULT '',
+			stickers TEXT DEFAULT '',
+			reference TEXT DEFAULT '',
 			created_at TEXT
 		)`,
 		`CREATE TABLE IF NOT EXISTS regex_filters (
@@ -181,6 +226,193 @@ func NewDatabase(path string) (*Database, error) {
 			guild_id TEXT PRIMARY KEY,
 			prefix TEXT NOT NULL
 		)`,
+		// Moderation cases (numbered per guild, soft-deleted so numbers are never reused)
+		`CREATE TABLE IF NOT EXISTS mod_cases (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL,
+			case_number INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			moderator_id TEXT,
+			reason TEXT,
+			duration_seconds INTEGER DEFAULT 0,
+			created_at TEXT,
+			deleted INTEGER DEFAULT 0,
+			UNIQUE(guild_id, case_number)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_mod_cases_user ON mod_cases (guild_id, user_id)`,
+		// Pending tempban/mute expirations
+		`CREATE TABLE IF NOT EXISTS scheduled_actions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			role_id TEXT,
+			execute_at TEXT NOT NULL,
+			case_number INTEGER DEFAULT 0,
+			attempts INTEGER DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_scheduled_actions_due ON scheduled_actions (execute_at)`,
+		// Per-guild moderation settings
+		`CREATE TABLE IF NOT EXISTS moderation_config (
+			guild_id TEXT PRIMARY KEY,
+			mute_role_id TEXT
+		)`,
+		`CREATE TABLE IF NOT EXISTS guild_extra_prefixes (
+			guild_id TEXT,
+			prefix TEXT,
+			PRIMARY KEY (guild_id, prefix)
+		)`,
+		// Per-guild XP → level curve (see internal/leveling)
+		`CREATE TABLE IF NOT EXISTS leveling_config (
+			guild_id TEXT PRIMARY KEY,
+			curve TEXT NOT NULL DEFAULT 'triangular',
+			param_a REAL DEFAULT 0,
+			param_b REAL DEFAULT 0,
+			param_c REAL DEFAULT 0
+		)`,
+		// Per-guild overrides of the [leveling] config
+		`CREATE TABLE IF NOT EXISTS xp_settings (
+			guild_id TEXT PRIMARY KEY,
+			xp_min INTEGER NOT NULL,
+			xp_max INTEGER NOT NULL,
+			cooldown_seconds INTEGER NOT NULL,
+			levelup_mode TEXT NOT NULL,
+			levelup_channel_id TEXT
+		)`,
+		// Rank reward mode: stack every earned role or keep only the highest
+		`CREATE TABLE IF NOT EXISTS rank_config (
+			guild_id TEXT PRIMARY KEY,
+			mode TEXT NOT NULL
+		)`,
+		// Anti-raid detection settings
+		`CREATE TABLE IF NOT EXISTS anti_raid_config (
+			guild_id TEXT PRIMARY KEY,
+			enabled INTEGER DEFAULT 0,
+			join_threshold INTEGER NOT NULL,
+			window_seconds INTEGER NOT NULL,
+			account_age_days INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			lockdown INTEGER DEFAULT 1,
+			raise_verification INTEGER DEFAULT 1,
+			duration_minutes INTEGER NOT NULL
+		)`,
+		// Active raid lockdowns and what they changed, so they can be undone after a restart
+		`CREATE TABLE IF NOT EXISTS raid_lockdowns (
+			guild_id TEXT PRIMARY KEY,
+			reason TEXT,
+			started_at TEXT NOT NULL,
+			ends_at TEXT NOT NULL,
+			previous_verification INTEGER DEFAULT -1
+		)`,
+		`CREATE TABLE IF NOT EXISTS raid_lockdown_channels (
+			guild_id TEXT,
+			channel_id TEXT,
+			had_overwrite INTEGER DEFAULT 0,
+			allow_bits INTEGER DEFAULT 0,
+			deny_bits INTEGER DEFAULT 0,
+			PRIMARY KEY (guild_id, channel_id)
+		)`,
+		// Spam detector overrides; detectors without a row use the defaults
+		`CREATE TABLE IF NOT EXISTS anti_spam_detectors (
+			guild_id TEXT,
+			detector TEXT,
+			enabled INTEGER DEFAULT 1,
+			threshold INTEGER NOT NULL,
+			window_seconds INTEGER DEFAULT 0,
+			PRIMARY KEY (guild_id, detector)
+		)`,
+		`CREATE TABLE IF NOT EXISTS anti_spam_config (
+			guild_id TEXT PRIMARY KEY,
+			escalation TEXT NOT NULL,
+			strike_reset_minutes INTEGER NOT NULL
+		)`,
+		// Per-guild copy of [spam_filter]; exempt_roles is space-separated
+		`CREATE TABLE IF NOT EXISTS spam_filter_settings (
+			guild_id TEXT PRIMARY KEY,
+			enabled INTEGER DEFAULT 1,
+			main_channel_prefix TEXT,
+			nsfw_channel_prefix TEXT,
+			allow_invites INTEGER DEFAULT 0,
+			max_consecutive_messages INTEGER,
+			warning_lifetime INTEGER,
+			ban_unauthorized_commands INTEGER DEFAULT 1,
+			ban_hierarchy_violation INTEGER DEFAULT 1,
+			allow_same_role_moderation INTEGER DEFAULT 0,
+			ban_everyone_mention INTEGER DEFAULT 1,
+			ban_here_mention INTEGER DEFAULT 1,
+			exempt_roles TEXT
+		)`,
+		// Roles and users the bot won't ban or kick
+		`CREATE TABLE IF NOT EXISTS protected_targets (
+			guild_id TEXT NOT NULL,
+			target_type TEXT NOT NULL,
+			target_id TEXT NOT NULL,
+			added_by TEXT,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (guild_id, target_type, target_id)
+		)`,
+		// Auto-ban observe mode and per-rule overrides of config.toml
+		`CREATE TABLE IF NOT EXISTS auto_ban_config (
+			guild_id TEXT PRIMARY KEY,
+			observe INTEGER DEFAULT 0
+		)`,
+		`CREATE TABLE IF NOT EXISTS auto_ban_rules (
+			guild_id TEXT NOT NULL,
+			rule TEXT NOT NULL,
+			enabled INTEGER DEFAULT 1,
+			action TEXT NOT NULL,
+			PRIMARY KEY (guild_id, rule)
+		)`,
+		// Invite and link filter settings and domain rules
+		`CREATE TABLE IF NOT EXISTS link_filter_config (
+			guild_id TEXT PRIMARY KEY,
+			enabled INTEGER DEFAULT 1,
+			invite_action TEXT NOT NULL,
+			link_action TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS link_rules (
+			guild_id TEXT,
+			channel_id TEXT DEFAULT '',
+			domain TEXT,
+			kind TEXT NOT NULL,
+			PRIMARY KEY (guild_id, channel_id, domain)
+		)`,
+		// Channel content policies by channel ID or name prefix
+		`CREATE TABLE IF NOT EXISTS channel_policies (
+			guild_id TEXT,
+			target_type TEXT,
+			target TEXT,
+			policy TEXT NOT NULL,
+			PRIMARY KEY (guild_id, target_type, target)
+		)`,
+		// Warning points ledger; rows past expires_at no longer count
+		`CREATE TABLE IF NOT EXISTS warning_points (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			guild_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			points INTEGER NOT NULL,
+			reason TEXT,
+			case_number INTEGER DEFAULT 0,
+			created_at TEXT NOT NULL,
+			expires_at TEXT
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_warning_points_user ON warning_points (guild_id, user_id)`,
+		`CREATE TABLE IF NOT EXISTS warn_config (
+			guild_id TEXT PRIMARY KEY,
+			enabled INTEGER DEFAULT 0,
+			points_per_warn INTEGER NOT NULL,
+			decay_days INTEGER NOT NULL,
+			thresholds TEXT NOT NULL
+		)`,
+		// XP multipliers and no-XP zones (multiplier 0) by channel or role
+		`CREATE TABLE IF NOT EXISTS xp_multipliers (
+			guild_id TEXT,
+			target_type TEXT,
+			target_id TEXT,
+			multiplier REAL NOT NULL,
+			PRIMARY KEY (guild_id, target_type, target_id)
+		)`,
 	}
 
 	for _, q := range queries {
@@ -189,6 +421,65 @@ func NewDatabase(path string) (*Database, error) {
 		}
 	}
 
+	// Columns added to existing tables (duplicate column errors mean already applied)
+	migrations := []string{
+		`ALTER TABLE guild_prefixes ADD COLUMN case_insensitive INTEGER DEFAULT 0`,
+		`ALTER TABLE guild_prefixes ADD COLUMN mention_prefix INTEGER DEFAULT 1`,
+		`ALTER TABLE welcome ADD COLUMN leave_enabled INTEGER DEFAULT 0`,
+		`ALTER TABLE welcome ADD COLUMN leave_channel_id TEXT`,
+		`ALTER TABLE welcome ADD COLUMN leave_message TEXT`,
+		// XP split by source for the leaderboard; exp stays the total
+		`ALTER TABLE glevel ADD COLUMN text_exp INTEGER DEFAULT 0`,
+		`ALTER TABLE glevel ADD COLUMN voice_exp INTEGER DEFAULT 0`,
+		// XP from before the split can't be told apart, so count it as text
+		`UPDATE glevel SET text_exp = exp WHERE text_exp = 0 AND voice_exp = 0 AND exp > 0`,
+		// Leaderboard indexes (after the columns above exist)
+		`CREATE INDEX IF NOT EXISTS idx_glevel_exp ON glevel (guild_id, exp DESC, user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_glevel_text_exp ON glevel (guild_id, text_exp DESC, user_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_glevel_voice_exp ON glevel (guild_id, voice_exp DESC, user_id)`,
+		// Regex filter hit counters
+		`ALTER TABLE regex_filters ADD COLUMN hits INTEGER DEFAULT 0`,
+		`ALTER TABLE regex_filters ADD COLUMN last_hit_at TEXT`,
+		`ALTER TABLE channel_regex_filters ADD COLUMN hits INTEGER DEFAULT 0`,
+		`ALTER TABLE channel_regex_filters ADD COLUMN last_hit_at TEXT`,
+		// Audit log types
+		`ALTER TABLE logging_config ADD COLUMN member_join INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_config ADD COLUMN member_leave INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_config ADD COLUMN member_ban INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_config ADD COLUMN member_unban INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_config ADD COLUMN member_role_change INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_config ADD COLUMN role_change INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_config ADD COLUMN channel_change INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_config ADD COLUMN invite_change INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_config ADD COLUMN emoji_change INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_config ADD COLUMN guild_update INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_channel_overrides ADD COLUMN member_join INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_channel_overrides ADD COLUMN member_leave INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_channel_overrides ADD COLUMN member_ban INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_channel_overrides ADD COLUMN member_unban INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_channel_overrides ADD COLUMN member_role_change INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_channel_overrides ADD COLUMN role_change INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_channel_overrides ADD COLUMN channel_change INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_channel_overrides ADD COLUMN invite_change INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_channel_overrides ADD COLUMN emoji_change INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_channel_overrides ADD COLUMN guild_update INTEGER DEFAULT 1`,
+		`ALTER TABLE logging_config ADD COLUMN use_webhooks INTEGER DEFAULT 0`,
+		`ALTER TABLE logging_config ADD COLUMN archive_attachments INTEGER DEFAULT 0`,
+		`ALTER TABLE message_cache ADD COLUMN author_tag TEXT DEFAULT ''`,
+		`ALTER TABLE message_cache ADD COLUMN attachments TEXT DEFAULT ''`,
+		`ALTER TABLE message_cache ADD COLUMN embeds TEXT DEFAULT ''`,
+		`ALTER TABLE message_cache ADD COLUMN stickers TEXT DEFAULT ''`,
+		`ALTER TABLE message_cache ADD COLUMN reference TEXT DEFAULT ''`,
+		// Failed scheduled actions back off without losing when they were due
+		`ALTER TABLE scheduled_actions ADD COLUMN retry_at TEXT`,
+	}
+
+	for _, m := range migrations {
+		if _, err := db.Exec(m); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			log.Printf("Warning: Failed to apply migration: %v", err)
+		}
+	}
+
 	return &Database{db}, nil
 }
 
@@ -542,3 +833,1389 @@ func (d *Database) SetGuildPrefix(guildID, prefix string) error {
 	)
 	return err
 }
+
+// GetGuildPrefixes loads every prefix setting for a guild (sql.ErrNoRows if unset)
+func (d *Database) GetGuildPrefixes(guildID string) (*commands.GuildPrefixes, error) {
+	var prefix string
+	var caseInsensitive, mentionPrefix int
+	err := d.QueryRow(`
+		SELECT prefix, COALESCE(case_insensitive, 0), COALESCE(mention_prefix, 1)
+		FROM guild_prefixes WHERE guild_id = ?`, guildID).Scan(&prefix, &caseInsensitive, &mentionPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes := &commands.GuildPrefixes{
+		Prefixes:        []string{prefix},
+		CaseInsensitive: caseInsensitive == 1,
+		MentionPrefix:   mentionPrefix == 1,
+	}
+
+	rows, err := d.Query("SELECT prefix FROM guild_extra_prefixes WHERE guild_id = ? ORDER BY rowid", guildID)
+	if err != nil {
+		return prefixes, nil
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var extra string
+		if rows.Scan(&extra) == nil {
+			prefixes.Prefixes = append(prefixes.Prefixes, extra)
+		}
+	}
+
+	return prefixes, nil
+}
+
+// SaveGuildPrefixes replaces a guild's prefix settings
+func (d *Database) SaveGuildPrefixes(guildID string, prefixes *commands.GuildPrefixes) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT OR REPLACE INTO guild_prefixes (guild_id, prefix, case_insensitive, mention_prefix)
+		VALUES (?, ?, ?, ?)`,
+		guildID, prefixes.Primary(), boolToInt(prefixes.CaseInsensitive), boolToInt(prefixes.MentionPrefix))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM guild_extra_prefixes WHERE guild_id = ?", guildID); err != nil {
+		return err
+	}
+
+	for _, extra := range prefixes.Prefixes[1:] {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO guild_extra_prefixes (guild_id, prefix) VALUES (?, ?)", guildID, extra); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteGuildPrefixes resets a guild to the global prefix
+func (d *Database) DeleteGuildPrefixes(guildID string) error {
+	if _, err := d.Exec("DELETE FROM guild_extra_prefixes WHERE guild_id = ?", guildID); err != nil {
+		return err
+	}
+	_, err := d.Exec("DELETE FROM guild_prefixes WHERE guild_id = ?", guildID)
+	return err
+}
+
+// Welcome Methods
+
+// GetWelcomeConfig loads a guild's welcome settings (sql.ErrNoRows if never configured)
+func (d *Database) GetWelcomeConfig(guildID string) (*commands.WelcomeConfig, error) {
+	config := &commands.WelcomeConfig{GuildID: guildID}
+	var channelID, message, imageURL, leaveChannelID, leaveMessage sql.NullString
+	var enabled, channelEnabled, dmEnabled, leaveEnabled int
+
+	err := d.QueryRow(`
+		SELECT channel_id, COALESCE(dm_enabled, 0), COALESCE(channel_enabled, 1), message,
+		       COALESCE(embed_color, 16761035), image_url, COALESCE(enabled, 1),
+		       COALESCE(leave_enabled, 0), leave_channel_id, leave_message
+		FROM welcome WHERE guild_id = ?`, guildID).Scan(
+		&channelID, &dmEnabled, &channelEnabled, &message,
+		&config.EmbedColor, &imageURL, &enabled,
+		&leaveEnabled, &leaveChannelID, &leaveMessage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	config.ChannelID = channelID.String
+	config.DMEnabled = dmEnabled == 1
+	config.ChannelEnabled = channelEnabled == 1
+	config.Message = message.String
+	config.ImageURL = imageURL.String
+	config.Enabled = enabled == 1
+	config.LeaveEnabled = leaveEnabled == 1
+	config.LeaveChannelID = leaveChannelID.String
+	config.LeaveMessage = leaveMessage.String
+
+	return config, nil
+}
+
+// SaveWelcomeConfig stores a guild's welcome settings
+func (d *Database) SaveWelcomeConfig(config *commands.WelcomeConfig) error {
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO welcome (guild_id, channel_id, dm_enabled, channel_enabled, message,
+			embed_color, image_url, enabled, leave_enabled, leave_channel_id, leave_message)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		config.GuildID, config.ChannelID, boolToInt(config.DMEnabled), boolToInt(config.ChannelEnabled),
+		config.Message, config.EmbedColor, config.ImageURL, boolToInt(config.Enabled),
+		boolToInt(config.LeaveEnabled), config.LeaveChannelID, config.LeaveMessage,
+	)
+	return err
+}
+
+// Moderation Case Methods
+
+// CreateModCase stores a case and assigns it the next number for its guild
+func (d *Database) CreateModCase(modCase *commands.ModCase) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var next int
+	err = tx.QueryRow("SELECT COALESCE(MAX(case_number), 0) + 1 FROM mod_cases WHERE guild_id = ?", modCase.GuildID).Scan(&next)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO mod_cases (guild_id, case_number, action, user_id, moderator_id, reason, duration_seconds, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		modCase.GuildID, next, modCase.Action, modCase.UserID, modCase.ModeratorID, modCase.Reason,
+		int64(modCase.Duration/time.Second), modCase.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	modCase.CaseNumber = next
+	return nil
+}
+
+// GetModCase loads a single case
+func (d *Database) GetModCase(guildID string, caseNumber int) (*commands.ModCase, error) {
+	row := d.QueryRow(`
+		SELECT guild_id, case_number, action, user_id, COALESCE(moderator_id, ''), COALESCE(reason, ''),
+		       COALESCE(duration_seconds, 0), COALESCE(created_at, '')
+		FROM mod_cases WHERE guild_id = ? AND case_number = ? AND deleted = 0`, guildID, caseNumber)
+	return scanModCase(row)
+}
+
+// UpdateModCaseReason changes the reason of a case
+func (d *Database) UpdateModCaseReason(guildID string, caseNumber int, reason string) error {
+	result, err := d.Exec("UPDATE mod_cases SET reason = ? WHERE guild_id = ? AND case_number = ? AND deleted = 0",
+		reason, guildID, caseNumber)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteModCase hides a case from history without freeing its number
+func (d *Database) DeleteModCase(guildID string, caseNumber int) error {
+	result, err := d.Exec("UPDATE mod_cases SET deleted = 1 WHERE guild_id = ? AND case_number = ? AND deleted = 0",
+		guildID, caseNumber)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetUserModCases lists a user's cases, newest first
+func (d *Database) GetUserModCases(guildID, userID string) ([]*commands.ModCase, error) {
+	rows, err := d.Query(`
+		SELECT guild_id, case_number, action, user_id, COALESCE(moderator_id, ''), COALESCE(reason, ''),
+		       COALESCE(duration_seconds, 0), COALESCE(created_at, '')
+		FROM mod_cases WHERE guild_id = ? AND user_id = ? AND deleted = 0
+		ORDER BY case_number DESC`, guildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cases := []*commands.ModCase{}
+	for rows.Next() {
+		modCase, err := scanModCase(rows)
+		if err != nil {
+			continue
+		}
+		cases = append(cases, modCase)
+	}
+	return cases, nil
+}
+
+// rowScanner is a *sql.Row or *sql.Rows, for helpers that read either
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanModCase reads a mod_cases row selected in the column order used above
+func scanModCase(row rowScanner) (*commands.ModCase, error) {
+	modCase := &commands.ModCase{}
+	var durationSeconds int64
+	var createdAt string
+
+	err := row.Scan(&modCase.GuildID, &modCase.CaseNumber, &modCase.Action, &modCase.UserID,
+		&modCase.ModeratorID, &modCase.Reason, &durationSeconds, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	modCase.Duration = time.Duration(durationSeconds) * time.Second
+	modCase.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	return modCase, nil
+}
+
+// Scheduled Action Methods
+
+// AddScheduledAction persists a pending action
+func (d *Database) AddScheduledAction(action *commands.ScheduledAction) error {
+	result, err := d.Exec(`
+		INSERT INTO scheduled_actions (guild_id, user_id, action, role_id, execute_at, case_number)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		action.GuildID, action.UserID, action.Action, action.RoleID,
+		action.ExecuteAt.UTC().Format(time.RFC3339), action.CaseNumber)
+	if err != nil {
+		return err
+	}
+	action.ID, _ = result.LastInsertId()
+	return nil
+}
+
+// DeleteScheduledActions removes pending actions of a type for a user
+func (d *Database) DeleteScheduledActions(guildID, userID, action string) (int, error) {
+	result, err := d.Exec("DELETE FROM scheduled_actions WHERE guild_id = ? AND user_id = ? AND action = ?",
+		guildID, userID, action)
+	if err != nil {
+		return 0, err
+	}
+	affected, _ := result.RowsAffected()
+	return int(affected), nil
+}
+
+// DeleteScheduledAction removes a single pending action
+func (d *Database) DeleteScheduledAction(id int64) error {
+	_, err := d.Exec("DELETE FROM scheduled_actions WHERE id = ?", id)
+	return err
+}
+
+// GetDueScheduledActions returns actions whose time (or retry time) has
+// passed, oldest first
+func (d *Database) GetDueScheduledActions(now time.Time) ([]*commands.ScheduledAction, error) {
+	rows, err := d.Query(`
+		SELECT id, guild_id, user_id, action, COALESCE(role_id, ''), execute_at, COALESCE(case_number, 0), COALESCE(attempts, 0)
+		FROM scheduled_actions
+		WHERE COALESCE(retry_at, execute_at) <= ?
+		ORDER BY execute_at`, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := []*commands.ScheduledAction{}
+	for rows.Next() {
+		action := &commands.ScheduledAction{}
+		var executeAt string
+		if err := rows.Scan(&action.ID, &action.GuildID, &action.UserID, &action.Action,
+			&action.RoleID, &executeAt, &action.CaseNumber, &action.Attempts); err != nil {
+			continue
+		}
+		action.ExecuteAt, _ = time.Parse(time.RFC3339, executeAt)
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// RetryScheduledActionAt records a failed attempt and holds the action back
+// until retryAt
+func (d *Database) RetryScheduledActionAt(id int64, retryAt time.Time) error {
+	_, err := d.Exec("UPDATE scheduled_actions SET attempts = attempts + 1, retry_at = ? WHERE id = ?",
+		retryAt.UTC().Format(time.RFC3339), id)
+	return err
+}
+
+// Moderation Config Methods
+
+// GetMuteRole returns the guild's mute role, or "" if none is set
+func (d *Database) GetMuteRole(guildID string) string {
+	var roleID sql.NullString
+	d.QueryRow("SELECT mute_role_id FROM moderation_config WHERE guild_id = ?", guildID).Scan(&roleID)
+	return roleID.String
+}
+
+// SetMuteRole stores the guild's mute role ("" clears it)
+func (d *Database) SetMuteRole(guildID, roleID string) error {
+	_, err := d.Exec(`
+		INSERT INTO moderation_config (guild_id, mute_role_id) VALUES (?, ?)
+		ON CONFLICT(guild_id) DO UPDATE SET mute_role_id = excluded.mute_role_id`,
+		guildID, roleID)
+	return err
+}
+
+// Anti-Raid Methods
+
+// GetRaidSettings returns a guild's anti-raid settings (sql.ErrNoRows if unset)
+func (d *Database) GetRaidSettings(guildID string) (*commands.RaidSettings, error) {
+	settings := &commands.RaidSettings{GuildID: guildID}
+	var enabled, lockdown, raise int
+	err := d.QueryRow(`
+		SELECT enabled, join_threshold, window_seconds, account_age_days, action,
+		       lockdown, raise_verification, duration_minutes
+		FROM anti_raid_config WHERE guild_id = ?`, guildID).
+		Scan(&enabled, &settings.JoinThreshold, &settings.WindowSeconds, &settings.AccountAgeDays,
+			&settings.Action, &lockdown, &raise, &settings.DurationMinutes)
+	if err != nil {
+		return nil, err
+	}
+	settings.Enabled = enabled == 1
+	settings.Lockdown = lockdown == 1
+	settings.RaiseVerification = raise == 1
+	return settings, nil
+}
+
+// SaveRaidSettings stores a guild's anti-raid settings
+func (d *Database) SaveRaidSettings(settings *commands.RaidSettings) error {
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO anti_raid_config
+			(guild_id, enabled, join_threshold, window_seconds, account_age_days, action,
+			 lockdown, raise_verification, duration_minutes)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		settings.GuildID, boolToInt(settings.Enabled), settings.JoinThreshold, settings.WindowSeconds,
+		settings.AccountAgeDays, settings.Action, boolToInt(settings.Lockdown),
+		boolToInt(settings.RaiseVerification), settings.DurationMinutes)
+	return err
+}
+
+// GetRaidLockdown returns a guild's active lockdown (sql.ErrNoRows if none)
+func (d *Database) GetRaidLockdown(guildID string) (*RaidLockdown, error) {
+	lockdown := &RaidLockdown{GuildID: guildID}
+	var reason sql.NullString
+	var startedAt, endsAt string
+	err := d.QueryRow(`
+		SELECT reason, started_at, ends_at, previous_verification
+		FROM raid_lockdowns WHERE guild_id = ?`, guildID).
+		Scan(&reason, &startedAt, &endsAt, &lockdown.PreviousVerification)
+	if err != nil {
+		return nil, err
+	}
+	lockdown.Reason = reason.String
+	lockdown.StartedAt, _ = time.Parse(time.RFC3339, startedAt)
+	lockdown.EndsAt, _ = time.Parse(time.RFC3339, endsAt)
+
+	rows, err := d.Query(`
+		SELECT channel_id, had_overwrite, allow_bits, deny_bits
+		FROM raid_lockdown_channels WHERE guild_id = ?`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var locked LockedChannel
+		var hadOverwrite int
+		if err := rows.Scan(&locked.ChannelID, &hadOverwrite, &locked.Allow, &locked.Deny); err != nil {
+			continue
+		}
+		locked.HadOverwrite = hadOverwrite == 1
+		lockdown.Channels = append(lockdown.Channels, locked)
+	}
+	return lockdown, rows.Err()
+}
+
+// SaveRaidLockdown records a lockdown and the channel overwrites it replaces
+func (d *Database) SaveRaidLockdown(lockdown *RaidLockdown) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT OR REPLACE INTO raid_lockdowns (guild_id, reason, started_at, ends_at, previous_verification)
+		VALUES (?, ?, ?, ?, ?)`,
+		lockdown.GuildID, lockdown.Reason, lockdown.StartedAt.UTC().Format(time.RFC3339),
+		lockdown.EndsAt.UTC().Format(time.RFC3339), lockdown.PreviousVerification)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM raid_lockdown_channels WHERE guild_id = ?", lockdown.GuildID); err != nil {
+		return err
+	}
+	for _, locked := range lockdown.Channels {
+		_, err := tx.Exec(`
+			INSERT INTO raid_lockdown_channels (guild_id, channel_id, had_overwrite, allow_bits, deny_bits)
+			VALUES (?, ?, ?, ?, ?)`,
+			lockdown.GuildID, locked.ChannelID, boolToInt(locked.HadOverwrite), locked.Allow, locked.Deny)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteRaidLockdown forgets a guild's lockdown once it has been lifted
+func (d *Database) DeleteRaidLockdown(guildID string) error {
+	if _, err := d.Exec("DELETE FROM raid_lockdown_channels WHERE guild_id = ?", guildID); err != nil {
+		return err
+	}
+	_, err := d.Exec("DELETE FROM raid_lockdowns WHERE guild_id = ?", guildID)
+	return err
+}
+
+// Anti-Spam Methods
+
+// LoadAntiSpamSettings overlays a guild's stored detectors and escalation onto
+// settings (sql.ErrNoRows if nothing is stored)
+func (d *Database) LoadAntiSpamSettings(settings *commands.AntiSpamSettings) error {
+	found := false
+
+	var escalation string
+	var resetMinutes int
+	err := d.QueryRow(`
+		SELECT escalation, strike_reset_minutes FROM anti_spam_config WHERE guild_id = ?`,
+		settings.GuildID).Scan(&escalation, &resetMinutes)
+	switch {
+	case err == nil:
+		found = true
+		if steps, err := commands.ParseEscalation(strings.Fields(escalation)); err == nil {
+			settings.Escalation = steps
+		}
+		settings.StrikeResetMinutes = resetMinutes
+	case err != sql.ErrNoRows:
+		return err
+	}
+
+	rows, err := d.Query(`
+		SELECT detector, enabled, threshold, window_seconds
+		FROM anti_spam_detectors WHERE guild_id = ?`, settings.GuildID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var enabled, threshold, window int
+		if err := rows.Scan(&name, &enabled, &threshold, &window); err != nil {
+			continue
+		}
+		detector, ok := settings.Detectors[name]
+		if !ok {
+			continue
+		}
+		found = true
+		detector.Enabled = enabled == 1
+		detector.Threshold = threshold
+		if detector.Windowed() {
+			detector.WindowSeconds = window
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if !found {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SaveAntiSpamSettings stores every detector and the escalation ladder
+func (d *Database) SaveAntiSpamSettings(settings *commands.AntiSpamSettings) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	steps := make([]string, len(settings.Escalation))
+	for i, step := range settings.Escalation {
+		steps[i] = step.String()
+	}
+	_, err = tx.Exec(`
+		INSERT OR REPLACE INTO anti_spam_config (guild_id, escalation, strike_reset_minutes)
+		VALUES (?, ?, ?)`,
+		settings.GuildID, strings.Join(steps, " "), settings.StrikeResetMinutes)
+	if err != nil {
+		return err
+	}
+
+	for _, detector := range settings.Detectors {
+		_, err := tx.Exec(`
+			INSERT OR REPLACE INTO anti_spam_detectors (guild_id, detector, enabled, threshold, window_seconds)
+			VALUES (?, ?, ?, ?, ?)`,
+			settings.GuildID, detector.Name, boolToInt(detector.Enabled), detector.Threshold, detector.WindowSeconds)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetProtectedTargets lists a guild's protected roles and users
+func (d *Database) GetProtectedTargets(guildID string) ([]commands.ProtectedTarget, error) {
+	rows, err := d.Query(`
+		SELECT target_type, target_id, added_by, created_at FROM protected_targets WHERE guild_id = ?`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []commands.ProtectedTarget
+	for rows.Next() {
+		t := commands.ProtectedTarget{GuildID: guildID}
+		var addedBy sql.NullString
+		var createdAt string
+		if err := rows.Scan(&t.TargetType, &t.TargetID, &addedBy, &createdAt); err != nil {
+			return nil, err
+		}
+		t.AddedBy = addedBy.String
+		t.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// AddProtectedTarget protects a role or user
+func (d *Database) AddProtectedTarget(t commands.ProtectedTarget) error {
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO protected_targets (guild_id, target_type, target_id, added_by, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		t.GuildID, t.TargetType, t.TargetID, t.AddedBy, t.CreatedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+// RemoveProtectedTarget drops a protection, reporting whether one existed
+func (d *Database) RemoveProtectedTarget(guildID, targetType, targetID string) (bool, error) {
+	result, err := d.Exec(`
+		DELETE FROM protected_targets WHERE guild_id = ? AND target_type = ? AND target_id = ?`,
+		guildID, targetType, targetID)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// GetSpamFilterSettings loads a guild's spam filter settings (sql.ErrNoRows if unset)
+func (d *Database) GetSpamFilterSettings(guildID string) (*commands.SpamFilterSettings, error) {
+	s := &commands.SpamFilterSettings{GuildID: guildID, Customised: true}
+	var mainPrefix, nsfwPrefix, exemptRoles sql.NullString
+	var enabled, allowInvites, banUnauthorized, banHierarchy, sameRole, banEveryone, banHere int
+	err := d.QueryRow(`
+		SELECT enabled, main_channel_prefix, nsfw_channel_prefix, allow_invites, max_consecutive_messages,
+		       warning_lifetime, ban_unauthorized_commands, ban_hierarchy_violation,
+		       allow_same_role_moderation, ban_everyone_mention, ban_here_mention, exempt_roles
+		FROM spam_filter_settings WHERE guild_id = ?`, guildID).Scan(
+		&enabled, &mainPrefix, &nsfwPrefix, &allowInvites, &s.MaxConsecutiveMessages,
+		&s.WarningLifetime, &banUnauthorized, &banHierarchy,
+		&sameRole, &banEveryone, &banHere, &exemptRoles)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Enabled = enabled == 1
+	s.MainChannelPrefix = mainPrefix.String
+	s.NSFWChannelPrefix = nsfwPrefix.String
+	s.AllowInvites = allowInvites == 1
+	s.AutoBanOnUnauthorizedCommands = banUnauthorized == 1
+	s.AutoBanOnHierarchyViolation = banHierarchy == 1
+	s.AllowSameRoleModeration = sameRole == 1
+	s.AutoBanOnEveryoneMention = banEveryone == 1
+	s.AutoBanOnHereMention = banHere == 1
+	s.ExemptRolesFromMentionBan = strings.Fields(exemptRoles.String)
+	return s, nil
+}
+
+// SaveSpamFilterSettings stores a guild's spam filter settings
+func (d *Database) SaveSpamFilterSettings(s *commands.SpamFilterSettings) error {
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO spam_filter_settings
+		(guild_id, enabled, main_channel_prefix, nsfw_channel_prefix, allow_invites, max_consecutive_messages,
+		 warning_lifetime, ban_unauthorized_commands, ban_hierarchy_violation,
+		 allow_same_role_moderation, ban_everyone_mention, ban_here_mention, exempt_roles)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		s.GuildID, boolToInt(s.Enabled), s.MainChannelPrefix, s.NSFWChannelPrefix, boolToInt(s.AllowInvites), s.MaxConsecutiveMessages,
+		s.WarningLifetime, boolToInt(s.AutoBanOnUnauthorizedCommands), boolToInt(s.AutoBanOnHierarchyViolation),
+		boolToInt(s.AllowSameRoleModeration), boolToInt(s.AutoBanOnEveryoneMention), boolToInt(s.AutoBanOnHereMention),
+		strings.Join(s.ExemptRolesFromMentionBan, " "))
+	return err
+}
+
+// DeleteSpamFilterSettings drops a guild's settings so config.toml applies again
+func (d *Database) DeleteSpamFilterSettings(guildID string) error {
+	_, err := d.Exec("DELETE FROM spam_filter_settings WHERE guild_id = ?", guildID)
+	return err
+}
+
+// LoadAutoBanSettings applies a guild's observe mode and rule overrides on top
+// of the defaults. Returns sql.ErrNoRows if the guild has none.
+func (d *Database) LoadAutoBanSettings(settings *commands.AutoBanSettings) error {
+	found := false
+
+	var observe int
+	err := d.QueryRow("SELECT observe FROM auto_ban_config WHERE guild_id = ?", settings.GuildID).Scan(&observe)
+	switch {
+	case err == nil:
+		found = true
+		settings.Observe = observe == 1
+	case err != sql.ErrNoRows:
+		return err
+	}
+
+	rows, err := d.Query("SELECT rule, enabled, action FROM auto_ban_rules WHERE guild_id = ?", settings.GuildID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, action string
+		var enabled int
+		if err := rows.Scan(&name, &enabled, &action); err != nil {
+			continue
+		}
+		rule, ok := settings.Rules[name]
+		if !ok {
+			continue
+		}
+		found = true
+		rule.Enabled = enabled == 1
+		rule.Overridden = true
+		if steps, err := commands.ParseEscalation([]string{action}); err == nil {
+			rule.Step = steps[0]
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if !found {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SaveAutoBanSettings stores observe mode and replaces the rule overrides
+func (d *Database) SaveAutoBanSettings(settings *commands.AutoBanSettings) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("INSERT OR REPLACE INTO auto_ban_config (guild_id, observe) VALUES (?, ?)",
+		settings.GuildID, boolToInt(settings.Observe))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM auto_ban_rules WHERE guild_id = ?", settings.GuildID); err != nil {
+		return err
+	}
+	for _, rule := range settings.Rules {
+		if !rule.Overridden {
+			continue
+		}
+		_, err := tx.Exec(`
+			INSERT INTO auto_ban_rules (guild_id, rule, enabled, action) VALUES (?, ?, ?, ?)`,
+			settings.GuildID, rule.Name, boolToInt(rule.Enabled), rule.Step.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteAntiSpamSettings drops a guild's overrides so the defaults apply again
+func (d *Database) DeleteAntiSpamSettings(guildID string) error {
+	if _, err := d.Exec("DELETE FROM anti_spam_detectors WHERE guild_id = ?", guildID); err != nil {
+		return err
+	}
+	_, err := d.Exec("DELETE FROM anti_spam_config WHERE guild_id = ?", guildID)
+	return err
+}
+
+// Warning Point Methods
+
+// GetWarnSettings returns a guild's warning point settings (sql.ErrNoRows if unset)
+func (d *Database) GetWarnSettings(guildID string) (*commands.WarnSettings, error) {
+	settings := &commands.WarnSettings{GuildID: guildID}
+	var enabled int
+	var thresholds string
+	err := d.QueryRow(`
+		SELECT enabled, points_per_warn, decay_days, thresholds FROM warn_config WHERE guild_id = ?`,
+		guildID).Scan(&enabled, &settings.PointsPerWarn, &settings.DecayDays, &thresholds)
+	if err != nil {
+		return nil, err
+	}
+	settings.Enabled = enabled == 1
+	settings.Thresholds, err = commands.ParseWarnThresholds(strings.Fields(thresholds))
+	if err != nil {
+		log.Printf("Warning: Bad warning thresholds for guild %s: %v", guildID, err)
+	}
+	return settings, nil
+}
+
+// SaveWarnSettings stores a guild's warning point settings
+func (d *Database) SaveWarnSettings(settings *commands.WarnSettings) error {
+	thresholds := make([]string, len(settings.Thresholds))
+	for i, t := range settings.Thresholds {
+		thresholds[i] = t.String()
+	}
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO warn_config (guild_id, enabled, points_per_warn, decay_days, thresholds)
+		VALUES (?, ?, ?, ?, ?)`,
+		settings.GuildID, boolToInt(settings.Enabled), settings.PointsPerWarn, settings.DecayDays,
+		strings.Join(thresholds, " "))
+	return err
+}
+
+// AddWarningPoints adds an entry to a member's points ledger
+func (d *Database) AddWarningPoints(p *commands.WarningPoint) error {
+	var expiresAt interface{}
+	if !p.ExpiresAt.IsZero() {
+		expiresAt = p.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	_, err := d.Exec(`
+		INSERT INTO warning_points (guild_id, user_id, points, reason, case_number, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		p.GuildID, p.UserID, p.Points, p.Reason, p.CaseNumber, p.CreatedAt.UTC().Format(time.RFC3339), expiresAt)
+	return err
+}
+
+// GetActiveWarningPoints lists a member's ledger entries that haven't expired by now
+func (d *Database) GetActiveWarningPoints(guildID, userID string, now time.Time) ([]commands.WarningPoint, error) {
+	rows, err := d.Query(`
+		SELECT id, points, reason, case_number, created_at, expires_at
+		FROM warning_points
+		WHERE guild_id = ? AND user_id = ? AND (expires_at IS NULL OR expires_at > ?)
+		ORDER BY id`,
+		guildID, userID, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []commands.WarningPoint
+	for rows.Next() {
+		p := commands.WarningPoint{GuildID: guildID, UserID: userID}
+		var reason, expiresAt sql.NullString
+		var createdAt string
+		if err := rows.Scan(&p.ID, &p.Points, &reason, &p.CaseNumber, &createdAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		p.Reason = reason.String
+		p.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		if expiresAt.Valid {
+			p.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt.String)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// ClearWarningPoints deletes a member's whole ledger
+func (d *Database) ClearWarningPoints(guildID, userID string) error {
+	_, err := d.Exec("DELETE FROM warning_points WHERE guild_id = ? AND user_id = ?", guildID, userID)
+	return err
+}
+
+// DeleteWarningPointsForCase removes the points a deleted case gave
+func (d *Database) DeleteWarningPointsForCase(guildID string, caseNumber int) error {
+	_, err := d.Exec("DELETE FROM warning_points WHERE guild_id = ? AND case_number = ?", guildID, caseNumber)
+	return err
+}
+
+// Regex Filter Methods
+
+// regexFilterTable returns the table holding guild-wide or channel filters
+func regexFilterTable(channel bool) string {
+	if channel {
+		return "channel_regex_filters"
+	}
+	return "regex_filters"
+}
+
+// GetAllRegexFilters returns a guild's guild-wide and channel filters, in ID order
+func (d *Database) GetAllRegexFilters(guildID string) ([]commands.RegexFilter, error) {
+	filters, err := d.GetRegexFilters(guildID, "")
+	if err != nil {
+		return nil, err
+	}
+	channelFilters, err := d.queryRegexFilters(`
+		SELECT id, channel_id, pattern, action, reason, enabled, created_by, hits, last_hit_at
+		FROM channel_regex_filters WHERE guild_id = ? ORDER BY id`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	return append(filters, channelFilters...), nil
+}
+
+// GetRegexFilters returns a guild's filters for one channel, or its guild-wide
+// filters when channelID is empty
+func (d *Database) GetRegexFilters(guildID, channelID string) ([]commands.RegexFilter, error) {
+	if channelID == "" {
+		return d.queryRegexFilters(`
+			SELECT id, '', pattern, action, reason, enabled, created_by, hits, last_hit_at
+			FROM regex_filters WHERE guild_id = ? ORDER BY id`, guildID)
+	}
+	return d.queryRegexFilters(`
+		SELECT id, channel_id, pattern, action, reason, enabled, created_by, hits, last_hit_at
+		FROM channel_regex_filters WHERE guild_id = ? AND channel_id = ? ORDER BY id`, guildID, channelID)
+}
+
+func (d *Database) queryRegexFilters(query string, args ...interface{}) ([]commands.RegexFilter, error) {
+	rows, err := d.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var filters []commands.RegexFilter
+	for rows.Next() {
+		var f commands.RegexFilter
+		var reason, createdBy, lastHit sql.NullString
+		var hits sql.NullInt64
+		var enabled int
+		if err := rows.Scan(&f.ID, &f.ChannelID, &f.Pattern, &f.Action, &reason, &enabled,
+			&createdBy, &hits, &lastHit); err != nil {
+			return nil, err
+		}
+		f.Reason = reason.String
+		f.Enabled = enabled == 1
+		f.CreatedBy = createdBy.String
+		f.Hits = hits.Int64
+		if lastHit.Valid {
+			f.LastHit, _ = time.Parse(time.RFC3339, lastHit.String)
+		}
+		filters = append(filters, f)
+	}
+	return filters, rows.Err()
+}
+
+// AddRegexFilter stores a guild-wide or channel filter and returns its ID
+func (d *Database) AddRegexFilter(f *commands.RegexFilter) (int, error) {
+	timestamp := time.Now().Format(time.RFC3339)
+
+	var result sql.Result
+	var err error
+	if f.ChannelID == "" {
+		result, err = d.Exec(`
+			INSERT INTO regex_filters (guild_id, pattern, action, reason, enabled, created_by, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			f.GuildID, f.Pattern, f.Action, f.Reason, boolToInt(f.Enabled), f.CreatedBy, timestamp)
+	} else {
+		result, err = d.Exec(`
+			INSERT INTO channel_regex_filters (guild_id, channel_id, pattern, action, reason, enabled, created_by, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			f.GuildID, f.ChannelID, f.Pattern, f.Action, f.Reason, boolToInt(f.Enabled), f.CreatedBy, timestamp)
+	}
+	if err != nil {
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	return int(id), err
+}
+
+// RemoveRegexFilter deletes a filter, reporting whether one existed
+func (d *Database) RemoveRegexFilter(guildID, channelID string, id int) (bool, error) {
+	var result sql.Result
+	var err error
+	if channelID == "" {
+		result, err = d.Exec("DELETE FROM regex_filters WHERE guild_id = ? AND id = ?", guildID, id)
+	} else {
+		result, err = d.Exec("DELETE FROM channel_regex_filters WHERE guild_id = ? AND channel_id = ? AND id = ?",
+			guildID, channelID, id)
+	}
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// SetRegexFilterEnabled turns a filter on or off, reporting whether it exists
+func (d *Database) SetRegexFilterEnabled(guildID, channelID string, id int, enabled bool) (bool, error) {
+	var result sql.Result
+	var err error
+	if channelID == "" {
+		result, err = d.Exec("UPDATE regex_filters SET enabled = ? WHERE guild_id = ? AND id = ?",
+			boolToInt(enabled), guildID, id)
+	} else {
+		result, err = d.Exec("UPDATE channel_regex_filters SET enabled = ? WHERE guild_id = ? AND channel_id = ? AND id = ?",
+			boolToInt(enabled), guildID, channelID, id)
+	}
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// DisableRegexFilter turns a filter off without deleting it
+func (d *Database) DisableRegexFilter(id int, channel bool) error {
+	_, err := d.Exec("UPDATE "+regexFilterTable(channel)+" SET enabled = 0 WHERE id = ?", id)
+	return err
+}
+
+// AddRegexFilterHits adds to a filter's hit counter
+func (d *Database) AddRegexFilterHits(id int, channel bool, hits int64, lastHit time.Time) error {
+	_, err := d.Exec("UPDATE "+regexFilterTable(channel)+
+		" SET hits = COALESCE(hits, 0) + ?, last_hit_at = ? WHERE id = ?",
+		hits, lastHit.UTC().Format(time.RFC3339), id)
+	return err
+}
+
+// Link Filter Methods
+
+// GetLinkFilterSettings returns a guild's link filter settings (sql.ErrNoRows if unset)
+func (d *Database) GetLinkFilterSettings(guildID string) (*commands.LinkFilterSettings, error) {
+	settings := &commands.LinkFilterSettings{GuildID: guildID}
+	var enabled int
+	err := d.QueryRow(`
+		SELECT enabled, invite_action, link_action FROM link_filter_config WHERE guild_id = ?`,
+		guildID).Scan(&enabled, &settings.InviteAction, &settings.LinkAction)
+	if err != nil {
+		return nil, err
+	}
+	settings.Enabled = enabled == 1
+	return settings, nil
+}
+
+// SaveLinkFilterSettings stores a guild's link filter settings
+func (d *Database) SaveLinkFilterSettings(settings *commands.LinkFilterSettings) error {
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO link_filter_config (guild_id, enabled, invite_action, link_action)
+		VALUES (?, ?, ?, ?)`,
+		settings.GuildID, boolToInt(settings.Enabled), settings.InviteAction, settings.LinkAction)
+	return err
+}
+
+// GetLinkRules returns a guild's domain allow and block rules
+func (d *Database) GetLinkRules(guildID string) ([]commands.LinkRule, error) {
+	rows, err := d.Query(`
+		SELECT channel_id, domain, kind FROM link_rules WHERE guild_id = ?`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []commands.LinkRule
+	for rows.Next() {
+		rule := commands.LinkRule{GuildID: guildID}
+		if err := rows.Scan(&rule.ChannelID, &rule.Domain, &rule.Kind); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// SetLinkRule adds or replaces a domain rule
+func (d *Database) SetLinkRule(rule commands.LinkRule) error {
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO link_rules (guild_id, channel_id, domain, kind)
+		VALUES (?, ?, ?, ?)`,
+		rule.GuildID, rule.ChannelID, rule.Domain, rule.Kind)
+	return err
+}
+
+// RemoveLinkRule deletes a domain rule, reporting whether one existed
+func (d *Database) RemoveLinkRule(guildID, channelID, domain string) (bool, error) {
+	result, err := d.Exec(`
+		DELETE FROM link_rules WHERE guild_id = ? AND channel_id = ? AND domain = ?`,
+		guildID, channelID, domain)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Channel Policy Methods
+
+// GetChannelPolicies returns a guild's channel and prefix content policies
+func (d *Database) GetChannelPolicies(guildID string) ([]commands.ChannelPolicy, error) {
+	rows, err := d.Query(`
+		SELECT target_type, target, policy FROM channel_policies WHERE guild_id = ?`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var policies []commands.ChannelPolicy
+	for rows.Next() {
+		p := commands.ChannelPolicy{GuildID: guildID}
+		if err := rows.Scan(&p.TargetType, &p.Target, &p.Policy); err != nil {
+			return nil, err
+		}
+		policies = append(policies, p)
+	}
+	return policies, rows.Err()
+}
+
+// SetChannelPolicy adds or replaces a channel or prefix policy
+func (d *Database) SetChannelPolicy(p commands.ChannelPolicy) error {
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO channel_policies (guild_id, target_type, target, policy)
+		VALUES (?, ?, ?, ?)`,
+		p.GuildID, p.TargetType, p.Target, p.Policy)
+	return err
+}
+
+// RemoveChannelPolicy deletes a policy, reporting whether one existed
+func (d *Database) RemoveChannelPolicy(guildID, targetType, target string) (bool, error) {
+	result, err := d.Exec(`
+		DELETE FROM channel_policies WHERE guild_id = ? AND target_type = ? AND target = ?`,
+		guildID, targetType, target)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// Leveling Methods
+
+// GetLevelSettings returns a guild's stored level curve (sql.ErrNoRows if none)
+func (d *Database) GetLevelSettings(guildID string) (leveling.Settings, error) {
+	var settings leveling.Settings
+	err := d.QueryRow(`
+		SELECT curve, param_a, param_b, param_c FROM leveling_config WHERE guild_id = ?`,
+		guildID).Scan(&settings.Curve, &settings.A, &settings.B, &settings.C)
+	return settings, err
+}
+
+// SetLevelSettings stores a guild's level curve
+func (d *Database) SetLevelSettings(guildID string, settings leveling.Settings) error {
+	_, err := d.Exec(`
+		INSERT INTO leveling_config (guild_id, curve, param_a, param_b, param_c) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(guild_id) DO UPDATE SET
+			curve = excluded.curve, param_a = excluded.param_a,
+			param_b = excluded.param_b, param_c = excluded.param_c`,
+		guildID, settings.Curve, settings.A, settings.B, settings.C)
+	return err
+}
+
+// GetXPSettings returns a guild's message XP overrides (sql.ErrNoRows if none)
+func (d *Database) GetXPSettings(guildID string) (*commands.XPSettings, error) {
+	settings := &commands.XPSettings{GuildID: guildID}
+	var channelID sql.NullString
+	err := d.QueryRow(`
+		SELECT xp_min, xp_max, cooldown_seconds, levelup_mode, levelup_channel_id
+		FROM xp_settings WHERE guild_id = ?`, guildID).
+		Scan(&settings.MinXP, &settings.MaxXP, &settings.CooldownSeconds, &settings.LevelUpMode, &channelID)
+	if err != nil {
+		return nil, err
+	}
+	settings.LevelUpChannelID = channelID.String
+	return settings, nil
+}
+
+// SaveXPSettings stores a guild's message XP overrides
+func (d *Database) SaveXPSettings(settings *commands.XPSettings) error {
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO xp_settings
+			(guild_id, xp_min, xp_max, cooldown_seconds, levelup_mode, levelup_channel_id)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		settings.GuildID, settings.MinXP, settings.MaxXP, settings.CooldownSeconds,
+		settings.LevelUpMode, settings.LevelUpChannelID)
+	return err
+}
+
+// DeleteXPSettings drops a guild's overrides so config.toml applies again
+func (d *Database) DeleteXPSettings(guildID string) error {
+	_, err := d.Exec("DELETE FROM xp_settings WHERE guild_id = ?", guildID)
+	return err
+}
+
+// GetXPMultipliers returns every channel and role multiplier for a guild
+func (d *Database) GetXPMultipliers(guildID string) ([]commands.XPMultiplier, error) {
+	rows, err := d.Query(`
+		SELECT target_type, target_id, multiplier
+		FROM xp_multipliers WHERE guild_id = ?`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var multipliers []commands.XPMultiplier
+	for rows.Next() {
+		m := commands.XPMultiplier{GuildID: guildID}
+		if err := rows.Scan(&m.TargetType, &m.TargetID, &m.Multiplier); err != nil {
+			return nil, err
+		}
+		multipliers = append(multipliers, m)
+	}
+	return multipliers, rows.Err()
+}
+
+// SetXPMultiplier adds or replaces a channel or role multiplier
+func (d *Database) SetXPMultiplier(m *commands.XPMultiplier) error {
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO xp_multipliers (guild_id, target_type, target_id, multiplier)
+		VALUES (?, ?, ?, ?)`,
+		m.GuildID, m.TargetType, m.TargetID, m.Multiplier)
+	return err
+}
+
+// RemoveXPMultiplier deletes a multiplier, reporting whether one existed
+func (d *Database) RemoveXPMultiplier(guildID, targetType, targetID string) (bool, error) {
+	result, err := d.Exec(`
+		DELETE FROM xp_multipliers WHERE guild_id = ? AND target_type = ? AND target_id = ?`,
+		guildID, targetType, targetID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// GetUserXP returns a member's stored XP and level (zero for unknown members)
+func (d *Database) GetUserXP(guildID, userID string) (int64, int, error) {
+	var exp int64
+	var level int
+	err := d.QueryRow("SELECT exp, level FROM glevel WHERE guild_id = ? AND user_id = ?",
+		guildID, userID).Scan(&exp, &level)
+	if err == sql.ErrNoRows {
+		return 0, 0, nil
+	}
+	return exp, level, err
+}
+
+// SetUserXP stores a member's XP and level, keeping their enabled flag
+func (d *Database) SetUserXP(guildID, userID string, exp int64, level int) error {
+	_, err := d.Exec(`
+		INSERT INTO glevel (guild_id, user_id, exp, level, enabled, text_exp) VALUES (?, ?, ?, ?, 'enabled', ?)
+		ON CONFLICT(guild_id, user_id) DO UPDATE SET exp = excluded.exp, level = excluded.level, `+rescaleXPSplit("excluded.exp"),
+		guildID, userID, exp, level, exp)
+	return err
+}
+
+// GetGuildRanks returns a guild's level → role mappings, lowest level first
+func (d *Database) GetGuildRanks(guildID string) ([]commands.GuildRank, error) {
+	rows, err := d.Query("SELECT role_id, level FROM ranks WHERE guild_id = ? ORDER BY level", guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ranks []commands.GuildRank
+	for rows.Next() {
+		var rank commands.GuildRank
+		if err := rows.Scan(&rank.RoleID, &rank.Level); err != nil {
+			continue
+		}
+		ranks = append(ranks, rank)
+	}
+	return ranks, rows.Err()
+}
+
+// GetGuildLevels returns the stored level of every member of a guild
+func (d *Database) GetGuildLevels(guildID string) (map[string]int, error) {
+	rows, err := d.Query("SELECT user_id, level FROM glevel WHERE guild_id = ?", guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := make(map[string]int)
+	for rows.Next() {
+		var userID string
+		var level int
+		if err := rows.Scan(&userID, &level); err != nil {
+			continue
+		}
+		levels[userID] = level
+	}
+	return levels, rows.Err()
+}
+
+// GetRankMode returns a guild's rank reward mode (sql.ErrNoRows if unset)
+func (d *Database) GetRankMode(guildID string) (string, error) {
+	var mode string
+	err := d.QueryRow("SELECT mode FROM rank_config WHERE guild_id = ?", guildID).Scan(&mode)
+	return mode, err
+}
+
+// SetRankMode stores a guild's rank reward mode
+func (d *Database) SetRankMode(guildID, mode string) error {
+	_, err := d.Exec(`
+		INSERT INTO rank_config (guild_id, mode) VALUES (?, ?)
+		ON CONFLICT(guild_id) DO UPDATE SET mode = excluded.mode`,
+		guildID, mode)
+	return err
+}
+
+// rescaleXPSplit is the SET clause that keeps text_exp + voice_exp equal to
+// exp when exp is set outright: the new total is split in the same
+// proportions as before, and XP with no split yet counts as text
+func rescaleXPSplit(newExp string) string {
+	return `text_exp = CASE WHEN text_exp + voice_exp > 0
+			THEN ` + newExp + ` * text_exp / (text_exp + voice_exp) ELSE ` + newExp + ` END,
+		voice_exp = CASE WHEN text_exp + voice_exp > 0
+			THEN ` + newExp + ` - ` + newExp + ` * text_exp / (text_exp + voice_exp) ELSE 0 END`
+}
+
+// leaderboardColumn maps a leaderboard type to its XP column
+func leaderboardColumn(board string) string {
+	switch board {
+	case commands.LeaderboardText:
+		return "text_exp"
+	case commands.LeaderboardVoice:
+		return "voice_exp"
+	default:
+		return "exp"
+	}
+}
+
+// GetLeaderboard returns one page of a guild's leaderboard, highest XP first
+func (d *Database) GetLeaderboard(guildID, board string, offset, limit int) ([]commands.LeaderboardEntry, error) {
+	column := leaderboardColumn(board)
+	rows, err := d.Query(`
+		SELECT user_id, `+column+`, level FROM glevel
+		WHERE guild_id = ? AND `+column+` > 0
+		ORDER BY `+column+` DESC, user_id
+		LIMIT ? OFFSET ?`, guildID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []commands.LeaderboardEntry
+	for rows.Next() {
+		var entry commands.LeaderboardEntry
+		if err := rows.Scan(&entry.UserID, &entry.XP, &entry.Level); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// CountLeaderboard returns how many members are ranked on a leaderboard
+func (d *Database) CountLeaderboard(guildID, board string) (int, error) {
+	column := leaderboardColumn(board)
+	var count int
+	err := d.QueryRow(`SELECT COUNT(*) FROM glevel WHERE guild_id = ? AND `+column+` > 0`, guildID).Scan(&count)
+	return count, err
+}
+
+// GetLeaderboardPosition returns a member's 1-based rank and XP (rank 0 if unranked)
+func (d *Database) GetLeaderboardPosition(guildID, board, userID string) (int, int64, error) {
+	column := leaderboardColumn(board)
+
+	var xp int64
+	err := d.QueryRow(`SELECT `+column+` FROM glevel WHERE guild_id = ? AND user_id = ?`, guildID, userID).Scan(&xp)
+	if err == sql.ErrNoRows || (err == nil && xp <= 0) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Same ordering as GetLeaderboard: XP descending, ties by user ID.
+	// Two counts so each one is a range seek on the leaderboard index.
+	var ahead int
+	err = d.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM glevel WHERE guild_id = ? AND `+column+` > ?) +
+			(SELECT COUNT(*) FROM glevel WHERE guild_id = ? AND `+column+` = ? AND user_id < ?)`,
+		guildID, xp, guildID, xp, userID).Scan(&ahead)
+	if err != nil {
+		return 0, 0, err
+	}
+	return ahead + 1, xp, nil
+}
+
+// RecalculateLevels rewrites a guild's stored levels for a curve. With keepLevels
+// the levels stay and XP is moved to the start of each level instead.
+func (d *Database) RecalculateLevels(guildID string, curve leveling.Curve, keepLevels bool) (int, error) {
+	tx, err := d.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT user_id, exp, level FROM glevel WHERE guild_id = ?", guildID)
+	if err != nil {
+		return 0, err
+	}
+
+	type levelUpdate struct {
+		userID string
+		exp    int64
+		level  int
+	}
+	var updates []levelUpdate
+	for rows.Next() {
+		var u levelUpdate
+		if err := rows.Scan(&u.userID, &u.exp, &u.level); err != nil {
+			continue
+		}
+		if keepLevels {
+			if exp := curve.ExpForLevel(u.level); exp != u.exp {
+				u.exp = exp
+				updates = append(updates, u)
+			}
+		} else if level := curve.LevelForExp(u.exp); level != u.level {
+			u.level = level
+			updates = append(updates, u)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE glevel SET exp = ?1, level = ?2, " + rescaleXPSplit("?1") + " WHERE guild_id = ?3 AND user_id = ?4")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, u := range updates {
+		if _, err := stmt.Exec(u.exp, u.level, guildID, u.userID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(updates), nil
+}
+
+// boolToInt converts a flag to the 0/1 form stored in SQLite
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// GetLogWebhook loads the webhook logs are posted through in a channel
+func (d *Database) GetLogWebhook(channelID string) (string, string, error) {
+	var id, token string
+	err := d.QueryRow(`SELECT webhook_id, webhook_token FROM log_webhooks WHERE channel_id = ?`, channelID).Scan(&id, &token)
+	return id, token, err
+}
+
+// SaveLogWebhook stores the webhook created for a log channel
+func (d *Database) SaveLogWebhook(guildID, channelID, webhookID, token string) error {
+	_, err := d.Exec(`
+		INSERT OR REPLACE INTO log_webhooks (channel_id, guild_id, webhook_id, webhook_token, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		channelID, guildID, webhookID, token, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// DeleteLogWebhook forgets a log channel's webhook
+func (d *Database) DeleteLogWebhook(channelID string) error {
+	_, err := d.Exec(`DELETE FROM log_webhooks WHERE channel_id = ?`, channelID)
+	return err
+}
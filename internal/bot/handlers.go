@@ -3,8 +3,6 @@ package bot
 import (
 	"fmt"
 	"log"
-	"math"
-	"strconv"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -15,6 +13,8 @@ func (b *Bot) giveXP(s *discordgo.Session, guildID, userID, channelID string, xp
 
 	DebugLog("Giving %d XP to user %s in guild %s", xp, userID, guildID)
 
+	curve := b.LevelCurve(guildID)
+
 	tx, err := b.DB.Begin()
 	if err != nil {
 		log.Printf("Failed to begin transaction: %v", err)
@@ -47,19 +47,21 @@ func (b *Bot) giveXP(s *discordgo.Session, guildID, userID, channelID string, xp
 	}
 
 	newXP := currentXP + xp
-	newLevel := int(math.Floor((math.Sqrt(1 + 8*float64(newXP)/50) - 1) / 2))
+	newLevel := curve.LevelForExp(int64(newXP))
 
 	if newLevel > level {
 		DebugLog("User %s leveled up! %d -> %d", userID, level, newLevel)
-		user, err := s.User(userID)
-		if err == nil {
-			s.ChannelMessageSend(channelID,
-				user.Mention()+" just reached **Level "+strconv.Itoa(newLevel)+"**! ♡")
-		}
+		go b.announceLevelUp(guildID, userID, channelID, newLevel)
 	}
 
-	_, err = tx.Exec(`INSERT OR REPLACE INTO glevel (guild_id, user_id, exp, level, enabled)
-	         VALUES (?, ?, ?, ?, 'enabled')`, guildID, userID, newXP, newLevel)
+	// Voice XP is granted without a channel
+	sourceColumn := "text_exp"
+	if channelID == "" {
+		sourceColumn = "voice_exp"
+	}
+
+	_, err = tx.Exec(`UPDATE glevel SET exp = ?, level = ?, `+sourceColumn+` = `+sourceColumn+` + ?
+	         WHERE guild_id = ? AND user_id = ?`, newXP, newLevel, xp, guildID, userID)
 	if err != nil {
 		log.Printf("Failed to update XP: %v", err)
 		return
@@ -67,36 +69,12 @@ func (b *Bot) giveXP(s *discordgo.Session, guildID, userID, channelID string, xp
 	
 	tx.Commit()
 
-	// Auto-role logic (you'll expand this later)
-	b.checkLevelRoles(guildID, userID, newLevel)
-}
-
-func (b *Bot) checkLevelRoles(guildID, userID string, level int) {
-	rows, err := b.DB.Query(`
-		SELECT role_id FROM ranks WHERE guild_id = ? AND level <= ?`, guildID, level)
-	if err != nil {
-		return
-	}
-	defer rows.Close()
-
-	var roleID string
-	for rows.Next() {
-		rows.Scan(&roleID)
-		go b.Session.GuildMemberRoleAdd(guildID, userID, roleID)
-	}
-}
-
-func (b *Bot) onMemberJoin(s *discordgo.Session, m *discordgo.GuildMemberAdd) {
-	defer RecoverFromPanic("onMemberJoin")
-
-	DebugLog("Member joined: %s in guild %s", m.User.String(), m.GuildID)
-
-	if Global.Debug.PrintRawEvents {
-		log.Printf("[RAW EVENT] GuildMemberAdd: %+v", m)
+	// Rank roles only change with the level
+	if newLevel != level {
+		if _, _, err := b.syncRankRoles(guildID, userID, newLevel); err != nil {
+			DebugLog("Failed to sync rank roles: %v", err)
+		}
 	}
-
-	// Welcome system placeholder – fully ported next if you want
-	log.Printf("%s joined %s", m.User.String(), m.GuildID)
 }
 
 // Voice XP tracking handler
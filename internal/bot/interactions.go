@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
+)
+
+// registerSlashCommands publishes the command set as global application commands
+func (b *Bot) registerSlashCommands(s *discordgo.Session) {
+	defer RecoverFromPanic("registerSlashCommands")
+
+	appCommands := b.Commands.ApplicationCommands()
+	registered, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, "", appCommands)
+	if err != nil {
+		log.Printf("Warning: Could not register slash commands: %v", err)
+		return
+	}
+
+	log.Printf("Registered %d slash commands", len(registered))
+}
+
+// onInteractionCreate dispatches slash commands and message components through the command manager
+func (b *Bot) onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	defer RecoverFromPanic("onInteractionCreate")
+
+	if i.Type != discordgo.InteractionApplicationCommand && i.Type != discordgo.InteractionMessageComponent {
+		return
+	}
+
+	user := i.User
+	if i.Member != nil {
+		user = i.Member.User
+	}
+	if user == nil || user.Bot {
+		return
+	}
+
+	// Check if user or server is bot-banned (silently ignore)
+	banned, _ := b.DB.IsBotBanned(user.ID, i.GuildID)
+	if banned {
+		return
+	}
+
+	if i.Type == discordgo.InteractionMessageComponent {
+		ctx := &commands.Context{
+			Session:     s,
+			Interaction: i,
+			Bot:         b,
+		}
+		if err := b.Commands.ExecuteComponent(ctx); err != nil {
+			log.Printf("Component error (%s): %v", i.MessageComponentData().CustomID, err)
+		}
+		return
+	}
+
+	data := i.ApplicationCommandData()
+	DebugLog("Slash command received: /%s from %s", data.Name, user.Username)
+
+	// Same moderation authorization as the prefix dispatcher
+	if i.GuildID != "" && (data.Name == "ban" || data.Name == "kick") {
+		var targetID string
+		for _, opt := range data.Options {
+			if opt.Type == discordgo.ApplicationCommandOptionUser {
+				targetID, _ = opt.Value.(string)
+				break
+			}
+		}
+
+		if result := b.SpamFilter.CheckCommandAuthorization(i.GuildID, user.ID, targetID, data.Name); result != nil {
+			DebugLog("Punishing %s for unauthorized command: %s", user.ID, result.Reason)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "❌ You're not allowed to use /" + data.Name + " on that member.",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			// Slash commands have no message to delete, only the user to act on
+			b.SpamFilter.ExecuteAction(s, &discordgo.MessageCreate{Message: &discordgo.Message{
+				GuildID:   i.GuildID,
+				ChannelID: i.ChannelID,
+				Author:    user,
+				Content:   "/" + data.Name,
+			}}, result)
+			return
+		}
+	}
+
+	ctx := &commands.Context{
+		Session:     s,
+		Interaction: i,
+		Bot:         b,
+	}
+
+	if err := b.Commands.ExecuteInteraction(ctx); err != nil {
+		log.Printf("Command error: %v", err)
+		if Global.Debug.VerboseLogging {
+			log.Printf("Slash command execution failed for '/%s': %v", data.Name, err)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+package bot
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+
+	"yuno-go/internal/commands"
+	"yuno-go/internal/leveling"
+)
+
+// ============================================================================
+// LEVEL CURVES - Every XP grant needs the guild's curve
+// ============================================================================
+
+type cachedLevelCurve struct {
+	settings leveling.Settings
+	curve    leveling.Curve
+}
+
+// defaultLevelSettings is the curve for guilds that never picked one
+func defaultLevelSettings() leveling.Settings {
+	return leveling.DefaultSettings(Global.Leveling.LevelCurve)
+}
+
+// GetLevelSettings returns a guild's curve settings, falling back to config.toml
+func (b *Bot) GetLevelSettings(guildID string) leveling.Settings {
+	settings, _ := b.levelCurve(guildID)
+	return settings
+}
+
+// LevelCurve returns the curve used to turn XP into levels for a guild
+func (b *Bot) LevelCurve(guildID string) leveling.Curve {
+	_, curve := b.levelCurve(guildID)
+	return curve
+}
+
+func (b *Bot) levelCurve(guildID string) (leveling.Settings, leveling.Curve) {
+	if b.LevelCurveCache != nil {
+		if cached, ok := b.LevelCurveCache.Get(guildID); ok {
+			return cached.settings, cached.curve
+		}
+	}
+
+	settings, err := b.DB.GetLevelSettings(guildID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error loading level curve for guild %s: %v", guildID, err)
+		}
+		settings = defaultLevelSettings()
+	}
+	curve := leveling.New(settings)
+
+	if b.LevelCurveCache != nil {
+		b.LevelCurveCache.Set(guildID, cachedLevelCurve{settings: settings, curve: curve})
+	}
+	return settings, curve
+}
+
+// SetLevelSettings stores a guild's curve. Stored levels are left alone until
+// RecalculateLevels runs.
+func (b *Bot) SetLevelSettings(guildID string, settings leveling.Settings) error {
+	if err := b.DB.SetLevelSettings(guildID, settings); err != nil {
+		return err
+	}
+	if b.LevelCurveCache != nil {
+		b.LevelCurveCache.Invalidate(guildID)
+	}
+	return nil
+}
+
+// RecalculateLevels brings stored levels in line with the guild's current curve
+func (b *Bot) RecalculateLevels(guildID string, keepLevels bool) (int, error) {
+	// Pending batched XP would be written with levels from the old curve
+	if b.XPBatcher != nil {
+		b.XPBatcher.flushSync()
+	}
+	changed, err := b.DB.RecalculateLevels(guildID, b.LevelCurve(guildID), keepLevels)
+	if err == nil && changed > 0 && !keepLevels {
+		b.reconcileRankRolesAsync(guildID)
+	}
+	return changed, err
+}
+
+// GetUserXP returns a member's stored XP and level
+func (b *Bot) GetUserXP(guildID, userID string) (int64, int, error) {
+	return b.DB.GetUserXP(guildID, userID)
+}
+
+// SetUserXP stores a member's XP and level and reconciles their rank roles
+func (b *Bot) SetUserXP(guildID, userID string, exp int64, level int) error {
+	if err := b.DB.SetUserXP(guildID, userID, exp, level); err != nil {
+		return err
+	}
+	if _, _, err := b.syncRankRoles(guildID, userID, level); err != nil {
+		DebugLog("Failed to sync rank roles: %v", err)
+	}
+	return nil
+}
+
+// AddUserXP adds XP to a member and recomputes their level with the guild's curve
+func (b *Bot) AddUserXP(guildID, userID string, amount int64) error {
+	exp, _, err := b.DB.GetUserXP(guildID, userID)
+	if err != nil {
+		return err
+	}
+	exp += amount
+	if exp < 0 {
+		exp = 0
+	}
+	return b.SetUserXP(guildID, userID, exp, b.LevelCurve(guildID).LevelForExp(exp))
+}
+
+// GetLeaderboard returns one page of a guild's leaderboard
+func (b *Bot) GetLeaderboard(guildID, board string, offset, limit int) ([]commands.LeaderboardEntry, error) {
+	return b.DB.GetLeaderboard(guildID, board, offset, limit)
+}
+
+// CountLeaderboard returns how many members are ranked on a leaderboard
+func (b *Bot) CountLeaderboard(guildID, board string) (int, error) {
+	return b.DB.CountLeaderboard(guildID, board)
+}
+
+// GetLeaderboardPosition returns a member's rank and XP on a leaderboard
+func (b *Bot) GetLeaderboardPosition(guildID, board, userID string) (int, int64, error) {
+	return b.DB.GetLeaderboardPosition(guildID, board, userID)
+}
+
+// GetGuildRanks returns a guild's level → role mappings
+func (b *Bot) GetGuildRanks(guildID string) ([]commands.GuildRank, error) {
+	return b.DB.GetGuildRanks(guildID)
+}
+
+// ============================================================================
+// XP SETTINGS - Message XP range, cooldown and level-up routing
+// ============================================================================
+
+// defaultXPSettings builds the settings for a guild from the [leveling] config
+func defaultXPSettings(guildID string) *commands.XPSettings {
+	settings := &commands.XPSettings{
+		GuildID:         guildID,
+		MinXP:           15,
+		MaxXP:           25,
+		CooldownSeconds: Global.Leveling.CooldownSeconds,
+	}
+
+	switch xp := Global.Leveling.XpPerMessage; len(xp) {
+	case 0:
+	case 1:
+		settings.MinXP, settings.MaxXP = xp[0], xp[0]
+	default:
+		settings.MinXP, settings.MaxXP = xp[0], xp[1]
+	}
+	if settings.MinXP < 0 {
+		settings.MinXP = 0
+	}
+	if settings.MaxXP < settings.MinXP {
+		settings.MaxXP = settings.MinXP
+	}
+	if settings.CooldownSeconds < 0 {
+		settings.CooldownSeconds = 0
+	}
+
+	// level_up_channel: "" = DM, "here" = current channel, "off" = silent, otherwise a channel ID
+	switch channel := strings.TrimSpace(Global.Leveling.LevelUpChannel); strings.ToLower(channel) {
+	case "":
+		settings.LevelUpMode = commands.LevelUpDM
+	case "here":
+		settings.LevelUpMode = commands.LevelUpHere
+	case "off", "none":
+		settings.LevelUpMode = commands.LevelUpOff
+	default:
+		settings.LevelUpMode = commands.LevelUpChannel
+		settings.LevelUpChannelID = channel
+	}
+
+	return settings
+}
+
+// GetXPSettings returns a guild's message XP settings with config.toml defaults
+func (b *Bot) GetXPSettings(guildID string) *commands.XPSettings {
+	if b.XPSettingsCache != nil {
+		// Cached by value, so commands can edit what they get
+		if settings, ok := b.XPSettingsCache.Get(guildID); ok {
+			return &settings
+		}
+	}
+
+	settings, err := b.DB.GetXPSettings(guildID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error loading XP settings for guild %s: %v", guildID, err)
+		}
+		settings = defaultXPSettings(guildID)
+	}
+
+	if b.XPSettingsCache != nil {
+		b.XPSettingsCache.Set(guildID, *settings)
+	}
+	return settings
+}
+
+// SaveXPSettings stores a guild's message XP settings
+func (b *Bot) SaveXPSettings(settings *commands.XPSettings) error {
+	if err := b.DB.SaveXPSettings(settings); err != nil {
+		return err
+	}
+	if b.XPSettingsCache != nil {
+		b.XPSettingsCache.Invalidate(settings.GuildID)
+	}
+	return nil
+}
+
+// ResetXPSettings drops a guild's overrides
+func (b *Bot) ResetXPSettings(guildID string) error {
+	if err := b.DB.DeleteXPSettings(guildID); err != nil {
+		return err
+	}
+	if b.XPSettingsCache != nil {
+		b.XPSettingsCache.Invalidate(guildID)
+	}
+	return nil
+}
+
+// rollMessageXP picks the XP for one message from the guild's range
+func rollMessageXP(settings *commands.XPSettings) int {
+	if settings.MaxXP <= settings.MinXP {
+		return settings.MinXP
+	}
+	return settings.MinXP + rand.Intn(settings.MaxXP-settings.MinXP+1)
+}
+
+// announceLevelUp sends the level-up message where the guild wants it. channelID
+// is where the XP was earned ("" for voice XP).
+func (b *Bot) announceLevelUp(guildID, userID, channelID string, level int) {
+	settings := b.GetXPSettings(guildID)
+	message := fmt.Sprintf("<@%s> just reached **Level %d**! ♡", userID, level)
+
+	switch settings.LevelUpMode {
+	case commands.LevelUpOff:
+		return
+
+	case commands.LevelUpDM:
+		dm, err := b.Session.UserChannelCreate(userID)
+		if err != nil {
+			DebugLog("Failed to open DM with %s for level-up: %v", userID, err)
+			return
+		}
+		guildName := "the server"
+		if guild, err := b.Session.State.Guild(guildID); err == nil {
+			guildName = "**" + guild.Name + "**"
+		}
+		message = fmt.Sprintf("You just reached **Level %d** in %s! ♡", level, guildName)
+		channelID = dm.ID
+
+	case commands.LevelUpChannel:
+		channelID = settings.LevelUpChannelID
+	}
+
+	if channelID == "" {
+		return
+	}
+	if _, err := b.Session.ChannelMessageSend(channelID, message); err != nil {
+		DebugLog("Failed to send level-up message in %s: %v", channelID, err)
+	}
+}
+
+// ============================================================================
+// XP MULTIPLIERS - Channel and role multipliers, checked on every grant
+// ============================================================================
+
+// GetXPMultipliers returns a guild's channel and role multipliers
+func (b *Bot) GetXPMultipliers(guildID string) []commands.XPMultiplier {
+	if b.XPMultiplierCache != nil {
+		if multipliers, ok := b.XPMultiplierCache.Get(guildID); ok {
+			// Callers may sort the list
+			return append([]commands.XPMultiplier(nil), multipliers...)
+		}
+	}
+
+	multipliers, err := b.DB.GetXPMultipliers(guildID)
+	if err != nil {
+		log.Printf("Error loading XP multipliers for guild %s: %v", guildID, err)
+		return nil
+	}
+
+	if b.XPMultiplierCache != nil {
+		b.XPMultiplierCache.Set(guildID, multipliers)
+	}
+	return append([]commands.XPMultiplier(nil), multipliers...)
+}
+
+// SetXPMultiplier stores a channel or role multiplier
+func (b *Bot) SetXPMultiplier(multiplier *commands.XPMultiplier) error {
+	if err := b.DB.SetXPMultiplier(multiplier); err != nil {
+		return err
+	}
+	if b.XPMultiplierCache != nil {
+		b.XPMultiplierCache.Invalidate(multiplier.GuildID)
+	}
+	return nil
+}
+
+// RemoveXPMultiplier drops a channel or role multiplier
+func (b *Bot) RemoveXPMultiplier(guildID, targetType, targetID string) (bool, error) {
+	removed, err := b.DB.RemoveXPMultiplier(guildID, targetType, targetID)
+	if err != nil {
+		return false, err
+	}
+	if b.XPMultiplierCache != nil {
+		b.XPMultiplierCache.Invalidate(guildID)
+	}
+	return removed, nil
+}
+
+// xpMultiplier returns the factor to apply to XP earned in channelID by a member
+// holding roleIDs. Threads and channels inherit their parent's multiplier.
+func (b *Bot) xpMultiplier(guildID, channelID string, roleIDs []string) float64 {
+	multipliers := b.GetXPMultipliers(guildID)
+	if len(multipliers) == 0 {
+		return 1
+	}
+
+	// Thread → channel → category; the State only knows so many levels
+	var channelIDs []string
+	for id := channelID; id != "" && len(channelIDs) < 3; {
+		channelIDs = append(channelIDs, id)
+		channel, err := b.Session.State.Channel(id)
+		if err != nil {
+			break
+		}
+		id = channel.ParentID
+	}
+
+	return commands.ResolveXPMultiplier(multipliers, channelIDs, roleIDs)
+}
+
+// applyXPMultiplier scales a grant, rounding to the nearest whole XP
+func applyXPMultiplier(xp int, multiplier float64) int {
+	if multiplier == 1 {
+		return xp
+	}
+	return int(math.Round(float64(xp) * multiplier))
+}
@@ -0,0 +1,297 @@
+package bot
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
+)
+
+var (
+	// inviteRegex matches discord.gg/code and discord.com/invite/code
+	inviteRegex = regexp.MustCompile(`(?i)(?:https?://)?(?:www\.)?(?:discord\.gg|discord(?:app)?\.com/invite)/([a-z0-9-]+)`)
+	// linkRegex matches the URLs Discord turns into links
+	linkRegex = regexp.MustCompile(`(?i)https?://[^\s<>]+`)
+)
+
+// inviteCacheTTL is how long a resolved invite code is trusted
+const inviteCacheTTL = 10 * time.Minute
+
+// ============================================================================
+// LINK FILTER SETTINGS - Settings and domain rules, checked on every message
+// ============================================================================
+
+type cachedLinkFilter struct {
+	settings *commands.LinkFilterSettings
+	rules    []commands.LinkRule
+}
+
+// defaultLinkFilterSettings follows the guild's allow_invites spam filter setting
+func defaultLinkFilterSettings(sfs *commands.SpamFilterSettings) *commands.LinkFilterSettings {
+	inviteAction := "ban"
+	if sfs.AllowInvites {
+		inviteAction = "off"
+	}
+	return &commands.LinkFilterSettings{
+		GuildID:      sfs.GuildID,
+		Enabled:      true,
+		InviteAction: inviteAction,
+		LinkAction:   "warn",
+	}
+}
+
+// loadLinkFilter returns a guild's cached settings and rules
+func (b *Bot) loadLinkFilter(guildID string) *cachedLinkFilter {
+	if b.LinkFilterCache != nil {
+		if cached, ok := b.LinkFilterCache.Get(guildID); ok {
+			return cached
+		}
+	}
+
+	settings, err := b.DB.GetLinkFilterSettings(guildID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error loading link filter settings for guild %s: %v", guildID, err)
+		}
+		settings = defaultLinkFilterSettings(b.GetSpamFilterSettings(guildID))
+	}
+
+	rules, err := b.DB.GetLinkRules(guildID)
+	if err != nil {
+		log.Printf("Error loading link rules for guild %s: %v", guildID, err)
+	}
+
+	cached := &cachedLinkFilter{settings: settings, rules: rules}
+	if b.LinkFilterCache != nil {
+		b.LinkFilterCache.Set(guildID, cached)
+	}
+	return cached
+}
+
+// GetLinkFilterSettings returns a copy of a guild's link filter settings
+func (b *Bot) GetLinkFilterSettings(guildID string) *commands.LinkFilterSettings {
+	settings := *b.loadLinkFilter(guildID).settings
+	return &settings
+}
+
+// SaveLinkFilterSettings stores a guild's link filter settings
+func (b *Bot) SaveLinkFilterSettings(settings *commands.LinkFilterSettings) error {
+	if err := b.DB.SaveLinkFilterSettings(settings); err != nil {
+		return err
+	}
+	if b.LinkFilterCache != nil {
+		b.LinkFilterCache.Invalidate(settings.GuildID)
+	}
+	return nil
+}
+
+// GetLinkRules returns a copy of a guild's domain rules
+func (b *Bot) GetLinkRules(guildID string) []commands.LinkRule {
+	return append([]commands.LinkRule(nil), b.loadLinkFilter(guildID).rules...)
+}
+
+// SetLinkRule adds or replaces a domain rule
+func (b *Bot) SetLinkRule(rule commands.LinkRule) error {
+	if err := b.DB.SetLinkRule(rule); err != nil {
+		return err
+	}
+	if b.LinkFilterCache != nil {
+		b.LinkFilterCache.Invalidate(rule.GuildID)
+	}
+	return nil
+}
+
+// RemoveLinkRule drops a domain rule
+func (b *Bot) RemoveLinkRule(guildID, channelID, domain string) (bool, error) {
+	removed, err := b.DB.RemoveLinkRule(guildID, channelID, domain)
+	if err != nil {
+		return false, err
+	}
+	if b.LinkFilterCache != nil {
+		b.LinkFilterCache.Invalidate(guildID)
+	}
+	return removed, nil
+}
+
+// ============================================================================
+// INVITE RESOLVER - Which guild an invite code points to
+// ============================================================================
+
+type InviteResolver struct {
+	bot     *Bot
+	guilds  map[string]resolvedInvite
+	mu      sync.Mutex
+	lookups int
+}
+
+type resolvedInvite struct {
+	guildID   string // Empty for invalid or expired invites
+	expiresAt time.Time
+}
+
+func NewInviteResolver(b *Bot) *InviteResolver {
+	return &InviteResolver{bot: b, guilds: make(map[string]resolvedInvite)}
+}
+
+// Resolve returns the guild an invite belongs to. ok is false if the lookup
+// failed for a reason other than the invite not existing.
+func (ir *InviteResolver) Resolve(code string) (guildID string, ok bool) {
+	now := time.Now()
+
+	ir.mu.Lock()
+	if cached, exists := ir.guilds[code]; exists && now.Before(cached.expiresAt) {
+		ir.mu.Unlock()
+		return cached.guildID, true
+	}
+	ir.mu.Unlock()
+
+	invite, err := ir.bot.Session.Invite(code)
+	if err != nil {
+		restErr, isREST := err.(*discordgo.RESTError)
+		if !isREST || restErr.Response == nil || restErr.Response.StatusCode != http.StatusNotFound {
+			DebugLog("Failed to resolve invite %s: %v", code, err)
+			return "", false
+		}
+	} else if invite.Guild != nil {
+		guildID = invite.Guild.ID
+	}
+
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+
+	ir.lookups++
+	if ir.lookups%100 == 0 {
+		for c, cached := range ir.guilds {
+			if now.After(cached.expiresAt) {
+				delete(ir.guilds, c)
+			}
+		}
+	}
+	ir.guilds[code] = resolvedInvite{guildID: guildID, expiresAt: now.Add(inviteCacheTTL)}
+	return guildID, true
+}
+
+// ============================================================================
+// LINK CHECKS
+// ============================================================================
+
+// checkLinks applies the invite filter and domain rules
+func (sf *SpamFilter) checkLinks(m *discordgo.MessageCreate) *FilterResult {
+	lower := strings.ToLower(m.Content)
+	if !strings.Contains(lower, "discord") && !strings.Contains(lower, "://") {
+		return nil
+	}
+
+	filter := sf.bot.loadLinkFilter(m.GuildID)
+	settings := filter.settings
+	if !settings.Enabled {
+		return nil
+	}
+
+	result := sf.checkInvites(m, settings)
+	if result == nil {
+		content := inviteRegex.ReplaceAllString(m.Content, "")
+		result = sf.checkDomains(m, content, settings, filter.rules)
+	}
+	if result == nil {
+		return nil
+	}
+
+	// Moderators can post whatever links they like
+	if sf.permChecker.IsBotOwner(m.Author.ID) ||
+		sf.permChecker.HasPermission(m.GuildID, m.Author.ID, discordgo.PermissionManageMessages) {
+		return nil
+	}
+	return result
+}
+
+// checkInvites flags invites to other servers
+func (sf *SpamFilter) checkInvites(m *discordgo.MessageCreate, settings *commands.LinkFilterSettings) *FilterResult {
+	if settings.InviteAction == "off" {
+		return nil
+	}
+
+	for _, match := range inviteRegex.FindAllStringSubmatch(m.Content, 5) {
+		guildID, ok := sf.bot.InviteResolver.Resolve(match[1])
+		if !ok {
+			// Don't punish anyone for a Discord API hiccup; the invite may
+			// well be our own
+			DebugLog("Couldn't resolve invite %s from %s, letting it through", match[1], m.Author.ID)
+			continue
+		}
+		if guildID == "" || guildID == m.GuildID {
+			// Dead invites and our own are harmless
+			continue
+		}
+
+		DebugLog("Detected invite %s to guild %q from %s", match[1], guildID, m.Author.ID)
+		return &FilterResult{
+			ShouldTakeAction: true,
+			Action:           FilterAction(settings.InviteAction),
+			Reason:           "Posting invites to other servers",
+		}
+	}
+	return nil
+}
+
+// checkDomains flags links whose domain is blocked in the channel
+func (sf *SpamFilter) checkDomains(m *discordgo.MessageCreate, content string, settings *commands.LinkFilterSettings, rules []commands.LinkRule) *FilterResult {
+	links := linkRegex.FindAllString(content, 20)
+	if len(links) == 0 {
+		return nil
+	}
+
+	rules = sf.withChannelDefaults(m.GuildID, m.ChannelID, rules)
+
+	for _, link := range links {
+		domain := commands.NormaliseDomain(link)
+		if domain == "" {
+			continue
+		}
+
+		rule := commands.ResolveLinkRule(rules, m.ChannelID, domain)
+		if rule == nil || rule.Kind != commands.LinkBlock {
+			continue
+		}
+
+		DebugLog("Blocked link to %s from %s", domain, m.Author.ID)
+		return &FilterResult{
+			ShouldTakeAction: true,
+			Action:           FilterAction(settings.LinkAction),
+			Reason:           "Links to " + domain + " aren't allowed here",
+		}
+	}
+	return nil
+}
+
+// withChannelDefaults blocks links in main_* channels unless the channel has
+// its own catch-all rule
+func (sf *SpamFilter) withChannelDefaults(guildID, channelID string, rules []commands.LinkRule) []commands.LinkRule {
+	prefix := sf.bot.GetSpamFilterSettings(guildID).MainChannelPrefix
+	if prefix == "" {
+		return rules
+	}
+
+	channel, err := sf.bot.Session.State.Channel(channelID)
+	if err != nil || !strings.HasPrefix(channel.Name, prefix) {
+		return rules
+	}
+
+	for _, rule := range rules {
+		if rule.ChannelID == channelID && rule.Domain == commands.LinkAnyDomain {
+			return rules
+		}
+	}
+
+	return append(append([]commands.LinkRule(nil), rules...), commands.LinkRule{
+		ChannelID: channelID,
+		Domain:    commands.LinkAnyDomain,
+		Kind:      commands.LinkBlock,
+	})
+}
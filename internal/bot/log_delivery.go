@@ -0,0 +1,407 @@
+package bot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ============================================================================
+// LOG DELIVERY - Queues log embeds and posts them through webhooks
+// ============================================================================
+
+const (
+	logEmbedsPerMessage = 10   // Discord's limit per message
+	logCharsPerMessage  = 6000 // Discord's limit on all embeds in a message
+	maxLogBacklog       = 100  // Embeds queued per channel before new ones are dropped
+	maxLogAttempts      = 3    // Failed sends (other than rate limits) before a batch is dropped
+	maxLogBackoff       = time.Minute
+	webhookRetryAfter   = 10 * time.Minute // Wait before retrying a channel we couldn't create a webhook in
+)
+
+// LogDelivery posts log embeds for every log channel. Each channel has its
+// own queue, sent at most one message at a time and packed up to 10 embeds
+// per message; guilds with webhook delivery on post through a webhook so logs
+// don't share the bot's rate limits with command replies. Embeds that come
+// with files are sent on their own so it's clear what the files belong to.
+type LogDelivery struct {
+	bot           *Bot
+	queues        map[string]*logQueue // channelID -> queue
+	webhooks      map[string]*discordgo.Webhook
+	noWebhook     map[string]time.Time // channelID -> when to try creating a webhook again
+	mu            sync.Mutex
+	stopChan      chan struct{}
+	flushInterval time.Duration
+}
+
+type logQueue struct {
+	guildID  string
+	entries  []logEntry
+	dropped  map[string]int // Embed title -> count dropped while backlogged
+	sending  bool
+	retryAt  time.Time
+	failures int
+}
+
+type logEntry struct {
+	embed *discordgo.MessageEmbed
+	files []logFile
+}
+
+// logFile is a file to upload with a log, either generated (Data) or on
+// disk (Path). It's read when the message is sent, so retries can send it
+// again.
+type logFile struct {
+	Name        string
+	ContentType string
+	Path        string
+	Data        []byte
+}
+
+func NewLogDelivery(bot *Bot) *LogDelivery {
+	return &LogDelivery{
+		bot:           bot,
+		queues:        make(map[string]*logQueue),
+		webhooks:      make(map[string]*discordgo.Webhook),
+		noWebhook:     make(map[string]time.Time),
+		stopChan:      make(chan struct{}),
+		flushInterval: time.Second,
+	}
+}
+
+func (ld *LogDelivery) Start() {
+	go ld.run()
+}
+
+// Stop sends whatever is still queued
+func (ld *LogDelivery) Stop() {
+	close(ld.stopChan)
+
+	ld.mu.Lock()
+	channels := make([]string, 0, len(ld.queues))
+	for channelID := range ld.queues {
+		channels = append(channels, channelID)
+	}
+	ld.mu.Unlock()
+
+	for _, channelID := range channels {
+		for {
+			batch, guildID := ld.take(channelID)
+			if len(batch) == 0 {
+				break
+			}
+			if err := ld.deliver(guildID, channelID, batch); err != nil {
+				log.Printf("Failed to deliver %d log entries to %s on shutdown: %v", len(batch), channelID, err)
+				break
+			}
+		}
+	}
+}
+
+// Send queues an embed for a log channel
+func (ld *LogDelivery) Send(guildID, channelID string, embed *discordgo.MessageEmbed) {
+	ld.SendWithFiles(guildID, channelID, embed, nil)
+}
+
+// SendWithFiles queues an embed with files to upload alongside it
+func (ld *LogDelivery) SendWithFiles(guildID, channelID string, embed *discordgo.MessageEmbed, files []logFile) {
+	if channelID == "" {
+		return
+	}
+
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+
+	q, exists := ld.queues[channelID]
+	if !exists {
+		q = &logQueue{guildID: guildID}
+		ld.queues[channelID] = q
+	}
+
+	// Keep what's already queued and count the rest, so a flood of events
+	// ends in a summary instead of minutes of stale logs
+	if len(q.entries) >= maxLogBacklog {
+		if q.dropped == nil {
+			q.dropped = make(map[string]int)
+		}
+		q.dropped[embed.Title]++
+		return
+	}
+	q.entries = append(q.entries, logEntry{embed: embed, files: files})
+}
+
+func (ld *LogDelivery) run() {
+	ticker := time.NewTicker(ld.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ld.stopChan:
+			return
+		case <-ticker.C:
+			ld.flush()
+		}
+	}
+}
+
+// flush starts a send for every channel that's ready
+func (ld *LogDelivery) flush() {
+	ld.mu.Lock()
+	now := time.Now()
+	var ready []string
+	for channelID, q := range ld.queues {
+		if q.sending || now.Before(q.retryAt) {
+			continue
+		}
+		if len(q.entries) == 0 && len(q.dropped) == 0 {
+			delete(ld.queues, channelID)
+			continue
+		}
+		q.sending = true
+		ready = append(ready, channelID)
+	}
+	ld.mu.Unlock()
+
+	for _, channelID := range ready {
+		go ld.sendNext(channelID)
+	}
+}
+
+// take removes the next message's worth of entries from a queue
+func (ld *LogDelivery) take(channelID string) ([]logEntry, string) {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+
+	q, exists := ld.queues[channelID]
+	if !exists {
+		return nil, ""
+	}
+
+	// Once the backlog is gone, say what was dropped
+	if len(q.entries) == 0 && len(q.dropped) > 0 {
+		q.entries = append(q.entries, logEntry{embed: droppedLogsEmbed(q.dropped)})
+		q.dropped = nil
+	}
+
+	n, chars := 0, 0
+	for n < len(q.entries) && n < logEmbedsPerMessage {
+		if len(q.entries[n].files) > 0 {
+			if n == 0 {
+				n = 1
+			}
+			break
+		}
+		size := embedLength(q.entries[n].embed)
+		if n > 0 && chars+size > logCharsPerMessage {
+			break
+		}
+		chars += size
+		n++
+	}
+
+	batch := q.entries[:n:n]
+	q.entries = q.entries[n:]
+	return batch, q.guildID
+}
+
+// sendNext sends one message from a queue, putting the entries back if
+// Discord rate limits it
+func (ld *LogDelivery) sendNext(channelID string) {
+	defer RecoverFromPanic("LogDelivery.sendNext")
+
+	batch, guildID := ld.take(channelID)
+	var err error
+	if len(batch) > 0 {
+		err = ld.deliver(guildID, channelID, batch)
+	}
+
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+
+	q, exists := ld.queues[channelID]
+	if !exists {
+		return
+	}
+	q.sending = false
+	if err == nil {
+		q.failures = 0
+		return
+	}
+
+	var rateLimit *discordgo.RateLimitError
+	if errors.As(err, &rateLimit) {
+		// Rate limits don't count as failures; wait as long as Discord asks
+		wait := rateLimit.RetryAfter
+		if backoff := logBackoff(q.failures + 1); wait < backoff {
+			wait = backoff
+		}
+		q.retryAt = time.Now().Add(wait)
+		q.entries = append(batch, q.entries...)
+		DebugLog("Log delivery to %s rate limited, retrying in %s", channelID, wait)
+		return
+	}
+
+	q.failures++
+	if q.failures >= maxLogAttempts {
+		log.Printf("Dropping %d log entries for %s after %d failed attempts: %v", len(batch), channelID, q.failures, err)
+		q.failures = 0
+		return
+	}
+	q.retryAt = time.Now().Add(logBackoff(q.failures))
+	q.entries = append(batch, q.entries...)
+	DebugLog("Log delivery to %s failed (attempt %d): %v", channelID, q.failures, err)
+}
+
+// deliver posts entries through the channel's webhook if the guild uses them,
+// otherwise as the bot
+func (ld *LogDelivery) deliver(guildID, channelID string, batch []logEntry) error {
+	embeds := make([]*discordgo.MessageEmbed, len(batch))
+	var files []*discordgo.File
+	for i, entry := range batch {
+		embeds[i] = entry.embed
+		for _, f := range entry.files {
+			if f.Data != nil {
+				files = append(files, &discordgo.File{Name: f.Name, ContentType: f.ContentType, Reader: bytes.NewReader(f.Data)})
+				continue
+			}
+
+			file, err := os.Open(f.Path)
+			if err != nil {
+				// Cleaned up since it was queued; the embed still says what it was
+				DebugLog("Skipping log file %s: %v", f.Path, err)
+				continue
+			}
+			defer file.Close()
+			files = append(files, &discordgo.File{Name: f.Name, ContentType: f.ContentType, Reader: file})
+		}
+	}
+
+	config, err := ld.bot.GetLoggingConfigCached(guildID)
+	if err == nil && config.UseWebhooks {
+		if webhook := ld.webhookFor(guildID, channelID); webhook != nil {
+			params := &discordgo.WebhookParams{Embeds: embeds, Files: files}
+			if user := ld.bot.Session.State.User; user != nil {
+				params.Username = user.Username
+				params.AvatarURL = user.AvatarURL("")
+			}
+
+			_, err := ld.bot.Session.WebhookExecute(webhook.ID, webhook.Token, false, params, discordgo.WithRetryOnRatelimit(false))
+			var restErr *discordgo.RESTError
+			if errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == discordgo.ErrCodeUnknownWebhook {
+				// Someone deleted it; make a new one on the retry
+				ld.forgetWebhook(channelID)
+			}
+			return err
+		}
+	}
+
+	_, err = ld.bot.Session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Embeds: embeds, Files: files}, discordgo.WithRetryOnRatelimit(false))
+	return err
+}
+
+// webhookFor returns the channel's log webhook, creating one if needed, or
+// nil if the bot can't
+func (ld *LogDelivery) webhookFor(guildID, channelID string) *discordgo.Webhook {
+	ld.mu.Lock()
+	webhook, cached := ld.webhooks[channelID]
+	retryAt := ld.noWebhook[channelID]
+	ld.mu.Unlock()
+
+	if cached {
+		return webhook
+	}
+	if time.Now().Before(retryAt) {
+		return nil
+	}
+
+	id, token, err := ld.bot.DB.GetLogWebhook(channelID)
+	if err == nil {
+		webhook = &discordgo.Webhook{ID: id, Token: token, ChannelID: channelID}
+	} else {
+		name := "Logs"
+		if user := ld.bot.Session.State.User; user != nil {
+			name = user.Username + " Logs"
+		}
+
+		webhook, err = ld.bot.Session.WebhookCreate(channelID, name, "")
+		if err != nil {
+			log.Printf("Couldn't create a log webhook in %s, posting as the bot: %v", channelID, err)
+			ld.mu.Lock()
+			ld.noWebhook[channelID] = time.Now().Add(webhookRetryAfter)
+			ld.mu.Unlock()
+			return nil
+		}
+		if err := ld.bot.DB.SaveLogWebhook(guildID, channelID, webhook.ID, webhook.Token); err != nil {
+			log.Printf("Failed to save log webhook for %s: %v", channelID, err)
+		}
+		DebugLog("Created log webhook %s in %s", webhook.ID, channelID)
+	}
+
+	ld.mu.Lock()
+	ld.webhooks[channelID] = webhook
+	ld.mu.Unlock()
+	return webhook
+}
+
+func (ld *LogDelivery) forgetWebhook(channelID string) {
+	ld.mu.Lock()
+	delete(ld.webhooks, channelID)
+	ld.mu.Unlock()
+
+	if err := ld.bot.DB.DeleteLogWebhook(channelID); err != nil {
+		log.Printf("Failed to delete log webhook for %s: %v", channelID, err)
+	}
+}
+
+// logBackoff doubles from 2s per failed attempt, up to a minute
+func logBackoff(attempt int) time.Duration {
+	backoff := time.Second << attempt
+	if backoff <= 0 || backoff > maxLogBackoff {
+		return maxLogBackoff
+	}
+	return backoff
+}
+
+// droppedLogsEmbed summarises the embeds dropped during a backlog
+func droppedLogsEmbed(dropped map[string]int) *discordgo.MessageEmbed {
+	total := 0
+	lines := make([]string, 0, len(dropped))
+	for title, count := range dropped {
+		total += count
+		if title == "" {
+			title = "Untitled"
+		}
+		lines = append(lines, fmt.Sprintf("**%d** × %s", count, title))
+	}
+	sort.Strings(lines)
+
+	return &discordgo.MessageEmbed{
+		Title:       "⚠️ Logs Dropped",
+		Description: fmt.Sprintf("Too many events to keep up with; **%d** log entries were skipped:\n%s", total, truncateLogField(strings.Join(lines, "\n"))),
+		Color:       0xFFAA00,
+		Timestamp:   time.Now().Format(time.RFC3339),
+	}
+}
+
+// embedLength counts the characters Discord limits across a message's embeds
+func embedLength(embed *discordgo.MessageEmbed) int {
+	n := len(embed.Title) + len(embed.Description)
+	for _, field := range embed.Fields {
+		n += len(field.Name) + len(field.Value)
+	}
+	if embed.Footer != nil {
+		n += len(embed.Footer.Text)
+	}
+	if embed.Author != nil {
+		n += len(embed.Author.Name)
+	}
+	return n
+}
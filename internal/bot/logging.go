@@ -2,6 +2,7 @@ package bot
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -22,8 +23,76 @@ type LoggingConfig struct {
 	NicknameChange       bool
 	AvatarChange         bool
 	PresenceChange       bool
+	MemberJoin           bool
+	MemberLeave          bool
+	MemberBan            bool
+	MemberUnban          bool
+	MemberRoleChange     bool
+	RoleChange           bool
+	ChannelChange        bool
+	InviteChange         bool
+	EmojiChange          bool
+	GuildUpdate          bool
 	PresenceBatchSeconds int
 	Enabled              bool
+	UseWebhooks          bool              // Post through a webhook per log channel instead of as the bot
+	ArchiveAttachments   bool              // Keep copies of attachments to re-upload when messages are deleted
+	TypeChannels         map[string]string // Log type -> its own channel, instead of LogChannelID
+}
+
+// ChannelFor returns where a log type goes: its own channel if it has one,
+// otherwise the default log channel
+func (c *LoggingConfig) ChannelFor(logType string) string {
+	if channelID := c.TypeChannels[logType]; channelID != "" {
+		return channelID
+	}
+	return c.LogChannelID
+}
+
+// HasLogChannel reports whether anything has somewhere to go
+func (c *LoggingConfig) HasLogChannel() bool {
+	return c.LogChannelID != "" || len(c.TypeChannels) > 0
+}
+
+// Logs reports whether the guild logs the given type (a logging_config column)
+func (c *LoggingConfig) Logs(logType string) bool {
+	switch logType {
+	case "message_delete":
+		return c.MessageDelete
+	case "message_edit":
+		return c.MessageEdit
+	case "member_join_voice":
+		return c.MemberJoinVoice
+	case "member_leave_voice":
+		return c.MemberLeaveVoice
+	case "nickname_change":
+		return c.NicknameChange
+	case "avatar_change":
+		return c.AvatarChange
+	case "presence_change":
+		return c.PresenceChange
+	case "member_join":
+		return c.MemberJoin
+	case "member_leave":
+		return c.MemberLeave
+	case "member_ban":
+		return c.MemberBan
+	case "member_unban":
+		return c.MemberUnban
+	case "member_role_change":
+		return c.MemberRoleChange
+	case "role_change":
+		return c.RoleChange
+	case "channel_change":
+		return c.ChannelChange
+	case "invite_change":
+		return c.InviteChange
+	case "emoji_change":
+		return c.EmojiChange
+	case "guild_update":
+		return c.GuildUpdate
+	}
+	return false
 }
 
 // ============================================================================
@@ -95,12 +164,61 @@ type MessageCacheBatcher struct {
 }
 
 type cachedMessage struct {
-	ID        string
-	GuildID   string
-	ChannelID string
-	AuthorID  string
-	Content   string
-	CreatedAt string
+	ID          string
+	GuildID     string
+	ChannelID   string
+	AuthorID    string
+	AuthorTag   string
+	Content     string
+	Attachments string // JSON, so deletion logs can show what was attached
+	Embeds      string // JSON
+	Stickers    string // JSON
+	Reference   string // JSON of the message this one replied to
+	CreatedAt   string
+}
+
+const cacheMessageQuery = `
+	INSERT OR REPLACE INTO message_cache
+	(message_id, guild_id, channel_id, author_id, author_tag, content,
+	 attachments, embeds, stickers, reference, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+func newCachedMessage(m *discordgo.Message) cachedMessage {
+	cached := cachedMessage{
+		ID:          m.ID,
+		GuildID:     m.GuildID,
+		ChannelID:   m.ChannelID,
+		AuthorID:    m.Author.ID,
+		AuthorTag:   m.Author.String(),
+		Content:     m.Content,
+		Attachments: marshalCacheField(m.Attachments, len(m.Attachments)),
+		Embeds:      marshalCacheField(m.Embeds, len(m.Embeds)),
+		Stickers:    marshalCacheField(m.StickerItems, len(m.StickerItems)),
+		CreatedAt:   m.Timestamp.Format(time.RFC3339),
+	}
+	if m.MessageReference != nil {
+		cached.Reference = marshalCacheField(m.MessageReference, 1)
+	}
+	return cached
+}
+
+func (c cachedMessage) args() []interface{} {
+	return []interface{}{
+		c.ID, c.GuildID, c.ChannelID, c.AuthorID, c.AuthorTag, c.Content,
+		c.Attachments, c.Embeds, c.Stickers, c.Reference, c.CreatedAt,
+	}
+}
+
+// marshalCacheField stores empty lists as "" so most rows stay small
+func marshalCacheField(v interface{}, n int) string {
+	if n == 0 {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
 }
 
 func NewMessageCacheBatcher(bot *Bot) *MessageCacheBatcher {
@@ -128,14 +246,7 @@ func (mcb *MessageCacheBatcher) Add(m *discordgo.Message) {
 	}
 
 	mcb.mu.Lock()
-	mcb.messages = append(mcb.messages, cachedMessage{
-		ID:        m.ID,
-		GuildID:   m.GuildID,
-		ChannelID: m.ChannelID,
-		AuthorID:  m.Author.ID,
-		Content:   m.Content,
-		CreatedAt: m.Timestamp.Format(time.RFC3339),
-	})
+	mcb.messages = append(mcb.messages, newCachedMessage(m))
 
 	// Flush immediately if batch is full
 	if len(mcb.messages) >= mcb.maxBatchSize {
@@ -181,10 +292,7 @@ func (mcb *MessageCacheBatcher) flushLocked() {
 			return
 		}
 
-		stmt, err := tx.Prepare(`
-			INSERT OR REPLACE INTO message_cache
-			(message_id, guild_id, channel_id, author_id, content, created_at)
-			VALUES (?, ?, ?, ?, ?, ?)`)
+		stmt, err := tx.Prepare(cacheMessageQuery)
 		if err != nil {
 			tx.Rollback()
 			log.Printf("Failed to prepare message cache statement: %v", err)
@@ -193,7 +301,7 @@ func (mcb *MessageCacheBatcher) flushLocked() {
 		defer stmt.Close()
 
 		for _, m := range messages {
-			_, err := stmt.Exec(m.ID, m.GuildID, m.ChannelID, m.AuthorID, m.Content, m.CreatedAt)
+			_, err := stmt.Exec(m.args()...)
 			if err != nil {
 				log.Printf("Failed to cache message %s: %v", m.ID, err)
 			}
@@ -276,7 +384,7 @@ func (elb *EventLogBatcher) flushAll() {
 		}
 
 		config, err := elb.bot.GetLoggingConfigCached(guildID)
-		if err != nil || !config.Enabled || config.LogChannelID == "" {
+		if err != nil || !config.Enabled || !config.HasLogChannel() {
 			delete(elb.events, guildID)
 			continue
 		}
@@ -315,27 +423,27 @@ func (elb *EventLogBatcher) sendBatchedEvents(guildID string, config *LoggingCon
 	}
 
 	// Send batched voice joins
-	if len(voiceJoins) > 0 {
-		elb.sendVoiceBatch(config.LogChannelID, "Members Joined Voice", 0x2ecc71, voiceJoins)
+	if channelID := config.ChannelFor("member_join_voice"); len(voiceJoins) > 0 && channelID != "" {
+		elb.sendVoiceBatch(guildID, channelID, "Members Joined Voice", 0x2ecc71, voiceJoins)
 	}
 
 	// Send batched voice leaves
-	if len(voiceLeaves) > 0 {
-		elb.sendVoiceBatch(config.LogChannelID, "Members Left Voice", 0xe74c3c, voiceLeaves)
+	if channelID := config.ChannelFor("member_leave_voice"); len(voiceLeaves) > 0 && channelID != "" {
+		elb.sendVoiceBatch(guildID, channelID, "Members Left Voice", 0xe74c3c, voiceLeaves)
 	}
 
 	// Send batched nickname changes
-	if len(nickChanges) > 0 {
-		elb.sendNicknameBatch(config.LogChannelID, nickChanges)
+	if channelID := config.ChannelFor("nickname_change"); len(nickChanges) > 0 && channelID != "" {
+		elb.sendNicknameBatch(guildID, channelID, nickChanges)
 	}
 
 	// Send batched avatar changes
-	if len(avatarChanges) > 0 {
-		elb.sendAvatarBatch(config.LogChannelID, avatarChanges)
+	if channelID := config.ChannelFor("avatar_change"); len(avatarChanges) > 0 && channelID != "" {
+		elb.sendAvatarBatch(guildID, channelID, avatarChanges)
 	}
 }
 
-func (elb *EventLogBatcher) sendVoiceBatch(channelID, title string, color int, events []LogEvent) {
+func (elb *EventLogBatcher) sendVoiceBatch(guildID, channelID, title string, color int, events []LogEvent) {
 	// Group by channel
 	channelMap := make(map[string][]string)
 	for _, e := range events {
@@ -366,13 +474,10 @@ func (elb *EventLogBatcher) sendVoiceBatch(channelID, title string, color int, e
 		Timestamp:   time.Now().Format(time.RFC3339),
 	}
 
-	_, err := elb.bot.Session.ChannelMessageSendEmbed(channelID, embed)
-	if err != nil {
-		log.Printf("Failed to send batched voice events: %v", err)
-	}
+	elb.bot.LogDelivery.Send(guildID, channelID, embed)
 }
 
-func (elb *EventLogBatcher) sendNicknameBatch(channelID string, events []LogEvent) {
+func (elb *EventLogBatcher) sendNicknameBatch(guildID, channelID string, events []LogEvent) {
 	var fields []*discordgo.MessageEmbedField
 
 	for i, e := range events {
@@ -409,13 +514,10 @@ func (elb *EventLogBatcher) sendNicknameBatch(channelID string, events []LogEven
 		Timestamp:   time.Now().Format(time.RFC3339),
 	}
 
-	_, err := elb.bot.Session.ChannelMessageSendEmbed(channelID, embed)
-	if err != nil {
-		log.Printf("Failed to send batched nickname changes: %v", err)
-	}
+	elb.bot.LogDelivery.Send(guildID, channelID, embed)
 }
 
-func (elb *EventLogBatcher) sendAvatarBatch(channelID string, events []LogEvent) {
+func (elb *EventLogBatcher) sendAvatarBatch(guildID, channelID string, events []LogEvent) {
 	var fields []*discordgo.MessageEmbedField
 
 	for i, e := range events {
@@ -448,10 +550,7 @@ func (elb *EventLogBatcher) sendAvatarBatch(channelID string, events []LogEvent)
 		Timestamp:   time.Now().Format(time.RFC3339),
 	}
 
-	_, err := elb.bot.Session.ChannelMessageSendEmbed(channelID, embed)
-	if err != nil {
-		log.Printf("Failed to send batched avatar changes: %v", err)
-	}
+	elb.bot.LogDelivery.Send(guildID, channelID, embed)
 }
 
 // PresenceBatcher batches presence changes to avoid spam
@@ -534,13 +633,13 @@ func (pb *PresenceBatcher) flushAll() {
 
 		// Get logging config
 		config, err := pb.bot.GetLoggingConfig(guildID)
-		if err != nil || !config.Enabled || !config.PresenceChange || config.LogChannelID == "" {
+		if err != nil || !config.Enabled || !config.PresenceChange || config.ChannelFor("presence_change") == "" {
 			delete(pb.changes, guildID)
 			continue
 		}
 
 		// Build the message
-		pb.sendBatchedPresences(guildID, config.LogChannelID, changes)
+		pb.sendBatchedPresences(guildID, config.ChannelFor("presence_change"), changes)
 
 		// Clear the changes for this guild
 		delete(pb.changes, guildID)
@@ -595,32 +694,36 @@ func (pb *PresenceBatcher) sendBatchedPresences(guildID, logChannelID string, ch
 		Timestamp:   time.Now().Format(time.RFC3339),
 	}
 
-	_, err := pb.bot.Session.ChannelMessageSendEmbed(logChannelID, embed)
-	if err != nil {
-		log.Printf("Failed to send batched presence changes: %v", err)
-	}
+	pb.bot.LogDelivery.Send(guildID, logChannelID, embed)
 }
 
 // GetLoggingConfig gets the logging configuration for a guild (direct DB access)
 func (b *Bot) GetLoggingConfig(guildID string) (*LoggingConfig, error) {
 	var config LoggingConfig
 	var (
-		msgDel, msgEdit, joinVoice, leaveVoice, nick, avatar, presence, enabled int
+		msgDel, msgEdit, joinVoice, leaveVoice, nick, avatar, presence, enabled, webhooks, archive int
+		join, leave, ban, unban, memberRoles, roles, channels, invites, emojis, guild              int
 	)
 
 	err := b.DB.QueryRow(`
-		SELECT log_channel_id, message_delete, message_edit, member_join_voice,
+		SELECT COALESCE(log_channel_id, ''), message_delete, message_edit, member_join_voice,
 		       member_leave_voice, nickname_change, avatar_change, presence_change,
-		       presence_batch_seconds, enabled
+		       member_join, member_leave, member_ban, member_unban, member_role_change,
+		       role_change, channel_change, invite_change, emoji_change, guild_update,
+		       presence_batch_seconds, enabled, use_webhooks, archive_attachments
 		FROM logging_config WHERE guild_id = ?`, guildID).Scan(
 		&config.LogChannelID, &msgDel, &msgEdit, &joinVoice, &leaveVoice,
-		&nick, &avatar, &presence, &config.PresenceBatchSeconds, &enabled,
+		&nick, &avatar, &presence,
+		&join, &leave, &ban, &unban, &memberRoles,
+		&roles, &channels, &invites, &emojis, &guild,
+		&config.PresenceBatchSeconds, &enabled, &webhooks, &archive,
 	)
 
 	if err == sql.ErrNoRows {
 		// Return default config
 		return &LoggingConfig{
 			GuildID:              guildID,
+			TypeChannels:         map[string]string{},
 			MessageDelete:        true,
 			MessageEdit:          true,
 			MemberJoinVoice:      true,
@@ -628,6 +731,16 @@ func (b *Bot) GetLoggingConfig(guildID string) (*LoggingConfig, error) {
 			NicknameChange:       true,
 			AvatarChange:         true,
 			PresenceChange:       true,
+			MemberJoin:           true,
+			MemberLeave:          true,
+			MemberBan:            true,
+			MemberUnban:          true,
+			MemberRoleChange:     true,
+			RoleChange:           true,
+			ChannelChange:        true,
+			InviteChange:         true,
+			EmojiChange:          true,
+			GuildUpdate:          true,
 			PresenceBatchSeconds: 120,
 			Enabled:              false,
 		}, nil
@@ -643,11 +756,48 @@ func (b *Bot) GetLoggingConfig(guildID string) (*LoggingConfig, error) {
 	config.NicknameChange = nick == 1
 	config.AvatarChange = avatar == 1
 	config.PresenceChange = presence == 1
+	config.MemberJoin = join == 1
+	config.MemberLeave = leave == 1
+	config.MemberBan = ban == 1
+	config.MemberUnban = unban == 1
+	config.MemberRoleChange = memberRoles == 1
+	config.RoleChange = roles == 1
+	config.ChannelChange = channels == 1
+	config.InviteChange = invites == 1
+	config.EmojiChange = emojis == 1
+	config.GuildUpdate = guild == 1
 	config.Enabled = enabled == 1
+	config.UseWebhooks = webhooks == 1
+	config.ArchiveAttachments = archive == 1
+
+	config.TypeChannels, err = b.getLogTypeChannels(guildID)
+	if err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
 
+// getLogTypeChannels loads the log types that go somewhere other than the
+// default log channel
+func (b *Bot) getLogTypeChannels(guildID string) (map[string]string, error) {
+	rows, err := b.DB.Query(`SELECT log_type, channel_id FROM logging_type_channels WHERE guild_id = ?`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := make(map[string]string)
+	for rows.Next() {
+		var logType, channelID string
+		if err := rows.Scan(&logType, &channelID); err != nil {
+			return nil, err
+		}
+		channels[logType] = channelID
+	}
+	return channels, rows.Err()
+}
+
 // GetLoggingConfigCached gets the logging configuration with caching
 func (b *Bot) GetLoggingConfigCached(guildID string) (*LoggingConfig, error) {
 	// Check cache first
@@ -678,6 +828,25 @@ func (b *Bot) InvalidateLoggingConfigCache(guildID string) {
 	}
 }
 
+// loggingStore gives the logging commands SQL access to the logging tables
+type loggingStore struct {
+	bot *Bot
+}
+
+func (ls loggingStore) QueryRow(query string, args ...interface{}) interface {
+	Scan(dest ...interface{}) error
+} {
+	return ls.bot.DB.QueryRow(query, args...)
+}
+
+func (ls loggingStore) Exec(query string, args ...interface{}) (interface{}, error) {
+	return ls.bot.DB.Exec(query, args...)
+}
+
+func (ls loggingStore) InvalidateLoggingConfigCache(guildID string) {
+	ls.bot.InvalidateLoggingConfigCache(guildID)
+}
+
 // IsChannelLoggingEnabled checks if a specific logging type is enabled for a channel
 func (b *Bot) IsChannelLoggingEnabled(guildID, channelID, logType string) (bool, error) {
 	// First check if there's a channel override
@@ -696,32 +865,19 @@ func (b *Bot) IsChannelLoggingEnabled(guildID, channelID, logType string) (bool,
 		return false, err
 	}
 
-	switch logType {
-	case "message_delete":
-		return config.MessageDelete, nil
-	case "message_edit":
-		return config.MessageEdit, nil
-	case "member_join_voice":
-		return config.MemberJoinVoice, nil
-	case "member_leave_voice":
-		return config.MemberLeaveVoice, nil
-	case "nickname_change":
-		return config.NicknameChange, nil
-	case "avatar_change":
-		return config.AvatarChange, nil
-	case "presence_change":
-		return config.PresenceChange, nil
-	}
-
-	return false, nil
+	return config.Logs(logType), nil
 }
 
 // CacheMessage caches a message for potential logging (uses batcher for efficiency)
 func (b *Bot) CacheMessage(m *discordgo.Message) {
-	if m.GuildID == "" {
+	if m.GuildID == "" || m.Author == nil {
 		return
 	}
 
+	if len(m.Attachments) > 0 {
+		b.archiveAttachments(m)
+	}
+
 	// Use the batcher for efficient batched writes
 	if b.MessageCacheBatcher != nil {
 		b.MessageCacheBatcher.Add(m)
@@ -729,41 +885,98 @@ func (b *Bot) CacheMessage(m *discordgo.Message) {
 	}
 
 	// Fallback to direct write if batcher not available
-	_, err := b.DB.Exec(`
-		INSERT OR REPLACE INTO message_cache (message_id, guild_id, channel_id, author_id, content, created_at)
-		VALUES (?, ?, ?, ?, ?, ?)`,
-		m.ID, m.GuildID, m.ChannelID, m.Author.ID, m.Content, m.Timestamp.Format(time.RFC3339),
-	)
+	_, err := b.DB.Exec(cacheMessageQuery, newCachedMessage(m).args()...)
 
 	if err != nil {
 		log.Printf("Failed to cache message: %v", err)
 	}
 }
 
-// GetCachedMessage retrieves a cached message
+const cachedMessageColumns = `
+	message_id, guild_id, channel_id, author_id, COALESCE(author_tag, ''), content,
+	COALESCE(attachments, ''), COALESCE(embeds, ''), COALESCE(stickers, ''),
+	COALESCE(reference, ''), created_at`
+
+// GetCachedMessage retrieves a cached message. The author only has an ID and
+// the tag they had when the message was sent.
 func (b *Bot) GetCachedMessage(messageID string) (*discordgo.Message, error) {
-	var m discordgo.Message
-	var createdAt string
+	return scanCachedMessage(b.DB.QueryRow(`
+		SELECT `+cachedMessageColumns+`
+		FROM message_cache WHERE message_id = ?`, messageID))
+}
 
-	err := b.DB.QueryRow(`
-		SELECT message_id, guild_id, channel_id, author_id, content, created_at
-		FROM message_cache WHERE message_id = ?`, messageID).Scan(
-		&m.ID, &m.GuildID, &m.ChannelID, &m.Author.ID, &m.Content, &createdAt,
+// GetCachedMessages retrieves whichever of the messages are cached, oldest
+// first
+func (b *Bot) GetCachedMessages(messageIDs []string) ([]*discordgo.Message, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(messageIDs)), ", ")
+	args := make([]interface{}, len(messageIDs))
+	for i, id := range messageIDs {
+		args[i] = id
+	}
+
+	rows, err := b.DB.Query(`
+		SELECT `+cachedMessageColumns+`
+		FROM message_cache WHERE message_id IN (`+placeholders+`)
+		ORDER BY created_at, length(message_id), message_id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*discordgo.Message
+	for rows.Next() {
+		m, err := scanCachedMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+func scanCachedMessage(row rowScanner) (*discordgo.Message, error) {
+	m := discordgo.Message{Author: &discordgo.User{Discriminator: "0"}}
+	var createdAt, attachments, embeds, stickers, reference string
+
+	err := row.Scan(
+		&m.ID, &m.GuildID, &m.ChannelID, &m.Author.ID, &m.Author.Username, &m.Content,
+		&attachments, &embeds, &stickers, &reference, &createdAt,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
+	// Rows cached before these columns existed just leave them empty
+	if attachments != "" {
+		json.Unmarshal([]byte(attachments), &m.Attachments)
+	}
+	if embeds != "" {
+		json.Unmarshal([]byte(embeds), &m.Embeds)
+	}
+	if stickers != "" {
+		json.Unmarshal([]byte(stickers), &m.StickerItems)
+	}
+	if reference != "" {
+		json.Unmarshal([]byte(reference), &m.MessageReference)
+	}
+
 	m.Timestamp, _ = time.Parse(time.RFC3339, createdAt)
 	return &m, nil
 }
 
 // CleanOldMessageCache cleans up old messages from the cache (older than 7 days)
+// along with their archived attachments
 func (b *Bot) CleanOldMessageCache() {
-	cutoff := time.Now().AddDate(0, 0, -7).Format(time.RFC3339)
-	_, err := b.DB.Exec("DELETE FROM message_cache WHERE created_at < ?", cutoff)
+	cutoff := time.Now().AddDate(0, 0, -7)
+	_, err := b.DB.Exec("DELETE FROM message_cache WHERE created_at < ?", cutoff.Format(time.RFC3339))
 	if err != nil {
 		log.Printf("Failed to clean message cache: %v", err)
 	}
+
+	b.cleanAttachmentArchive(cutoff)
 }
@@ -2,7 +2,7 @@ package bot
 
 import (
 	"fmt"
-	"log"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
@@ -16,7 +16,7 @@ func (b *Bot) onMessageDelete(s *discordgo.Session, m *discordgo.MessageDelete)
 
 	// Use cached config for high-frequency events
 	config, err := b.GetLoggingConfigCached(m.GuildID)
-	if err != nil || !config.Enabled || !config.MessageDelete || config.LogChannelID == "" {
+	if err != nil || !config.Enabled || !config.MessageDelete || config.ChannelFor("message_delete") == "" {
 		return
 	}
 
@@ -47,13 +47,27 @@ func (b *Bot) onMessageDelete(s *discordgo.Session, m *discordgo.MessageDelete)
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
 
-	if cached != nil && cached.Author != nil {
+	var files []logFile
+	if cached != nil {
+		author := fmt.Sprintf("<@%s>", cached.Author.ID)
+		if cached.Author.Username != "" {
+			// Still readable if they've left and the mention won't resolve
+			author += fmt.Sprintf(" (%s)", cached.Author.String())
+		}
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:   "Author",
-			Value:  fmt.Sprintf("<@%s>", cached.Author.ID),
+			Value:  author,
 			Inline: true,
 		})
 
+		if ref := cached.MessageReference; ref != nil && ref.MessageID != "" {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   "Reply To",
+				Value:  b.describeReply(m.GuildID, ref),
+				Inline: true,
+			})
+		}
+
 		if cached.Content != "" {
 			content := cached.Content
 			if len(content) > 1024 {
@@ -66,22 +80,108 @@ func (b *Bot) onMessageDelete(s *discordgo.Session, m *discordgo.MessageDelete)
 			})
 		}
 
-		if len(m.Attachments) > 0 {
-			attachmentURLs := ""
-			for _, att := range m.Attachments {
-				attachmentURLs += att.URL + "\n"
-			}
+		if len(cached.Attachments) > 0 {
+			files = archivedFiles(m.GuildID, m.ID, cached.Attachments)
 			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 				Name:   "Attachments",
-				Value:  attachmentURLs,
+				Value:  describeAttachments(cached.Attachments, files),
+				Inline: false,
+			})
+
+			// Show the first re-uploaded image in the log itself
+			for _, f := range files {
+				if strings.HasPrefix(f.ContentType, "image/") {
+					embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + f.Name}
+					break
+				}
+			}
+		}
+
+		if len(cached.StickerItems) > 0 {
+			names := make([]string, len(cached.StickerItems))
+			for i, sticker := range cached.StickerItems {
+				names[i] = sticker.Name
+			}
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   "Stickers",
+				Value:  truncateLogField(strings.Join(names, ", ")),
+				Inline: false,
+			})
+		}
+
+		if len(cached.Embeds) > 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   "Embeds",
+				Value:  describeEmbeds(cached.Embeds),
 				Inline: false,
 			})
 		}
 	}
 
-	_, err = s.ChannelMessageSendEmbed(config.LogChannelID, embed)
-	if err != nil {
-		log.Printf("Failed to log message deletion: %v", err)
+	b.LogDelivery.SendWithFiles(m.GuildID, config.ChannelFor("message_delete"), embed, files)
+}
+
+// describeReply links the message a deleted message replied to, naming its
+// author if it's cached
+func (b *Bot) describeReply(guildID string, ref *discordgo.MessageReference) string {
+	channelID := ref.ChannelID
+	if channelID == "" {
+		channelID = "@me"
+	}
+	link := fmt.Sprintf("[Jump to Message](https://discord.com/channels/%s/%s/%s)", guildID, channelID, ref.MessageID)
+
+	if replied, err := b.GetCachedMessage(ref.MessageID); err == nil {
+		return fmt.Sprintf("<@%s> • %s", replied.Author.ID, link)
+	}
+	return link
+}
+
+// describeAttachments lists a deleted message's attachments, marking the
+// ones re-uploaded from the archive
+func describeAttachments(attachments []*discordgo.MessageAttachment, archived []logFile) string {
+	uploaded := make(map[string]bool, len(archived))
+	for _, f := range archived {
+		uploaded[f.Name] = true
+	}
+
+	lines := make([]string, len(attachments))
+	for i, att := range attachments {
+		line := fmt.Sprintf("%s (%s)", att.Filename, formatFileSize(att.Size))
+		if uploaded[att.Filename] {
+			line += " • re-uploaded"
+		}
+		lines[i] = line
+	}
+	return truncateLogField(strings.Join(lines, "\n"))
+}
+
+func describeEmbeds(embeds []*discordgo.MessageEmbed) string {
+	lines := make([]string, len(embeds))
+	for i, e := range embeds {
+		switch {
+		case e.Title != "":
+			lines[i] = e.Title
+		case e.URL != "":
+			lines[i] = e.URL
+		case len(e.Description) > 100:
+			lines[i] = e.Description[:97] + "..."
+		case e.Description != "":
+			lines[i] = e.Description
+		default:
+			lines[i] = "Untitled embed"
+		}
+	}
+	return truncateLogField(strings.Join(lines, "\n"))
+}
+
+func formatFileSize(bytes int) string {
+	switch {
+	case bytes >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1<<20))
+	case bytes >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", bytes)
 	}
 }
 
@@ -93,7 +193,7 @@ func (b *Bot) onMessageUpdate(s *discordgo.Session, m *discordgo.MessageUpdate)
 
 	// Use cached config for high-frequency events
 	config, err := b.GetLoggingConfigCached(m.GuildID)
-	if err != nil || !config.Enabled || !config.MessageEdit || config.LogChannelID == "" {
+	if err != nil || !config.Enabled || !config.MessageEdit || config.ChannelFor("message_edit") == "" {
 		return
 	}
 
@@ -109,8 +209,12 @@ func (b *Bot) onMessageUpdate(s *discordgo.Session, m *discordgo.MessageUpdate)
 		return
 	}
 
-	// If content is the same, it's not an edit (probably embed update)
+	// If content is the same, it's not an edit (probably embed update), but
+	// keep link previews cached for deletion logs
 	if cached.Content == m.Content {
+		if len(m.Embeds) != len(cached.Embeds) {
+			b.CacheMessage(m.Message)
+		}
 		return
 	}
 
@@ -162,10 +266,7 @@ func (b *Bot) onMessageUpdate(s *discordgo.Session, m *discordgo.MessageUpdate)
 		})
 	}
 
-	_, err = s.ChannelMessageSendEmbed(config.LogChannelID, embed)
-	if err != nil {
-		log.Printf("Failed to log message edit: %v", err)
-	}
+	b.LogDelivery.Send(m.GuildID, config.ChannelFor("message_edit"), embed)
 
 	// Update cache with new content
 	b.CacheMessage(m.Message)
@@ -179,7 +280,7 @@ func (b *Bot) onVoiceStateUpdateLogging(s *discordgo.Session, v *discordgo.Voice
 
 	// Use cached config for high-frequency events
 	config, err := b.GetLoggingConfigCached(v.GuildID)
-	if err != nil || !config.Enabled || config.LogChannelID == "" {
+	if err != nil || !config.Enabled || !config.HasLogChannel() {
 		return
 	}
 
@@ -223,11 +324,13 @@ func (b *Bot) addVoiceEventToBatch(guildID, userID, channelID, eventType string)
 	})
 }
 
-// onGuildMemberUpdate handles nickname and avatar changes (batched)
+// onGuildMemberUpdate handles nickname and avatar changes (batched) and role grants
 func (b *Bot) onGuildMemberUpdate(s *discordgo.Session, m *discordgo.GuildMemberUpdate) {
+	b.logMemberRoleChanges(m)
+
 	// Use cached config for high-frequency events
 	config, err := b.GetLoggingConfigCached(m.GuildID)
-	if err != nil || !config.Enabled || config.LogChannelID == "" {
+	if err != nil || !config.Enabled || !config.HasLogChannel() {
 		return
 	}
 
@@ -283,7 +386,7 @@ func (b *Bot) onPresenceUpdate(s *discordgo.Session, p *discordgo.PresenceUpdate
 
 	// Use cached config for very high-frequency events
 	config, err := b.GetLoggingConfigCached(p.GuildID)
-	if err != nil || !config.Enabled || !config.PresenceChange || config.LogChannelID == "" {
+	if err != nil || !config.Enabled || !config.PresenceChange || config.ChannelFor("presence_change") == "" {
 		return
 	}
 
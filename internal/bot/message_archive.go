@@ -0,0 +1,294 @@
+package bot
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ============================================================================
+// ATTACHMENT ARCHIVE - Keeps copies of attachments for deletion logs
+// ============================================================================
+
+const (
+	// Attachments archived per message, in total, so the whole set can be
+	// re-uploaded in one log message without boosts
+	maxArchivedBytes = 8 << 20
+	// Downloads running at once across all guilds
+	maxArchiveDownloads = 4
+	// Size of the whole archive when paths.attachment_archive_mb isn't set
+	defaultArchiveBudgetMB = 1024
+)
+
+var (
+	archiveClient    = &http.Client{Timeout: 30 * time.Second}
+	archiveDownloads = make(chan struct{}, maxArchiveDownloads)
+)
+
+// archiveUsage tracks how much the archive holds so downloads can check the
+// budget without walking it each time. It's measured from disk on first use
+// and kept up to date as files are reserved and removed.
+var archiveUsage struct {
+	mu     sync.Mutex
+	bytes  int64
+	loaded bool
+}
+
+// attachmentArchiveRoot is where archived attachments live, as
+// <logs folder>/attachments/<guild>/<message>/<attachment ID>
+func attachmentArchiveRoot() string {
+	folder := Global.Paths.LogsFolder
+	if folder == "" {
+		folder = "logs"
+	}
+	return filepath.Join(folder, "attachments")
+}
+
+func attachmentArchivePath(guildID, messageID, attachmentID string) string {
+	return filepath.Join(attachmentArchiveRoot(), guildID, messageID, attachmentID)
+}
+
+// archiveAttachments downloads a message's attachments in the background if
+// the guild keeps them, skipping any that would go over the size cap.
+// Discord removes the files when the message is deleted, so this has to
+// happen up front.
+func (b *Bot) archiveAttachments(m *discordgo.Message) {
+	config, err := b.GetLoggingConfigCached(m.GuildID)
+	if err != nil || !config.Enabled || !config.MessageDelete || !config.ArchiveAttachments {
+		return
+	}
+	if enabled, _ := b.IsChannelLoggingEnabled(m.GuildID, m.ChannelID, "message_delete"); !enabled {
+		return
+	}
+
+	var toArchive []*discordgo.MessageAttachment
+	var total int
+	for _, att := range m.Attachments {
+		if total+att.Size > maxArchivedBytes {
+			continue
+		}
+		total += att.Size
+		toArchive = append(toArchive, att)
+	}
+	if len(toArchive) == 0 {
+		return
+	}
+
+	go func() {
+		defer RecoverFromPanic("archiveAttachments")
+
+		archiveDownloads <- struct{}{}
+		defer func() { <-archiveDownloads }()
+
+		for _, att := range toArchive {
+			path := attachmentArchivePath(m.GuildID, m.ID, att.ID)
+			if _, err := os.Stat(path); err == nil {
+				continue // Edits re-cache the message; already have this one
+			}
+			if !reserveArchiveSpace(int64(att.Size), filepath.Dir(path)) {
+				log.Printf("Skipped archiving attachment %s from message %s: larger than the archive budget", att.ID, m.ID)
+				continue
+			}
+			if err := downloadAttachment(att.URL, path); err != nil {
+				releaseArchiveSpace(int64(att.Size))
+				log.Printf("Failed to archive attachment %s from message %s: %v", att.ID, m.ID, err)
+			}
+		}
+	}()
+}
+
+// downloadAttachment saves a file, writing to a temporary name first so a
+// failed download never looks archived
+func downloadAttachment(url, path string) error {
+	resp, err := archiveClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".part"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	// Trust the cap over the size Discord reported
+	n, err := io.Copy(out, io.LimitReader(resp.Body, maxArchivedBytes+1))
+	out.Close()
+	if err == nil && n > maxArchivedBytes {
+		err = fmt.Errorf("larger than %d bytes", maxArchivedBytes)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// attachmentArchiveBudget is how many bytes the whole archive may hold
+func attachmentArchiveBudget() int64 {
+	mb := Global.Paths.AttachmentArchiveMB
+	if mb <= 0 {
+		mb = defaultArchiveBudgetMB
+	}
+	return int64(mb) << 20
+}
+
+// reserveArchiveSpace counts size bytes against the archive budget, removing
+// the oldest archived messages other than keep until they fit. It reports
+// false if the file can't fit even in an empty archive.
+func reserveArchiveSpace(size int64, keep string) bool {
+	budget := attachmentArchiveBudget()
+	if size > budget {
+		return false
+	}
+
+	archiveUsage.mu.Lock()
+	defer archiveUsage.mu.Unlock()
+
+	if !archiveUsage.loaded {
+		archiveUsage.bytes = 0
+		for _, dir := range archivedMessageDirs() {
+			archiveUsage.bytes += dir.size
+		}
+		archiveUsage.loaded = true
+	}
+
+	if archiveUsage.bytes+size > budget {
+		archiveUsage.bytes -= evictArchivedMessages(archiveUsage.bytes+size-budget, keep)
+	}
+	archiveUsage.bytes += size
+	return true
+}
+
+// releaseArchiveSpace gives back space reserved for a download that failed
+func releaseArchiveSpace(size int64) {
+	archiveUsage.mu.Lock()
+	archiveUsage.bytes -= size
+	archiveUsage.mu.Unlock()
+}
+
+// archivedMessageDir is one message's folder of archived attachments
+type archivedMessageDir struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// archivedMessageDirs lists every archived message, oldest first
+func archivedMessageDirs() []archivedMessageDir {
+	root := attachmentArchiveRoot()
+	guilds, err := os.ReadDir(root)
+	if err != nil {
+		return nil // Nothing archived yet
+	}
+
+	var dirs []archivedMessageDir
+	for _, guild := range guilds {
+		if !guild.IsDir() {
+			continue
+		}
+		messages, err := os.ReadDir(filepath.Join(root, guild.Name()))
+		if err != nil {
+			continue
+		}
+		for _, message := range messages {
+			info, err := message.Info()
+			if err != nil || !info.IsDir() {
+				continue
+			}
+			dir := archivedMessageDir{path: filepath.Join(root, guild.Name(), message.Name()), modTime: info.ModTime()}
+			if files, err := os.ReadDir(dir.path); err == nil {
+				for _, file := range files {
+					if fi, err := file.Info(); err == nil {
+						dir.size += fi.Size()
+					}
+				}
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+
+	sort.Slice(dirs, func(i, j int) bool { return dirs[i].modTime.Before(dirs[j].modTime) })
+	return dirs
+}
+
+// evictArchivedMessages removes the oldest archived messages other than keep
+// until at least need bytes are freed, returning how many were
+func evictArchivedMessages(need int64, keep string) int64 {
+	var freed int64
+	removed := 0
+	for _, dir := range archivedMessageDirs() {
+		if freed >= need {
+			break
+		}
+		if dir.path == keep {
+			continue
+		}
+		if err := os.RemoveAll(dir.path); err != nil {
+			log.Printf("Failed to remove archived attachments %s: %v", dir.path, err)
+			continue
+		}
+		freed += dir.size
+		removed++
+	}
+
+	if removed > 0 {
+		DebugLog("Removed archived attachments of %d messages to stay under the archive budget", removed)
+	}
+	return freed
+}
+
+// archivedFiles returns the archived copies of a deleted message's attachments
+func archivedFiles(guildID, messageID string, attachments []*discordgo.MessageAttachment) []logFile {
+	var files []logFile
+	for _, att := range attachments {
+		path := attachmentArchivePath(guildID, messageID, att.ID)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		files = append(files, logFile{Name: att.Filename, ContentType: att.ContentType, Path: path})
+	}
+	return files
+}
+
+// cleanAttachmentArchive removes archived attachments of messages cached
+// before the cutoff
+func (b *Bot) cleanAttachmentArchive(cutoff time.Time) {
+	archiveUsage.mu.Lock()
+	defer archiveUsage.mu.Unlock()
+
+	removed := 0
+	for _, dir := range archivedMessageDirs() {
+		if dir.modTime.After(cutoff) {
+			break // Oldest first, so the rest are newer
+		}
+		if err := os.RemoveAll(dir.path); err != nil {
+			log.Printf("Failed to remove archived attachments %s: %v", dir.path, err)
+			continue
+		}
+		archiveUsage.bytes -= dir.size
+		removed++
+	}
+
+	if removed > 0 {
+		DebugLog("Removed archived attachments of %d old messages", removed)
+	}
+}
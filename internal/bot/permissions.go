@@ -1,20 +1,23 @@
 package bot
 
 import (
+	"fmt"
 	"log"
 	"sort"
 
 	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
 )
 
 // PermissionChecker handles permission and hierarchy validation
 type PermissionChecker struct {
+	bot     *Bot
 	session *discordgo.Session
 }
 
 // NewPermissionChecker creates a new permission checker
-func NewPermissionChecker(s *discordgo.Session) *PermissionChecker {
-	return &PermissionChecker{session: s}
+func NewPermissionChecker(b *Bot) *PermissionChecker {
+	return &PermissionChecker{bot: b, session: b.Session}
 }
 
 // HasPermission checks if a user has a specific permission in a guild
@@ -148,6 +151,23 @@ func (pc *PermissionChecker) HasExemptRole(guildID, userID string, exemptRoleIDs
 // CanModerate checks if moderator can take action against target
 // Returns: canModerate, reason
 func (pc *PermissionChecker) CanModerate(guildID, moderatorID, targetID string) (bool, string) {
+	canAct, reason := pc.CanActOn(guildID, moderatorID, targetID)
+	if !canAct || pc.IsBotOwner(moderatorID) || pc.IsOwner(guildID, moderatorID) {
+		return canAct, reason
+	}
+
+	// Check if moderator has ban permissions
+	if !pc.HasPermission(guildID, moderatorID, discordgo.PermissionBanMembers) {
+		return false, "You don't have ban permissions"
+	}
+
+	return true, ""
+}
+
+// CanActOn checks ownership and role hierarchy between moderator and target,
+// without requiring any particular permission
+// Returns: canAct, reason
+func (pc *PermissionChecker) CanActOn(guildID, moderatorID, targetID string) (bool, string) {
 	// Bot owners can do anything
 	if pc.IsBotOwner(moderatorID) {
 		return true, ""
@@ -171,15 +191,10 @@ func (pc *PermissionChecker) CanModerate(guildID, moderatorID, targetID string)
 		return false, "Cannot moderate server owner"
 	}
 
-	// Check if moderator has ban permissions
-	if !pc.HasPermission(guildID, moderatorID, discordgo.PermissionBanMembers) {
-		return false, "You don't have ban permissions"
-	}
-
 	// Check hierarchy
 	if !pc.IsHigherRank(guildID, moderatorID, targetID) {
 		// Check if same rank moderation is allowed
-		if pc.IsSameRank(guildID, moderatorID, targetID) && Global.SpamFilter.AllowSameRoleModeration {
+		if pc.IsSameRank(guildID, moderatorID, targetID) && pc.bot.GetSpamFilterSettings(guildID).AllowSameRoleModeration {
 			return true, ""
 		}
 		return false, "Target has equal or higher role"
@@ -209,6 +224,10 @@ func (pc *PermissionChecker) GetRolesSorted(guildID string) ([]*discordgo.Role,
 func (pc *PermissionChecker) AutoBanViolator(guildID, userID, reason string) error {
 	DebugLog("Auto-banning user %s in guild %s: %s", userID, guildID, reason)
 
+	if pc.bot.blockProtected(guildID, userID, commands.CaseBan, pc.session.State.User.ID) {
+		return fmt.Errorf("user %s is protected", userID)
+	}
+
 	err := pc.session.GuildBanCreateWithReason(guildID, userID, reason, 1)
 	if err != nil {
 		log.Printf("❌ Failed to auto-ban %s: %v", userID, err)
@@ -216,5 +235,13 @@ func (pc *PermissionChecker) AutoBanViolator(guildID, userID, reason string) err
 	}
 
 	log.Printf("🔨 Auto-banned user %s: %s", userID, reason)
+
+	pc.bot.RecordModCase(&commands.ModCase{
+		GuildID:     guildID,
+		Action:      commands.CaseBan,
+		UserID:      userID,
+		ModeratorID: pc.session.State.User.ID,
+		Reason:      "[Auto] " + reason,
+	})
 	return nil
 }
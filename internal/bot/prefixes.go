@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+
+	"yuno-go/internal/commands"
+)
+
+// ============================================================================
+// PREFIXES - Per-guild prefixes are checked on every message
+// ============================================================================
+
+// defaultPrefix is the global prefix from config
+func defaultPrefix() string {
+	if Global.Bot.Prefix == "" {
+		return "?"
+	}
+	return Global.Bot.Prefix
+}
+
+// defaultGuildPrefixes is used for guilds that never configured a prefix
+func defaultGuildPrefixes() *commands.GuildPrefixes {
+	return &commands.GuildPrefixes{
+		Prefixes:      []string{defaultPrefix()},
+		MentionPrefix: true,
+	}
+}
+
+// GetGuildPrefixesCached returns a guild's prefixes, falling back to the global prefix
+func (b *Bot) GetGuildPrefixesCached(guildID string) *commands.GuildPrefixes {
+	if guildID == "" {
+		return defaultGuildPrefixes()
+	}
+
+	if b.PrefixCache != nil {
+		if prefixes, ok := b.PrefixCache.Get(guildID); ok {
+			return prefixes
+		}
+	}
+
+	prefixes, err := b.DB.GetGuildPrefixes(guildID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error loading prefixes for guild %s: %v", guildID, err)
+		}
+		prefixes = defaultGuildPrefixes()
+	}
+
+	if b.PrefixCache != nil {
+		b.PrefixCache.Set(guildID, prefixes)
+	}
+
+	return prefixes
+}
+
+// SetGuildPrefixes saves a guild's prefixes and refreshes the cache
+func (b *Bot) SetGuildPrefixes(guildID string, prefixes *commands.GuildPrefixes) error {
+	if err := b.DB.SaveGuildPrefixes(guildID, prefixes); err != nil {
+		return err
+	}
+	if b.PrefixCache != nil {
+		b.PrefixCache.Invalidate(guildID)
+	}
+	return nil
+}
+
+// ResetGuildPrefixes returns a guild to the global prefix
+func (b *Bot) ResetGuildPrefixes(guildID string) error {
+	if err := b.DB.DeleteGuildPrefixes(guildID); err != nil {
+		return err
+	}
+	if b.PrefixCache != nil {
+		b.PrefixCache.Invalidate(guildID)
+	}
+	return nil
+}
+
+// matchCommandPrefix strips a guild prefix (or bot mention) from a message.
+// A mention only counts as a prefix when it's followed by a known command, so
+// plain pings still reach the delay command.
+func (b *Bot) matchCommandPrefix(guildID, content, botID string) (string, bool) {
+	prefixes := b.GetGuildPrefixesCached(guildID)
+
+	if rest, ok := prefixes.Match(content); ok {
+		return rest, true
+	}
+
+	if !prefixes.MentionPrefix {
+		return "", false
+	}
+
+	for _, mention := range []string{"<@" + botID + ">", "<@!" + botID + ">"} {
+		if !strings.HasPrefix(content, mention) {
+			continue
+		}
+
+		rest := strings.TrimSpace(strings.TrimPrefix(content, mention))
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return "", false
+		}
+		if _, err := b.Commands.Get(fields[0]); err != nil {
+			return "", false
+		}
+		return rest, true
+	}
+
+	return "", false
+}
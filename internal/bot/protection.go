@@ -0,0 +1,120 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+
+	"yuno-go/internal/commands"
+)
+
+// ============================================================================
+// PROTECTION - Roles and users that can't be banned or kicked
+// ============================================================================
+
+// GetProtectedTargets returns a guild's protected roles and users
+func (b *Bot) GetProtectedTargets(guildID string) []commands.ProtectedTarget {
+	if b.ProtectionCache != nil {
+		if targets, ok := b.ProtectionCache.Get(guildID); ok {
+			return append([]commands.ProtectedTarget(nil), targets...)
+		}
+	}
+
+	targets, err := b.DB.GetProtectedTargets(guildID)
+	if err != nil {
+		log.Printf("Error loading protected targets for guild %s: %v", guildID, err)
+		return nil
+	}
+
+	if b.ProtectionCache != nil {
+		b.ProtectionCache.Set(guildID, targets)
+	}
+	return append([]commands.ProtectedTarget(nil), targets...)
+}
+
+// AddProtectedTarget protects a role or user
+func (b *Bot) AddProtectedTarget(target commands.ProtectedTarget) error {
+	if err := b.DB.AddProtectedTarget(target); err != nil {
+		return err
+	}
+	if b.ProtectionCache != nil {
+		b.ProtectionCache.Invalidate(target.GuildID)
+	}
+	return nil
+}
+
+// RemoveProtectedTarget drops a role or user's protection
+func (b *Bot) RemoveProtectedTarget(guildID, targetType, targetID string) (bool, error) {
+	removed, err := b.DB.RemoveProtectedTarget(guildID, targetType, targetID)
+	if err != nil {
+		return false, err
+	}
+	if b.ProtectionCache != nil {
+		b.ProtectionCache.Invalidate(guildID)
+	}
+	return removed, nil
+}
+
+// HierarchyReason says why a moderator can't act on a member, or "" if they can
+func (b *Bot) HierarchyReason(guildID, moderatorID, targetID string) string {
+	if canAct, reason := b.PermChecker.CanActOn(guildID, moderatorID, targetID); !canAct {
+		return reason
+	}
+	return ""
+}
+
+// ProtectedReason says why a member can't be banned or kicked, or "" if they can
+func (b *Bot) ProtectedReason(guildID, userID string) string {
+	targets := b.GetProtectedTargets(guildID)
+	if len(targets) == 0 {
+		return ""
+	}
+
+	hasRoles := false
+	for _, t := range targets {
+		if t.TargetType == commands.ProtectUser && t.TargetID == userID {
+			return "they are on the protected list"
+		}
+		hasRoles = hasRoles || t.TargetType == commands.ProtectRole
+	}
+	if !hasRoles {
+		return ""
+	}
+
+	// Users who aren't in the server have no roles to check
+	member, err := b.Session.State.Member(guildID, userID)
+	if err != nil {
+		if member, err = b.Session.GuildMember(guildID, userID); err != nil {
+			return ""
+		}
+	}
+	for _, t := range targets {
+		if t.TargetType != commands.ProtectRole {
+			continue
+		}
+		for _, roleID := range member.Roles {
+			if roleID == t.TargetID {
+				return fmt.Sprintf("they have the protected role <@&%s>", roleID)
+			}
+		}
+	}
+	return ""
+}
+
+// blockProtected stops an automatic or terminal ban or kick on a protected
+// member, recording the attempt. It reports whether it refused.
+func (b *Bot) blockProtected(guildID, userID, action, moderatorID string) bool {
+	why := b.ProtectedReason(guildID, userID)
+	if why == "" {
+		return false
+	}
+
+	log.Printf("🛡️  Refused to %s protected user %s in %s: %s", action, userID, guildID, why)
+	b.RecordModCase(&commands.ModCase{
+		GuildID:     guildID,
+		Action:      commands.CaseBlocked,
+		UserID:      userID,
+		ModeratorID: moderatorID,
+		Reason:      fmt.Sprintf("Blocked %s: %s", action, why),
+	})
+	return true
+}
@@ -0,0 +1,170 @@
+package bot
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"yuno-go/internal/commands"
+)
+
+// defaultRankMode is the rank reward mode for guilds that never picked one
+func defaultRankMode() string {
+	if strings.EqualFold(strings.TrimSpace(Global.Leveling.RankMode), commands.RankModeReplace) {
+		return commands.RankModeReplace
+	}
+	return commands.RankModeStack
+}
+
+// GetRankMode returns whether a guild stacks rank roles or keeps only the highest
+func (b *Bot) GetRankMode(guildID string) string {
+	mode, err := b.DB.GetRankMode(guildID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error loading rank mode for guild %s: %v", guildID, err)
+		}
+		return defaultRankMode()
+	}
+	return mode
+}
+
+// SetRankMode stores a guild's rank reward mode
+func (b *Bot) SetRankMode(guildID, mode string) error {
+	return b.DB.SetRankMode(guildID, mode)
+}
+
+// rankRoleDiff works out which rank roles a member at level should gain and
+// lose. Roles that aren't rank rewards are never touched.
+func rankRoleDiff(ranks []commands.GuildRank, mode string, level int, memberRoles []string) (add, remove []string) {
+	// In replace mode only the highest reached tier is kept
+	top := -1
+	for _, rank := range ranks {
+		if rank.Level <= level && rank.Level > top {
+			top = rank.Level
+		}
+	}
+
+	want := make(map[string]bool, len(ranks))
+	for _, rank := range ranks {
+		eligible := rank.Level <= level
+		if mode == commands.RankModeReplace {
+			eligible = rank.Level == top
+		}
+		// A role listed at several levels is wanted if any of them apply
+		want[rank.RoleID] = want[rank.RoleID] || eligible
+	}
+
+	has := make(map[string]bool, len(memberRoles))
+	for _, roleID := range memberRoles {
+		has[roleID] = true
+	}
+
+	for _, rank := range ranks {
+		roleID := rank.RoleID
+		wanted, seen := want[roleID]
+		if !seen {
+			continue
+		}
+		delete(want, roleID)
+
+		switch {
+		case wanted && !has[roleID]:
+			add = append(add, roleID)
+		case !wanted && has[roleID]:
+			remove = append(remove, roleID)
+		}
+	}
+	return add, remove
+}
+
+// syncRankRoles brings a member's rank roles in line with their level, only
+// calling the API for roles that actually change
+func (b *Bot) syncRankRoles(guildID, userID string, level int) (added, removed int, err error) {
+	ranks, err := b.DB.GetGuildRanks(guildID)
+	if err != nil || len(ranks) == 0 {
+		return 0, 0, err
+	}
+
+	member, err := b.Session.State.Member(guildID, userID)
+	if err != nil {
+		member, err = b.Session.GuildMember(guildID, userID)
+		if err != nil {
+			// Not in the guild anymore; nothing to reconcile
+			return 0, 0, nil
+		}
+	}
+	if member.User != nil && member.User.Bot {
+		return 0, 0, nil
+	}
+
+	add, remove := rankRoleDiff(ranks, b.GetRankMode(guildID), level, member.Roles)
+
+	var failed []string
+	for _, roleID := range add {
+		if err := b.Session.GuildMemberRoleAdd(guildID, userID, roleID); err != nil {
+			failed = append(failed, fmt.Sprintf("add %s: %v", roleID, err))
+			continue
+		}
+		added++
+	}
+	for _, roleID := range remove {
+		if err := b.Session.GuildMemberRoleRemove(guildID, userID, roleID); err != nil {
+			failed = append(failed, fmt.Sprintf("remove %s: %v", roleID, err))
+			continue
+		}
+		removed++
+	}
+
+	if added > 0 || removed > 0 {
+		DebugLog("[Ranks] %s in guild %s at level %d: +%d -%d roles", userID, guildID, level, added, removed)
+	}
+	if len(failed) > 0 {
+		return added, removed, fmt.Errorf("rank roles for %s: %s", userID, strings.Join(failed, "; "))
+	}
+	return added, removed, nil
+}
+
+// ReconcileRankRoles runs syncRankRoles for every member with stored XP.
+// progress, if set, is called every 50 members.
+func (b *Bot) ReconcileRankRoles(guildID string, progress func(processed int)) (commands.RankSyncResult, error) {
+	var result commands.RankSyncResult
+
+	levels, err := b.DB.GetGuildLevels(guildID)
+	if err != nil {
+		return result, err
+	}
+
+	for userID, level := range levels {
+		added, removed, err := b.syncRankRoles(guildID, userID, level)
+		result.Added += added
+		result.Removed += removed
+		if err != nil {
+			DebugLog("[Ranks] Reconcile failed: %v", err)
+			result.Errors++
+		}
+
+		result.Processed++
+		if progress != nil && result.Processed%50 == 0 {
+			progress(result.Processed)
+		}
+	}
+
+	return result, nil
+}
+
+// reconcileRankRolesAsync reconciles a whole guild in the background after a
+// bulk level change
+func (b *Bot) reconcileRankRolesAsync(guildID string) {
+	go func() {
+		defer RecoverFromPanic(fmt.Sprintf("reconcileRankRoles(guild=%s)", guildID))
+
+		result, err := b.ReconcileRankRoles(guildID, nil)
+		if err != nil {
+			log.Printf("Error reconciling rank roles for guild %s: %v", guildID, err)
+			return
+		}
+		DebugLog("[Ranks] Reconciled guild %s: %d members, +%d -%d roles, %d errors",
+			guildID, result.Processed, result.Added, result.Removed, result.Errors)
+	}()
+}
@@ -0,0 +1,386 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
+)
+
+const (
+	// regexBudget is how long one filter may take on one message
+	regexBudget = 5 * time.Millisecond
+	// regexBudgetStrikes is how many overruns within regexStrikeWindow
+	// disable a filter. Go's regexps run in linear time, so a lone overrun is
+	// usually a GC pause or a busy scheduler rather than the pattern.
+	regexBudgetStrikes = 3
+	regexStrikeWindow  = 10 * time.Minute
+)
+
+// filterKey identifies a filter; guild-wide and channel filters have separate IDs
+type filterKey struct {
+	id      int
+	channel bool
+}
+
+type compiledFilter struct {
+	key    filterKey
+	filter commands.RegexFilter
+	re     *regexp.Regexp
+}
+
+// filterGroup is a list of filters with one combined regexp, so messages that
+// match nothing only need a single pass
+type filterGroup struct {
+	filters  []compiledFilter
+	combined *regexp.Regexp
+}
+
+type compiledFilterSet struct {
+	guild     *filterGroup
+	channels  map[string]*filterGroup
+	expiresAt time.Time
+}
+
+// budgetStrikes counts a filter's recent overruns
+type budgetStrikes struct {
+	count int
+	since time.Time
+}
+
+type pendingHits struct {
+	hits    int64
+	lastHit time.Time
+}
+
+// ============================================================================
+// REGEX FILTER ENGINE - Compiled filters per guild, with hit counters
+// ============================================================================
+
+type RegexFilterEngine struct {
+	bot  *Bot
+	sets map[string]*compiledFilterSet
+	mu   sync.RWMutex
+	ttl  time.Duration
+
+	hits    map[filterKey]*pendingHits
+	strikes map[filterKey]*budgetStrikes
+	hitsMu  sync.Mutex
+
+	stopChan      chan struct{}
+	flushInterval time.Duration
+}
+
+func NewRegexFilterEngine(b *Bot, ttl time.Duration) *RegexFilterEngine {
+	return &RegexFilterEngine{
+		bot:           b,
+		sets:          make(map[string]*compiledFilterSet),
+		ttl:           ttl,
+		hits:          make(map[filterKey]*pendingHits),
+		strikes:       make(map[filterKey]*budgetStrikes),
+		stopChan:      make(chan struct{}),
+		flushInterval: 30 * time.Second,
+	}
+}
+
+// Start begins writing hit counters periodically
+func (fe *RegexFilterEngine) Start() {
+	go fe.run()
+}
+
+// Stop stops the engine and writes the remaining hit counters
+func (fe *RegexFilterEngine) Stop() {
+	close(fe.stopChan)
+	fe.flushHits()
+}
+
+func (fe *RegexFilterEngine) run() {
+	ticker := time.NewTicker(fe.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-fe.stopChan:
+			return
+		case <-ticker.C:
+			fe.flushHits()
+		}
+	}
+}
+
+// Invalidate drops a guild's compiled filters after they change
+func (fe *RegexFilterEngine) Invalidate(guildID string) {
+	fe.mu.Lock()
+	defer fe.mu.Unlock()
+	delete(fe.sets, guildID)
+}
+
+// Match returns the first guild-wide filter, then channel filter, that
+// matches the content
+func (fe *RegexFilterEngine) Match(guildID, channelID, content string) *commands.RegexFilter {
+	set := fe.get(guildID)
+
+	if f := fe.matchGroup(guildID, set.guild, content); f != nil {
+		return f
+	}
+	if group, ok := set.channels[channelID]; ok {
+		return fe.matchGroup(guildID, group, content)
+	}
+	return nil
+}
+
+func (fe *RegexFilterEngine) matchGroup(guildID string, group *filterGroup, content string) *commands.RegexFilter {
+	if group == nil || len(group.filters) == 0 {
+		return nil
+	}
+	if group.combined != nil && !group.combined.MatchString(content) {
+		return nil
+	}
+
+	for i := range group.filters {
+		f := &group.filters[i]
+
+		start := time.Now()
+		matched := f.re.MatchString(content)
+		if elapsed := time.Since(start); elapsed > regexBudget {
+			fe.overBudget(guildID, f, elapsed)
+		}
+
+		if matched {
+			fe.recordHit(f.key)
+			filter := f.filter
+			return &filter
+		}
+	}
+	return nil
+}
+
+// MatchAll returns every enabled filter that matches the content, without
+// counting hits. Used for dry runs.
+func (fe *RegexFilterEngine) MatchAll(guildID, channelID, content string) []commands.RegexFilter {
+	set := fe.get(guildID)
+
+	var matched []commands.RegexFilter
+	for _, group := range []*filterGroup{set.guild, set.channels[channelID]} {
+		if group == nil {
+			continue
+		}
+		for _, f := range group.filters {
+			if f.re.MatchString(content) {
+				matched = append(matched, f.filter)
+			}
+		}
+	}
+	return matched
+}
+
+// get returns a guild's compiled filters, compiling them if needed
+func (fe *RegexFilterEngine) get(guildID string) *compiledFilterSet {
+	fe.mu.RLock()
+	set, exists := fe.sets[guildID]
+	fe.mu.RUnlock()
+	if exists && time.Now().Before(set.expiresAt) {
+		return set
+	}
+
+	set = &compiledFilterSet{
+		channels:  make(map[string]*filterGroup),
+		expiresAt: time.Now().Add(fe.ttl),
+	}
+
+	filters, err := fe.bot.DB.GetAllRegexFilters(guildID)
+	if err != nil {
+		log.Printf("Error loading regex filters for guild %s: %v", guildID, err)
+	}
+
+	guildFilters := []compiledFilter{}
+	channelFilters := make(map[string][]compiledFilter)
+	for _, filter := range filters {
+		if !filter.Enabled {
+			continue
+		}
+		re, err := commands.CompileFilterPattern(filter.Pattern)
+		if err != nil {
+			log.Printf("Invalid regex pattern in filter %d: %v", filter.ID, err)
+			continue
+		}
+
+		compiled := compiledFilter{
+			key:    filterKey{id: filter.ID, channel: filter.ChannelID != ""},
+			filter: filter,
+			re:     re,
+		}
+		if filter.ChannelID == "" {
+			guildFilters = append(guildFilters, compiled)
+		} else {
+			channelFilters[filter.ChannelID] = append(channelFilters[filter.ChannelID], compiled)
+		}
+	}
+
+	set.guild = newFilterGroup(guildFilters)
+	for channelID, compiled := range channelFilters {
+		set.channels[channelID] = newFilterGroup(compiled)
+	}
+
+	fe.mu.Lock()
+	fe.sets[guildID] = set
+	fe.mu.Unlock()
+	return set
+}
+
+// newFilterGroup builds the combined matcher for a list of filters. Each
+// pattern sits in its own group, so inline flags like (?i) stay scoped to it.
+func newFilterGroup(filters []compiledFilter) *filterGroup {
+	group := &filterGroup{filters: filters}
+	if len(filters) < 2 {
+		return group
+	}
+
+	parts := make([]string, len(filters))
+	for i, f := range filters {
+		parts[i] = "(?:" + f.filter.Pattern + ")"
+	}
+	combined, err := regexp.Compile(strings.Join(parts, "|"))
+	if err != nil {
+		// Too big to combine; each filter is still checked on its own
+		DebugLog("Could not combine %d regex filters: %v", len(filters), err)
+		return group
+	}
+	group.combined = combined
+	return group
+}
+
+// recordHit counts a match; counters are written in batches
+func (fe *RegexFilterEngine) recordHit(key filterKey) {
+	fe.hitsMu.Lock()
+	defer fe.hitsMu.Unlock()
+
+	pending, ok := fe.hits[key]
+	if !ok {
+		pending = &pendingHits{}
+		fe.hits[key] = pending
+	}
+	pending.hits++
+	pending.lastHit = time.Now()
+}
+
+// overBudget disables a filter that keeps taking too long
+func (fe *RegexFilterEngine) overBudget(guildID string, f *compiledFilter, elapsed time.Duration) {
+	now := time.Now()
+
+	fe.hitsMu.Lock()
+	recent, ok := fe.strikes[f.key]
+	if !ok || now.Sub(recent.since) > regexStrikeWindow {
+		recent = &budgetStrikes{since: now}
+		fe.strikes[f.key] = recent
+	}
+	recent.count++
+	strikes := recent.count
+	if strikes >= regexBudgetStrikes {
+		delete(fe.strikes, f.key)
+	}
+	fe.hitsMu.Unlock()
+
+	log.Printf("Regex filter %d in guild %s took %s (%d/%d)", f.filter.ID, guildID, elapsed, strikes, regexBudgetStrikes)
+	if strikes < regexBudgetStrikes {
+		return
+	}
+
+	if err := fe.bot.DB.DisableRegexFilter(f.key.id, f.key.channel); err != nil {
+		log.Printf("Failed to disable slow regex filter %d: %v", f.filter.ID, err)
+		return
+	}
+	log.Printf("⚠️  Disabled regex filter %d in guild %s: too slow", f.filter.ID, guildID)
+	fe.Invalidate(guildID)
+	fe.bot.notifyFilterDisabled(guildID, f.filter, elapsed)
+}
+
+// notifyFilterDisabled tells the guild's mod log that a filter was switched off
+func (b *Bot) notifyFilterDisabled(guildID string, filter commands.RegexFilter, elapsed time.Duration) {
+	config, err := b.GetLoggingConfigCached(guildID)
+	if err != nil || !config.Enabled || config.ChannelFor(commands.LogModActions) == "" {
+		return
+	}
+
+	scope := "Server-wide"
+	enable := fmt.Sprintf("enablefilter %d", filter.ID)
+	if filter.ChannelID != "" {
+		scope = "<#" + filter.ChannelID + ">"
+		enable = fmt.Sprintf("enablefilter <#%s> %d", filter.ChannelID, filter.ID)
+	}
+
+	pattern := filter.Pattern
+	if len(pattern) > 1000 {
+		pattern = pattern[:1000] + "..."
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "⚠️ Regex Filter Disabled",
+		Description: fmt.Sprintf("Filter **#%d** took too long on %d messages within %d minutes (last one %s), so it was turned off. Use `%s` to turn it back on.",
+			filter.ID, regexBudgetStrikes, int(regexStrikeWindow.Minutes()), elapsed.Round(time.Microsecond), enable),
+		Color: 0xFFAA00,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Scope", Value: scope, Inline: true},
+			{Name: "Action", Value: filter.Action, Inline: true},
+			{Name: "Pattern", Value: "`" + pattern + "`", Inline: false},
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	b.LogDelivery.Send(guildID, config.ChannelFor(commands.LogModActions), embed)
+}
+
+// flushHits writes pending hit counters to the database
+func (fe *RegexFilterEngine) flushHits() {
+	fe.hitsMu.Lock()
+	batch := fe.hits
+	fe.hits = make(map[filterKey]*pendingHits)
+	fe.hitsMu.Unlock()
+
+	for key, pending := range batch {
+		if err := fe.bot.DB.AddRegexFilterHits(key.id, key.channel, pending.hits, pending.lastHit); err != nil {
+			DebugLog("Failed to save hits for regex filter %d: %v", key.id, err)
+		}
+	}
+}
+
+// GetRegexFilters returns a guild's guild-wide or channel filters with
+// up-to-date hit counters
+func (b *Bot) GetRegexFilters(guildID, channelID string) ([]commands.RegexFilter, error) {
+	b.FilterEngine.flushHits()
+	return b.DB.GetRegexFilters(guildID, channelID)
+}
+
+// AddRegexFilter stores a filter and recompiles the guild's filters
+func (b *Bot) AddRegexFilter(filter *commands.RegexFilter) (int, error) {
+	id, err := b.DB.AddRegexFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+	b.FilterEngine.Invalidate(filter.GuildID)
+	return id, nil
+}
+
+// SetRegexFilterEnabled turns a filter on or off and recompiles the guild's filters
+func (b *Bot) SetRegexFilterEnabled(guildID, channelID string, id int, enabled bool) (bool, error) {
+	found, err := b.DB.SetRegexFilterEnabled(guildID, channelID, id, enabled)
+	if err != nil {
+		return false, err
+	}
+	b.FilterEngine.Invalidate(guildID)
+	return found, nil
+}
+
+// RemoveRegexFilter deletes a filter and recompiles the guild's filters
+func (b *Bot) RemoveRegexFilter(guildID, channelID string, id int) (bool, error) {
+	removed, err := b.DB.RemoveRegexFilter(guildID, channelID, id)
+	if err != nil {
+		return false, err
+	}
+	b.FilterEngine.Invalidate(guildID)
+	return removed, nil
+}
@@ -0,0 +1,252 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
+)
+
+const (
+	// Failing actions are retried after 30s, then twice as long each time up
+	// to an hour, until the ban, member or role is gone
+	scheduledRetryBase = 30 * time.Second
+	scheduledRetryMax  = time.Hour
+	// An action still failing this long after it was due is dropped and
+	// reported to the mod log
+	scheduledGiveUpAfter = 7 * 24 * time.Hour
+)
+
+// ScheduleWorker expires tempbans, timed mutes and raid lockdowns. Pending actions live in the
+// database, so anything that came due while the bot was offline runs on the
+// first check after reconnecting.
+type ScheduleWorker struct {
+	bot      *Bot
+	stopChan chan bool
+	ticker   *time.Ticker
+	mu       sync.Mutex // Prevents overlapping runs (ticker + ready catch-up)
+}
+
+// NewScheduleWorker creates a new schedule worker
+func NewScheduleWorker(bot *Bot) *ScheduleWorker {
+	return &ScheduleWorker{
+		bot:      bot,
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins the schedule background worker
+func (w *ScheduleWorker) Start() {
+	log.Println("Starting schedule worker...")
+	w.ticker = time.NewTicker(30 * time.Second)
+
+	go func() {
+		for {
+			select {
+			case <-w.ticker.C:
+				w.RunDue()
+			case <-w.stopChan:
+				w.ticker.Stop()
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the schedule worker
+func (w *ScheduleWorker) Stop() {
+	log.Println("Stopping schedule worker...")
+	w.stopChan <- true
+}
+
+// RunDue executes every action whose time has passed
+func (w *ScheduleWorker) RunDue() {
+	defer RecoverFromPanic("ScheduleWorker.RunDue")
+
+	// Don't run if bot is not connected
+	if w.bot.Session == nil || w.bot.Session.State == nil || w.bot.Session.State.User == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	actions, err := w.bot.DB.GetDueScheduledActions(time.Now())
+	if err != nil {
+		log.Printf("Error querying scheduled actions: %v", err)
+		return
+	}
+
+	if len(actions) > 0 {
+		DebugLog("[Scheduler] %d actions due", len(actions))
+	}
+
+	for _, action := range actions {
+		w.execute(action)
+	}
+}
+
+// execute runs a single action and records the matching case
+func (w *ScheduleWorker) execute(action *commands.ScheduledAction) {
+	s := w.bot.Session
+	var err error
+	var caseAction, reason string
+
+	switch action.Action {
+	case commands.ScheduledEndRaid:
+		if err := w.bot.EndRaid(action.GuildID, "Lockdown expired"); err != nil {
+			log.Printf("[Scheduler] Failed to end raid lockdown in %s: %v", action.GuildID, err)
+			if w.retryLater(action, err) {
+				return
+			}
+		}
+		w.bot.DB.DeleteScheduledAction(action.ID)
+		return
+	case commands.ScheduledUnban:
+		caseAction = commands.CaseUnban
+		reason = "Temporary ban expired"
+		err = s.GuildBanDelete(action.GuildID, action.UserID)
+	case commands.ScheduledRemoveRole:
+		caseAction = commands.CaseUnmute
+		reason = "Mute expired"
+		err = s.GuildMemberRoleRemove(action.GuildID, action.UserID, action.RoleID)
+	default:
+		log.Printf("[Scheduler] Unknown action %q, dropping", action.Action)
+		w.bot.DB.DeleteScheduledAction(action.ID)
+		return
+	}
+
+	if action.CaseNumber > 0 {
+		reason += fmt.Sprintf(" (case #%d)", action.CaseNumber)
+	}
+
+	if err != nil && !isGoneError(err) {
+		log.Printf("[Scheduler] Failed to %s %s in %s: %v", action.Action, action.UserID, action.GuildID, err)
+		if w.retryLater(action, err) {
+			return
+		}
+	}
+
+	w.bot.DB.DeleteScheduledAction(action.ID)
+
+	if err != nil {
+		// Either the ban, member or role is already gone, or retrying gave
+		// up and the mod log was told; there's no case to record
+		return
+	}
+
+	w.bot.RecordModCase(&commands.ModCase{
+		GuildID:     action.GuildID,
+		Action:      caseAction,
+		UserID:      action.UserID,
+		ModeratorID: s.State.User.ID,
+		Reason:      reason,
+	})
+
+	log.Printf("⏰ Scheduled %s completed for %s in %s", action.Action, action.UserID, action.GuildID)
+}
+
+// retryLater backs a failed action off and reports whether it will be
+// retried. Once it has failed for too long it gives up and alerts the mod log.
+func (w *ScheduleWorker) retryLater(action *commands.ScheduledAction, err error) bool {
+	if time.Since(action.ExecuteAt) < scheduledGiveUpAfter {
+		delay := scheduledRetryDelay(action.Attempts + 1)
+		if err := w.bot.DB.RetryScheduledActionAt(action.ID, time.Now().Add(delay)); err != nil {
+			log.Printf("[Scheduler] Failed to reschedule %s for %s: %v", action.Action, action.UserID, err)
+		}
+		DebugLog("[Scheduler] Retrying %s for %s in %s after %s (attempt %d)",
+			action.Action, action.UserID, action.GuildID, delay, action.Attempts+1)
+		return true
+	}
+
+	log.Printf("[Scheduler] Giving up on %s for %s in %s", action.Action, action.UserID, action.GuildID)
+	w.bot.notifyScheduledFailure(action, err)
+	return false
+}
+
+// scheduledRetryDelay is how long to wait after the given number of failures
+func scheduledRetryDelay(attempts int) time.Duration {
+	delay := scheduledRetryBase
+	for i := 1; i < attempts && delay < scheduledRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, scheduledRetryMax)
+}
+
+// isGoneError reports whether Discord says the target no longer exists
+// (unknown ban, member, role or guild), which means retrying can't help
+func isGoneError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "Unknown Ban") ||
+		strings.Contains(msg, "Unknown Member") ||
+		strings.Contains(msg, "Unknown Role") ||
+		strings.Contains(msg, "Unknown Guild")
+}
+
+// notifyScheduledFailure tells the mod log that a scheduled action was
+// dropped, so a moderator can finish it by hand
+func (b *Bot) notifyScheduledFailure(action *commands.ScheduledAction, err error) {
+	config, cfgErr := b.GetLoggingConfigCached(action.GuildID)
+	if cfgErr != nil || !config.Enabled || config.ChannelFor(commands.LogModActions) == "" {
+		return
+	}
+
+	var what string
+	switch action.Action {
+	case commands.ScheduledUnban:
+		what = fmt.Sprintf("The temporary ban of <@%s> couldn't be lifted, so they are still banned.", action.UserID)
+	case commands.ScheduledRemoveRole:
+		what = fmt.Sprintf("The mute of <@%s> couldn't be lifted, so they still have <@&%s>.", action.UserID, action.RoleID)
+	case commands.ScheduledEndRaid:
+		what = "The raid lockdown couldn't be ended, so the server is still locked down."
+	default:
+		what = fmt.Sprintf("Scheduled %s couldn't be run.", action.Action)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "⚠️ Scheduled Action Failed",
+		Description: fmt.Sprintf("%s It was due <t:%d:R> and kept failing, so it won't be retried.", what, action.ExecuteAt.Unix()),
+		Color:       0xFFAA00,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Attempts", Value: fmt.Sprintf("%d", action.Attempts+1), Inline: true},
+			{Name: "Last Error", Value: truncateLogField(err.Error()), Inline: false},
+		},
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	if action.CaseNumber > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Case",
+			Value:  fmt.Sprintf("#%d", action.CaseNumber),
+			Inline: true,
+		})
+	}
+	b.LogDelivery.Send(action.GuildID, config.ChannelFor(commands.LogModActions), embed)
+}
+
+// ScheduleAction persists an action for the worker
+func (b *Bot) ScheduleAction(action *commands.ScheduledAction) error {
+	if err := b.DB.AddScheduledAction(action); err != nil {
+		return err
+	}
+	DebugLog("Scheduled %s for %s in %s at %s", action.Action, action.UserID, action.GuildID, action.ExecuteAt.Format(time.RFC3339))
+	return nil
+}
+
+// CancelScheduledActions drops pending actions of a type for a user
+func (b *Bot) CancelScheduledActions(guildID, userID, action string) (int, error) {
+	return b.DB.DeleteScheduledActions(guildID, userID, action)
+}
+
+// GetMuteRole returns the guild's mute role, or "" if none is set
+func (b *Bot) GetMuteRole(guildID string) string {
+	return b.DB.GetMuteRole(guildID)
+}
+
+// SetMuteRole stores the guild's mute role
+func (b *Bot) SetMuteRole(guildID, roleID string) error {
+	return b.DB.SetMuteRole(guildID, roleID)
+}
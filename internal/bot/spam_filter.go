@@ -1,25 +1,28 @@
 package bot
 
 import (
+	"fmt"
 	"log"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
 )
 
 // SpamFilter handles message filtering and auto-moderation
 type SpamFilter struct {
-	bot     *Bot
+	bot         *Bot
 	permChecker *PermissionChecker
+	tracker     *SpamTracker
 }
 
 // NewSpamFilter creates a new spam filter
 func NewSpamFilter(b *Bot) *SpamFilter {
 	return &SpamFilter{
 		bot:         b,
-		permChecker: NewPermissionChecker(b.Session),
+		permChecker: NewPermissionChecker(b),
+		tracker:     NewSpamTracker(),
 	}
 }
 
@@ -30,6 +33,11 @@ const (
 	ActionWarn   FilterAction = "warn"
 	ActionBan    FilterAction = "ban"
 	ActionDelete FilterAction = "delete"
+	// Used by the spam detectors' escalation ladder
+	ActionTimeout FilterAction = "timeout"
+	ActionKick    FilterAction = "kick"
+	// Delete with a short-lived explanation, for channel content policies
+	ActionNotice FilterAction = "notice"
 )
 
 // FilterResult contains the result of filtering a message
@@ -38,6 +46,18 @@ type FilterResult struct {
 	Action           FilterAction
 	Reason           string
 	RuleID           int
+	Duration         time.Duration       // Timeout length
+	Detector         string              // Spam detector that fired, if any
+	AutoBanRule      string              // Auto-ban rule that fired, if any
+	Related          map[string][]string // Earlier spam messages to delete, by channel
+}
+
+// addRelated queues another message for deletion with the one that triggered
+func (r *FilterResult) addRelated(channelID, messageID string) {
+	if r.Related == nil {
+		r.Related = make(map[string][]string)
+	}
+	r.Related[channelID] = append(r.Related[channelID], messageID)
 }
 
 // CheckMessage checks a message against all filters
@@ -47,172 +67,262 @@ func (sf *SpamFilter) CheckMessage(m *discordgo.MessageCreate) *FilterResult {
 	if m.GuildID == "" || m.Author.Bot {
 		return nil
 	}
+	if !sf.bot.GetSpamFilterSettings(m.GuildID).Enabled {
+		return nil
+	}
 
 	// Check @everyone and @here mentions
 	if result := sf.checkMentions(m); result != nil {
 		return result
 	}
 
-	// Check guild-wide regex filters
+	// Check invites and blocked domains
+	if result := sf.checkLinks(m); result != nil {
+		return result
+	}
+
+	// Check guild-wide and channel-specific regex filters
 	if result := sf.checkRegexFilters(m); result != nil {
 		return result
 	}
 
-	// Check channel-specific regex filters
-	if result := sf.checkChannelFilters(m); result != nil {
+	// Check media-only, links-only and other channel policies
+	if result := sf.checkChannelPolicy(m); result != nil {
+		return result
+	}
+
+	// Check message-rate and content spam detectors
+	if result := sf.checkSpamDetectors(m); result != nil {
 		return result
 	}
 
 	return nil
 }
 
+// checkSpamDetectors runs the flood, duplicate, mention, emoji, caps and newline detectors
+func (sf *SpamFilter) checkSpamDetectors(m *discordgo.MessageCreate) *FilterResult {
+	result := sf.tracker.Check(m, sf.bot.GetAntiSpamSettings(m.GuildID))
+	if result == nil {
+		return nil
+	}
+
+	// Moderators are only looked up once something fires
+	if sf.permChecker.IsBotOwner(m.Author.ID) ||
+		sf.permChecker.HasPermission(m.GuildID, m.Author.ID, discordgo.PermissionManageMessages) {
+		DebugLog("User %s is exempt from spam detection", m.Author.ID)
+		sf.tracker.Forget(m.GuildID, m.Author.ID)
+		return nil
+	}
+
+	DebugLog("Spam detector %s fired for %s: %s", result.Detector, m.Author.ID, result.Reason)
+	return result
+}
+
 // checkMentions checks for @everyone and @here mentions
 func (sf *SpamFilter) checkMentions(m *discordgo.MessageCreate) *FilterResult {
 	// Check if exempt role
-	if sf.permChecker.HasExemptRole(m.GuildID, m.Author.ID, Global.SpamFilter.ExemptRolesFromMentionBan) {
+	if sf.permChecker.HasExemptRole(m.GuildID, m.Author.ID, sf.bot.GetSpamFilterSettings(m.GuildID).ExemptRolesFromMentionBan) {
 		DebugLog("User %s has exempt role, skipping mention check", m.Author.ID)
 		return nil
 	}
 
 	// Check @everyone
-	if Global.SpamFilter.AutoBanOnEveryoneMention {
-		if strings.Contains(m.Content, "@everyone") || m.MentionEveryone {
+	if strings.Contains(m.Content, "@everyone") || m.MentionEveryone {
+		if result := sf.bot.autoBanResult(m.GuildID, commands.AutoBanEveryone, "Unauthorized @everyone mention"); result != nil {
 			DebugLog("Detected @everyone mention from %s", m.Author.ID)
-			return &FilterResult{
-				ShouldTakeAction: true,
-				Action:           ActionBan,
-				Reason:           "Unauthorized @everyone mention",
-			}
+			return result
 		}
 	}
 
-	// Check @here
-	if Global.SpamFilter.AutoBanOnHereMention {
-		// Discord doesn't have a MentionHere field, so check content
-		if strings.Contains(m.Content, "@here") {
+	// Check @here; Discord doesn't have a MentionHere field, so check content
+	if strings.Contains(m.Content, "@here") {
+		if result := sf.bot.autoBanResult(m.GuildID, commands.AutoBanHere, "Unauthorized @here mention"); result != nil {
 			DebugLog("Detected @here mention from %s", m.Author.ID)
-			return &FilterResult{
-				ShouldTakeAction: true,
-				Action:           ActionBan,
-				Reason:           "Unauthorized @here mention",
-			}
+			return result
 		}
 	}
 
 	return nil
 }
 
-// checkRegexFilters checks message against guild-wide regex filters
+// checkRegexFilters checks message against guild-wide, then channel-specific, regex filters
 func (sf *SpamFilter) checkRegexFilters(m *discordgo.MessageCreate) *FilterResult {
-	rows, err := sf.bot.DB.Query(`
-		SELECT id, pattern, action, reason
-		FROM regex_filters
-		WHERE guild_id = ? AND enabled = 1
-	`, m.GuildID)
-
-	if err != nil {
-		DebugLog("Error querying regex filters: %v", err)
+	filter := sf.bot.FilterEngine.Match(m.GuildID, m.ChannelID, m.Content)
+	if filter == nil {
 		return nil
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var id int
-		var pattern, action, reason string
-
-		if err := rows.Scan(&id, &pattern, &action, &reason); err != nil {
-			continue
-		}
-
-		re, err := regexp.Compile(pattern)
-		if err != nil {
-			log.Printf("Invalid regex pattern in filter %d: %v", id, err)
-			continue
-		}
 
-		if re.MatchString(m.Content) {
-			DebugLog("Message matched regex filter %d: %s", id, pattern)
-			return &FilterResult{
-				ShouldTakeAction: true,
-				Action:           FilterAction(action),
-				Reason:           reason,
-				RuleID:           id,
-			}
-		}
+	DebugLog("Message matched regex filter %d: %s", filter.ID, filter.Pattern)
+	return &FilterResult{
+		ShouldTakeAction: true,
+		Action:           FilterAction(filter.Action),
+		Reason:           filter.Reason,
+		RuleID:           filter.ID,
 	}
-
-	return nil
 }
 
-// checkChannelFilters checks message against channel-specific regex filters
-func (sf *SpamFilter) checkChannelFilters(m *discordgo.MessageCreate) *FilterResult {
-	rows, err := sf.bot.DB.Query(`
-		SELECT id, pattern, action, reason
-		FROM channel_regex_filters
-		WHERE guild_id = ? AND channel_id = ? AND enabled = 1
-	`, m.GuildID, m.ChannelID)
+// TestFilters reports every rule that would act on a message from a regular
+// member, without acting or counting anything
+func (b *Bot) TestFilters(guildID, channelID, content string) []commands.FilterMatch {
+	sf := b.SpamFilter
+	m := &discordgo.MessageCreate{Message: &discordgo.Message{
+		GuildID:   guildID,
+		ChannelID: channelID,
+		Content:   content,
+		Author:    &discordgo.User{},
+	}}
+
+	var matches []commands.FilterMatch
+	add := func(rule string, result *FilterResult) {
+		if result != nil {
+			matches = append(matches, commands.FilterMatch{Rule: rule, Action: string(result.Action), Reason: result.Reason})
+		}
+	}
 
-	if err != nil {
-		DebugLog("Error querying channel filters: %v", err)
-		return nil
+	if strings.Contains(content, "@everyone") {
+		add("@everyone mention", b.autoBanResult(guildID, commands.AutoBanEveryone, "Unauthorized @everyone mention"))
+	}
+	if strings.Contains(content, "@here") {
+		add("@here mention", b.autoBanResult(guildID, commands.AutoBanHere, "Unauthorized @here mention"))
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var id int
-		var pattern, action, reason string
+	links := b.loadLinkFilter(guildID)
+	if links.settings.Enabled {
+		add("Invite filter", sf.checkInvites(m, links.settings))
+		withoutInvites := inviteRegex.ReplaceAllString(content, "")
+		add("Link filter", sf.checkDomains(m, withoutInvites, links.settings, links.rules))
+	}
 
-		if err := rows.Scan(&id, &pattern, &action, &reason); err != nil {
-			continue
+	for _, filter := range b.FilterEngine.MatchAll(guildID, channelID, content) {
+		rule := fmt.Sprintf("Regex filter #%d", filter.ID)
+		if filter.ChannelID != "" {
+			rule = fmt.Sprintf("Channel filter #%d", filter.ID)
 		}
+		add(rule+" `"+filter.Pattern+"`", &FilterResult{Action: FilterAction(filter.Action), Reason: filter.Reason})
+	}
 
-		re, err := regexp.Compile(pattern)
-		if err != nil {
-			log.Printf("Invalid regex pattern in channel filter %d: %v", id, err)
-			continue
-		}
+	add("Channel policy", sf.channelPolicyResult(m))
 
-		if re.MatchString(m.Content) {
-			DebugLog("Message matched channel filter %d: %s", id, pattern)
-			return &FilterResult{
-				ShouldTakeAction: true,
-				Action:           FilterAction(action),
-				Reason:           reason,
-				RuleID:           id,
-			}
-		}
+	if reason, detector := checkMessageContent(content, b.GetAntiSpamSettings(guildID)); detector != "" {
+		add("Spam detector: "+detector, &FilterResult{Action: ActionDelete, Reason: reason + " (action depends on strikes)"})
 	}
 
-	return nil
+	return matches
+}
+
+// deleteOffending removes the message that triggered an action. Slash
+// commands act through a stand-in message with no ID, so there's nothing
+// to delete.
+func (sf *SpamFilter) deleteOffending(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.ID == "" {
+		return
+	}
+	s.ChannelMessageDelete(m.ChannelID, m.ID)
 }
 
 // ExecuteAction executes the filter action
 func (sf *SpamFilter) ExecuteAction(s *discordgo.Session, m *discordgo.MessageCreate, result *FilterResult) {
 	defer RecoverFromPanic("SpamFilter.ExecuteAction")
 
-	// Log violation
-	sf.logViolation(m.GuildID, m.Author.ID, string(result.Action), result.Reason, "")
+	// In observe mode, auto-ban rules only say what they would have done
+	if sf.bot.observing(m.GuildID, result) {
+		sf.bot.reportObserved(m, result)
+		return
+	}
+
+	// Log violation; policy notices aren't misbehaviour
+	if result.Action != ActionNotice {
+		sf.logViolation(m.GuildID, m.Author.ID, string(result.Action), result.Reason, "")
+	}
+
+	lifetime := time.Duration(sf.bot.GetSpamFilterSettings(m.GuildID).WarningLifetime) * time.Second
+
+	// Clean up the rest of a spam burst
+	for channelID, messageIDs := range result.Related {
+		if len(messageIDs) == 1 {
+			s.ChannelMessageDelete(channelID, messageIDs[0])
+		} else if err := s.ChannelMessagesBulkDelete(channelID, messageIDs); err != nil {
+			DebugLog("Failed to bulk delete spam in %s: %v", channelID, err)
+		}
+	}
 
 	switch result.Action {
 	case ActionDelete:
-		s.ChannelMessageDelete(m.ChannelID, m.ID)
+		sf.deleteOffending(s, m)
 		DebugLog("Deleted message from %s: %s", m.Author.ID, result.Reason)
 
+	case ActionNotice:
+		sf.deleteOffending(s, m)
+		notice, err := s.ChannelMessageSend(m.ChannelID, m.Author.Mention()+" 🚫 Sorry, "+result.Reason)
+		if err == nil && lifetime > 0 {
+			time.AfterFunc(lifetime, func() {
+				s.ChannelMessageDelete(m.ChannelID, notice.ID)
+			})
+		}
+
 	case ActionWarn:
-		s.ChannelMessageDelete(m.ChannelID, m.ID)
+		sf.deleteOffending(s, m)
 		warning, err := s.ChannelMessageSend(m.ChannelID,
 			m.Author.Mention()+" ⚠️ Warning: "+result.Reason)
-		if err == nil && Global.SpamFilter.WarningLifetime > 0 {
+		if err == nil && lifetime > 0 {
 			// Delete warning after configured time
-			time.AfterFunc(time.Duration(Global.SpamFilter.WarningLifetime)*time.Second, func() {
+			time.AfterFunc(lifetime, func() {
 				s.ChannelMessageDelete(m.ChannelID, warning.ID)
 			})
 		}
 		log.Printf("⚠️  Warned user %s: %s", m.Author.Username, result.Reason)
 
+		sf.bot.RecordModCase(&commands.ModCase{
+			GuildID:     m.GuildID,
+			Action:      commands.CaseWarn,
+			UserID:      m.Author.ID,
+			ModeratorID: s.State.User.ID,
+			Reason:      "[Auto] " + result.Reason,
+		})
+
+	case ActionTimeout:
+		sf.deleteOffending(s, m)
+
+		until := time.Now().Add(result.Duration)
+		err := s.GuildMemberTimeout(m.GuildID, m.Author.ID, &until, discordgo.WithAuditLogReason(result.Reason))
+		if err != nil {
+			log.Printf("Failed to time out %s: %v", m.Author.ID, err)
+			break
+		}
+		log.Printf("🔇 Timed out user %s for %s: %s", m.Author.Username, result.Duration, result.Reason)
+
+		sf.bot.RecordModCase(&commands.ModCase{
+			GuildID:     m.GuildID,
+			Action:      commands.CaseTimeout,
+			UserID:      m.Author.ID,
+			ModeratorID: s.State.User.ID,
+			Reason:      "[Auto] " + result.Reason,
+			Duration:    result.Duration,
+		})
+
+	case ActionKick:
+		sf.deleteOffending(s, m)
+
+		if sf.bot.blockProtected(m.GuildID, m.Author.ID, commands.CaseKick, s.State.User.ID) {
+			break
+		}
+		if err := s.GuildMemberDeleteWithReason(m.GuildID, m.Author.ID, result.Reason); err != nil {
+			log.Printf("Failed to kick %s: %v", m.Author.ID, err)
+			break
+		}
+		log.Printf("👢 Kicked user %s: %s", m.Author.Username, result.Reason)
+
+		sf.bot.RecordModCase(&commands.ModCase{
+			GuildID:     m.GuildID,
+			Action:      commands.CaseKick,
+			UserID:      m.Author.ID,
+			ModeratorID: s.State.User.ID,
+			Reason:      "[Auto] " + result.Reason,
+		})
+
 	case ActionBan:
 		// Delete the message first
-		s.ChannelMessageDelete(m.ChannelID, m.ID)
+		sf.deleteOffending(s, m)
 
 		// Ban the user
 		err := sf.permChecker.AutoBanViolator(m.GuildID, m.Author.ID, result.Reason)
@@ -239,17 +349,12 @@ func (sf *SpamFilter) logViolation(guildID, userID, violationType, reason, moder
 	}
 }
 
-// CheckCommandAuthorization checks if a user is authorized for a moderation command
-// Returns shouldBan, reason
-func (sf *SpamFilter) CheckCommandAuthorization(guildID, userID, targetID, commandName string) (bool, string) {
+// CheckCommandAuthorization checks if a user is authorized for a moderation command.
+// Returns what the matching auto-ban rule does, or nil if they're allowed
+func (sf *SpamFilter) CheckCommandAuthorization(guildID, userID, targetID, commandName string) *FilterResult {
 	// Bot owners always authorized
 	if sf.permChecker.IsBotOwner(userID) {
-		return false, ""
-	}
-
-	// Check if auto-ban on unauthorized commands is enabled
-	if !Global.SpamFilter.AutoBanOnUnauthorizedCommands {
-		return false, ""
+		return nil
 	}
 
 	// Check if user has required permission
@@ -260,17 +365,17 @@ func (sf *SpamFilter) CheckCommandAuthorization(guildID, userID, targetID, comma
 
 	if !sf.permChecker.HasPermission(guildID, userID, requiredPerm) {
 		DebugLog("User %s tried to use %s without permission", userID, commandName)
-		return true, "Attempting to use moderation commands without permission"
+		return sf.bot.autoBanResult(guildID, commands.AutoBanUnauthorized, "Attempting to use moderation commands without permission")
 	}
 
 	// If target provided, check hierarchy violation
-	if targetID != "" && Global.SpamFilter.AutoBanOnHierarchyViolation {
+	if targetID != "" {
 		canModerate, reason := sf.permChecker.CanModerate(guildID, userID, targetID)
 		if !canModerate {
 			DebugLog("User %s hierarchy violation: %s", userID, reason)
-			return true, "Attempting to moderate higher-ranked user: " + reason
+			return sf.bot.autoBanResult(guildID, commands.AutoBanHierarchy, "Attempting to moderate higher-ranked user: "+reason)
 		}
 	}
 
-	return false, ""
+	return nil
 }
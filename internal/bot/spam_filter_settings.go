@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"database/sql"
+	"log"
+
+	"yuno-go/internal/commands"
+)
+
+// ============================================================================
+// SPAM FILTER SETTINGS - Per-guild [spam_filter], checked on every message
+// ============================================================================
+
+// defaultSpamFilterSettings copies [spam_filter] from config.toml
+func defaultSpamFilterSettings(guildID string) *commands.SpamFilterSettings {
+	cfg := Global.SpamFilter
+	return &commands.SpamFilterSettings{
+		GuildID:                       guildID,
+		Enabled:                       Global.Features.SpamFilterEnabled,
+		MainChannelPrefix:             cfg.MainChannelPrefix,
+		NSFWChannelPrefix:             cfg.NSFWChannelPrefix,
+		AllowInvites:                  cfg.AllowInvites,
+		MaxConsecutiveMessages:        cfg.MaxConsecutiveMessages,
+		WarningLifetime:               cfg.WarningLifetime,
+		AutoBanOnUnauthorizedCommands: cfg.AutoBanOnUnauthorizedCommands,
+		AutoBanOnHierarchyViolation:   cfg.AutoBanOnHierarchyViolation,
+		AllowSameRoleModeration:       cfg.AllowSameRoleModeration,
+		AutoBanOnEveryoneMention:      cfg.AutoBanOnEveryoneMention,
+		AutoBanOnHereMention:          cfg.AutoBanOnHereMention,
+		ExemptRolesFromMentionBan:     append([]string(nil), cfg.ExemptRolesFromMentionBan...),
+	}
+}
+
+// GetSpamFilterSettings returns a guild's spam filter settings, or the
+// config.toml defaults if it has none
+func (b *Bot) GetSpamFilterSettings(guildID string) *commands.SpamFilterSettings {
+	if b.SpamConfigCache != nil {
+		if settings, ok := b.SpamConfigCache.Get(guildID); ok {
+			return copySpamFilterSettings(settings)
+		}
+	}
+
+	settings, err := b.DB.GetSpamFilterSettings(guildID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error loading spam filter settings for guild %s: %v", guildID, err)
+		}
+		settings = defaultSpamFilterSettings(guildID)
+	}
+
+	if b.SpamConfigCache != nil {
+		b.SpamConfigCache.Set(guildID, settings)
+	}
+	return copySpamFilterSettings(settings)
+}
+
+// copySpamFilterSettings copies the settings and their exempt role list
+func copySpamFilterSettings(settings *commands.SpamFilterSettings) *commands.SpamFilterSettings {
+	copied := *settings
+	copied.ExemptRolesFromMentionBan = append([]string(nil), settings.ExemptRolesFromMentionBan...)
+	return &copied
+}
+
+// SaveSpamFilterSettings stores a guild's spam filter settings
+func (b *Bot) SaveSpamFilterSettings(settings *commands.SpamFilterSettings) error {
+	if err := b.DB.SaveSpamFilterSettings(settings); err != nil {
+		return err
+	}
+	b.invalidateSpamFilterSettings(settings.GuildID)
+	return nil
+}
+
+// ResetSpamFilterSettings puts a guild back on the config.toml defaults
+func (b *Bot) ResetSpamFilterSettings(guildID string) error {
+	if err := b.DB.DeleteSpamFilterSettings(guildID); err != nil {
+		return err
+	}
+	b.invalidateSpamFilterSettings(guildID)
+	return nil
+}
+
+// invalidateSpamFilterSettings also drops the caches whose defaults come
+// from the spam filter settings
+func (b *Bot) invalidateSpamFilterSettings(guildID string) {
+	if b.SpamConfigCache != nil {
+		b.SpamConfigCache.Invalidate(guildID)
+	}
+	if b.AntiSpamCache != nil {
+		b.AntiSpamCache.Invalidate(guildID)
+	}
+	if b.AutoBanCache != nil {
+		b.AutoBanCache.Invalidate(guildID)
+	}
+	if b.LinkFilterCache != nil {
+		b.LinkFilterCache.Invalidate(guildID)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
 )
 
 // Terminal handles interactive terminal commands
@@ -543,12 +544,29 @@ func (t *Terminal) terminalBan(args []string) {
 		reason = strings.Join(args[2:], " ")
 	}
 
+	if t.bot.blockProtected(guildID, userID, commands.CaseBan, t.bot.Session.State.User.ID) {
+		fmt.Printf("🛡️ User %s is protected in server %s; not banned\n", userID, guildID)
+		return
+	}
+
 	err := t.bot.Session.GuildBanCreateWithReason(guildID, userID, reason, 0)
 	if err != nil {
 		fmt.Printf("Error banning user: %v\n", err)
 		return
 	}
 
+	modCase, _ := t.bot.RecordModCase(&commands.ModCase{
+		GuildID:     guildID,
+		Action:      commands.CaseBan,
+		UserID:      userID,
+		ModeratorID: t.bot.Session.State.User.ID,
+		Reason:      reason,
+	})
+
+	if modCase != nil {
+		fmt.Printf("✅ Banned user %s from server %s (Case #%d)\n", userID, guildID, modCase.CaseNumber)
+		return
+	}
 	fmt.Printf("✅ Banned user %s from server %s\n", userID, guildID)
 }
 
@@ -652,10 +670,21 @@ func (t *Terminal) importBans(args []string) {
 		if reason == "" {
 			reason = "Imported ban"
 		}
+		if t.bot.blockProtected(guildID, ban.UserID, commands.CaseBan, t.bot.Session.State.User.ID) {
+			fmt.Printf("  🛡️ Skipped protected user %s\n", ban.UserID)
+			continue
+		}
 		err := t.bot.Session.GuildBanCreateWithReason(guildID, ban.UserID, reason, 0)
 		if err != nil {
 			fmt.Printf("  ❌ Failed to ban %s: %v\n", ban.UserID, err)
 		} else {
+			t.bot.RecordModCase(&commands.ModCase{
+				GuildID:     guildID,
+				Action:      commands.CaseBan,
+				UserID:      ban.UserID,
+				ModeratorID: t.bot.Session.State.User.ID,
+				Reason:      reason,
+			})
 			success++
 		}
 	}
@@ -0,0 +1,259 @@
+package bot
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ============================================================================
+// BULK DELETE TRANSCRIPTS - Purges logged as text and HTML transcripts
+// ============================================================================
+
+const transcriptTimeFormat = "2006-01-02 15:04:05 MST"
+
+// onMessageDeleteBulk logs a purge as one entry with transcripts of whatever
+// was in the message cache
+func (b *Bot) onMessageDeleteBulk(s *discordgo.Session, m *discordgo.MessageDeleteBulk) {
+	defer RecoverFromPanic("onMessageDeleteBulk")
+
+	if m.GuildID == "" || len(m.Messages) == 0 {
+		return
+	}
+
+	config, err := b.GetLoggingConfigCached(m.GuildID)
+	if err != nil || !config.Enabled || !config.MessageDelete || config.ChannelFor("message_delete") == "" {
+		return
+	}
+
+	enabled, _ := b.IsChannelLoggingEnabled(m.GuildID, m.ChannelID, "message_delete")
+	if !enabled {
+		return
+	}
+
+	cached, err := b.GetCachedMessages(m.Messages)
+	if err != nil {
+		log.Printf("Failed to load cached messages for bulk delete in %s: %v", m.ChannelID, err)
+	}
+
+	t := newTranscript(s, m, cached)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Messages Bulk Deleted",
+		Description: "The full transcript is attached.",
+		Color:       0xe74c3c, // Red
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Channel",
+				Value:  fmt.Sprintf("<#%s>", m.ChannelID),
+				Inline: true,
+			},
+			{
+				Name:   "Messages",
+				Value:  fmt.Sprintf("%d (%d cached)", len(m.Messages), len(cached)),
+				Inline: true,
+			},
+		},
+		Timestamp: t.deletedAt.Format(time.RFC3339),
+	}
+
+	if authors := t.authorCounts(); authors != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Authors",
+			Value:  authors,
+			Inline: false,
+		})
+	}
+
+	name := fmt.Sprintf("purge-%s-%s", m.ChannelID, t.deletedAt.Format("20060102-150405"))
+	files := []logFile{
+		{Name: name + ".txt", ContentType: "text/plain; charset=utf-8", Data: []byte(t.Text())},
+		{Name: name + ".html", ContentType: "text/html; charset=utf-8", Data: []byte(t.HTML())},
+	}
+
+	b.LogDelivery.SendWithFiles(m.GuildID, config.ChannelFor("message_delete"), embed, files)
+}
+
+// transcript is a purge's messages in the order they were sent. Messages
+// that weren't cached are kept as a nil entry so the gaps show.
+type transcript struct {
+	guildName   string
+	channelName string
+	deletedAt   time.Time
+	ids         []string
+	messages    map[string]*discordgo.Message
+}
+
+func newTranscript(s *discordgo.Session, m *discordgo.MessageDeleteBulk, cached []*discordgo.Message) *transcript {
+	t := &transcript{
+		guildName:   m.GuildID,
+		channelName: m.ChannelID,
+		deletedAt:   time.Now().UTC(),
+		ids:         append([]string(nil), m.Messages...),
+		messages:    make(map[string]*discordgo.Message, len(cached)),
+	}
+
+	if guild, err := s.State.Guild(m.GuildID); err == nil {
+		t.guildName = guild.Name
+	}
+	if channel, err := s.State.Channel(m.ChannelID); err == nil {
+		t.channelName = channel.Name
+	}
+
+	for _, msg := range cached {
+		t.messages[msg.ID] = msg
+	}
+
+	// Snowflakes sort by time once they're the same length
+	sort.Slice(t.ids, func(i, j int) bool {
+		if len(t.ids[i]) != len(t.ids[j]) {
+			return len(t.ids[i]) < len(t.ids[j])
+		}
+		return t.ids[i] < t.ids[j]
+	})
+	return t
+}
+
+// authorCounts lists who wrote the purged messages, most first
+func (t *transcript) authorCounts() string {
+	counts := make(map[string]int)
+	for _, msg := range t.messages {
+		counts[msg.Author.ID]++
+	}
+
+	authors := make([]string, 0, len(counts))
+	for id := range counts {
+		authors = append(authors, id)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if counts[authors[i]] != counts[authors[j]] {
+			return counts[authors[i]] > counts[authors[j]]
+		}
+		return authors[i] < authors[j]
+	})
+
+	lines := make([]string, 0, len(authors))
+	for i, id := range authors {
+		if i == 10 {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(authors)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("<@%s> • %d", id, counts[id]))
+	}
+	return truncateLogField(strings.Join(lines, "\n"))
+}
+
+func (t *transcript) sentAt(id string) time.Time {
+	if msg := t.messages[id]; msg != nil && !msg.Timestamp.IsZero() {
+		return msg.Timestamp.UTC()
+	}
+	sent, err := discordgo.SnowflakeTimestamp(id)
+	if err != nil {
+		return time.Time{}
+	}
+	return sent.UTC()
+}
+
+func transcriptAuthor(msg *discordgo.Message) string {
+	if msg.Author.Username == "" {
+		return "Unknown user"
+	}
+	return msg.Author.String()
+}
+
+// Text renders the transcript as plain text
+func (t *transcript) Text() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Bulk delete in #%s (%s)\n", t.channelName, t.guildName)
+	fmt.Fprintf(&sb, "Deleted: %s\n", t.deletedAt.Format(transcriptTimeFormat))
+	fmt.Fprintf(&sb, "Messages: %d (%d cached)\n", len(t.ids), len(t.messages))
+
+	for _, id := range t.ids {
+		sb.WriteString("\n")
+		msg := t.messages[id]
+		if msg == nil {
+			fmt.Fprintf(&sb, "[%s] Message %s wasn't cached\n", t.sentAt(id).Format(transcriptTimeFormat), id)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "[%s] %s (%s)\n", t.sentAt(id).Format(transcriptTimeFormat), transcriptAuthor(msg), msg.Author.ID)
+		if ref := msg.MessageReference; ref != nil && ref.MessageID != "" {
+			fmt.Fprintf(&sb, "  Reply to message %s\n", ref.MessageID)
+		}
+		for _, line := range strings.Split(msg.Content, "\n") {
+			if line != "" {
+				fmt.Fprintf(&sb, "  %s\n", line)
+			}
+		}
+		for _, att := range msg.Attachments {
+			fmt.Fprintf(&sb, "  Attachment: %s (%s) %s\n", att.Filename, formatFileSize(att.Size), att.URL)
+		}
+		for _, sticker := range msg.StickerItems {
+			fmt.Fprintf(&sb, "  Sticker: %s\n", sticker.Name)
+		}
+		for _, e := range msg.Embeds {
+			fmt.Fprintf(&sb, "  Embed: %s\n", describeEmbeds([]*discordgo.MessageEmbed{e}))
+		}
+	}
+	return sb.String()
+}
+
+const transcriptStyle = `body{background:#313338;color:#dbdee1;font-family:"gg sans","Helvetica Neue",Helvetica,Arial,sans-serif;margin:0;padding:24px}
+h1{font-size:20px;margin:0 0 4px}
+.meta{color:#949ba4;font-size:13px;margin-bottom:24px}
+.message{padding:8px 0;border-top:1px solid #3f4147}
+.author{font-weight:600;color:#f2f3f5}
+.id,.time,.extra{color:#949ba4;font-size:12px}
+.content{white-space:pre-wrap;word-wrap:break-word;margin-top:4px}
+.missing{color:#949ba4;font-style:italic}
+a{color:#00a8fc}`
+
+// HTML renders the transcript as a standalone page
+func (t *transcript) HTML() string {
+	var sb strings.Builder
+	title := html.EscapeString(fmt.Sprintf("Bulk delete in #%s", t.channelName))
+
+	sb.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\">")
+	fmt.Fprintf(&sb, "<title>%s</title><style>%s</style></head><body>\n", title, transcriptStyle)
+	fmt.Fprintf(&sb, "<h1>%s</h1>\n", title)
+	fmt.Fprintf(&sb, "<div class=\"meta\">%s • Deleted %s • %d messages (%d cached)</div>\n",
+		html.EscapeString(t.guildName), t.deletedAt.Format(transcriptTimeFormat), len(t.ids), len(t.messages))
+
+	for _, id := range t.ids {
+		sent := t.sentAt(id).Format(transcriptTimeFormat)
+		msg := t.messages[id]
+		if msg == nil {
+			fmt.Fprintf(&sb, "<div class=\"message missing\">%s • Message %s wasn't cached</div>\n", sent, html.EscapeString(id))
+			continue
+		}
+
+		sb.WriteString("<div class=\"message\">")
+		fmt.Fprintf(&sb, "<span class=\"author\">%s</span> <span class=\"id\">%s</span> <span class=\"time\">%s</span>",
+			html.EscapeString(transcriptAuthor(msg)), msg.Author.ID, sent)
+		if ref := msg.MessageReference; ref != nil && ref.MessageID != "" {
+			fmt.Fprintf(&sb, "<div class=\"extra\">Reply to message %s</div>", html.EscapeString(ref.MessageID))
+		}
+		if msg.Content != "" {
+			fmt.Fprintf(&sb, "<div class=\"content\">%s</div>", html.EscapeString(msg.Content))
+		}
+		for _, att := range msg.Attachments {
+			fmt.Fprintf(&sb, "<div class=\"extra\">Attachment: <a href=\"%s\">%s</a> (%s)</div>",
+				html.EscapeString(att.URL), html.EscapeString(att.Filename), formatFileSize(att.Size))
+		}
+		for _, sticker := range msg.StickerItems {
+			fmt.Fprintf(&sb, "<div class=\"extra\">Sticker: %s</div>", html.EscapeString(sticker.Name))
+		}
+		for _, e := range msg.Embeds {
+			fmt.Fprintf(&sb, "<div class=\"extra\">Embed: %s</div>", html.EscapeString(describeEmbeds([]*discordgo.MessageEmbed{e})))
+		}
+		sb.WriteString("</div>\n")
+	}
+
+	sb.WriteString("</body></html>\n")
+	return sb.String()
+}
@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// TTL CACHE - Per-guild settings checked on hot paths
+// ============================================================================
+
+// ttlCache keeps a value per guild for a while so message handlers don't hit
+// the database. Values are shared by every caller; anything that hands them
+// to code that edits them has to copy them first.
+type ttlCache[T any] struct {
+	entries map[string]ttlEntry[T]
+	mu      sync.RWMutex
+	ttl     time.Duration
+}
+
+type ttlEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func newTTLCache[T any](ttl time.Duration) *ttlCache[T] {
+	return &ttlCache[T]{
+		entries: make(map[string]ttlEntry[T]),
+		ttl:     ttl,
+	}
+}
+
+func (c *ttlCache[T]) Get(guildID string) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.entries[guildID]
+	if !exists || time.Now().After(entry.expiresAt) {
+		var zero T
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache[T]) Set(guildID string, value T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[guildID] = ttlEntry[T]{
+		value:     value,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+func (c *ttlCache[T]) Invalidate(guildID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, guildID)
+}
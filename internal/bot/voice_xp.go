@@ -232,15 +232,25 @@ func (v *VoiceXPTracker) processXP() {
 				continue
 			}
 
+			// Apply channel and role multipliers; 0x channels and roles earn nothing
+			var roles []string
+			if member, err := v.bot.Session.State.Member(guildID, userID); err == nil {
+				roles = member.Roles
+			}
+			xp := applyXPMultiplier(xpRate, v.bot.xpMultiplier(guildID, session.ChannelID, roles))
+			session.LastXP = now
+			if xp <= 0 {
+				continue
+			}
+
 			// Grant XP using batcher (no channel for voice XP level-ups)
 			if v.bot.XPBatcher != nil {
-				v.bot.XPBatcher.AddXP(guildID, userID, "", xpRate)
+				v.bot.XPBatcher.AddVoiceXP(guildID, userID, xp)
 			} else {
-				go v.bot.giveXP(v.bot.Session, guildID, userID, "", xpRate)
+				go v.bot.giveXP(v.bot.Session, guildID, userID, "", xp)
 			}
-			session.LastXP = now
 
-			DebugLog("[Voice XP] Granted %d XP to %s in guild %s", xpRate, userID, guildID)
+			DebugLog("[Voice XP] Granted %d XP to %s in guild %s", xp, userID, guildID)
 		}
 	}
 }
@@ -0,0 +1,177 @@
+package bot
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
+)
+
+// defaultWarnSettings records points but only punishes once a guild opts in
+func defaultWarnSettings(guildID string) *commands.WarnSettings {
+	return &commands.WarnSettings{
+		GuildID:       guildID,
+		Enabled:       false,
+		PointsPerWarn: 1,
+		DecayDays:     30,
+		Thresholds: []commands.WarnThreshold{
+			{Points: 3, Step: commands.EscalationStep{Action: commands.EscalateTimeout, Duration: time.Hour}},
+			{Points: 5, Step: commands.EscalationStep{Action: commands.EscalateKick}},
+			{Points: 8, Step: commands.EscalationStep{Action: commands.EscalateBan}},
+		},
+	}
+}
+
+// GetWarnSettings returns a guild's warning point settings or the defaults
+func (b *Bot) GetWarnSettings(guildID string) *commands.WarnSettings {
+	settings, err := b.DB.GetWarnSettings(guildID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error loading warning settings for guild %s: %v", guildID, err)
+		}
+		return defaultWarnSettings(guildID)
+	}
+	return settings
+}
+
+// SaveWarnSettings stores a guild's warning point settings
+func (b *Bot) SaveWarnSettings(settings *commands.WarnSettings) error {
+	return b.DB.SaveWarnSettings(settings)
+}
+
+// GetActiveWarningPoints lists a member's unexpired warning points
+func (b *Bot) GetActiveWarningPoints(guildID, userID string) ([]commands.WarningPoint, error) {
+	return b.DB.GetActiveWarningPoints(guildID, userID, time.Now())
+}
+
+// ClearWarningPoints wipes a member's active points, returning how many there were
+func (b *Bot) ClearWarningPoints(guildID, userID string) (int, error) {
+	points, err := b.GetActiveWarningPoints(guildID, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := b.DB.ClearWarningPoints(guildID, userID); err != nil {
+		return 0, err
+	}
+	return commands.TotalPoints(points), nil
+}
+
+// addWarningPoints adds a warn case to the points ledger and punishes the
+// member if that takes them over a threshold
+func (b *Bot) addWarningPoints(modCase *commands.ModCase) {
+	settings := b.GetWarnSettings(modCase.GuildID)
+
+	active, err := b.GetActiveWarningPoints(modCase.GuildID, modCase.UserID)
+	if err != nil {
+		log.Printf("Failed to load warning points for %s: %v", modCase.UserID, err)
+		return
+	}
+	before := commands.TotalPoints(active)
+
+	point := &commands.WarningPoint{
+		GuildID:    modCase.GuildID,
+		UserID:     modCase.UserID,
+		Points:     settings.PointsPerWarn,
+		Reason:     modCase.Reason,
+		CaseNumber: modCase.CaseNumber,
+		CreatedAt:  modCase.CreatedAt,
+	}
+	if settings.DecayDays > 0 {
+		point.ExpiresAt = modCase.CreatedAt.AddDate(0, 0, settings.DecayDays)
+	}
+	if err := b.DB.AddWarningPoints(point); err != nil {
+		log.Printf("Failed to record warning points for %s: %v", modCase.UserID, err)
+		return
+	}
+
+	after := before + point.Points
+	DebugLog("User %s has %d warning points in guild %s", modCase.UserID, after, modCase.GuildID)
+
+	threshold, withheld := b.WarnThresholdFor(modCase.GuildID, modCase.ModeratorID, before, after)
+	if withheld != nil {
+		DebugLog("Withheld %s for %s: moderator %s can't %s", withheld.Step, modCase.UserID, modCase.ModeratorID, withheld.Step.Action)
+	}
+	if threshold != nil {
+		b.applyWarnThreshold(modCase.GuildID, modCase.UserID, *threshold, after)
+	}
+}
+
+// WarnThresholdFor picks the threshold a warning from moderatorID applies when
+// it takes a member from before to after points. A manual warn only kicks or
+// bans if the moderator could do that themselves; otherwise it stops at the
+// strongest timeout reached and returns the kick or ban it held back.
+func (b *Bot) WarnThresholdFor(guildID, moderatorID string, before, after int) (apply, withheld *commands.WarnThreshold) {
+	settings := b.GetWarnSettings(guildID)
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	crossed := commands.CrossedThreshold(settings.Thresholds, before, after)
+	if crossed == nil || b.canEscalate(guildID, moderatorID, crossed.Step.Action) {
+		return crossed, nil
+	}
+	return commands.TimeoutThreshold(settings.Thresholds, after), crossed
+}
+
+// canEscalate reports whether a warning from moderatorID may apply a
+// threshold's action. Automatic warnings come from the bot and always may.
+func (b *Bot) canEscalate(guildID, moderatorID, action string) bool {
+	var perm int64
+	switch action {
+	case commands.EscalateKick:
+		perm = discordgo.PermissionKickMembers
+	case commands.EscalateBan:
+		perm = discordgo.PermissionBanMembers
+	default:
+		return true
+	}
+
+	if moderatorID == b.Session.State.User.ID || b.PermChecker.IsBotOwner(moderatorID) {
+		return true
+	}
+	return b.PermChecker.HasPermission(guildID, moderatorID, perm)
+}
+
+// applyWarnThreshold times out, kicks or bans a member who reached a threshold
+func (b *Bot) applyWarnThreshold(guildID, userID string, threshold commands.WarnThreshold, points int) {
+	reason := fmt.Sprintf("Reached %d warning points", points)
+	botID := b.Session.State.User.ID
+
+	modCase := &commands.ModCase{
+		GuildID:     guildID,
+		UserID:      userID,
+		ModeratorID: botID,
+		Reason:      "[Auto] " + reason,
+	}
+
+	if threshold.Step.Action != commands.EscalateTimeout && b.blockProtected(guildID, userID, threshold.Step.Action, botID) {
+		return
+	}
+
+	var err error
+	switch threshold.Step.Action {
+	case commands.EscalateTimeout:
+		until := time.Now().Add(threshold.Step.Duration)
+		err = b.Session.GuildMemberTimeout(guildID, userID, &until, discordgo.WithAuditLogReason(reason))
+		modCase.Action = commands.CaseTimeout
+		modCase.Duration = threshold.Step.Duration
+	case commands.EscalateKick:
+		err = b.Session.GuildMemberDeleteWithReason(guildID, userID, reason)
+		modCase.Action = commands.CaseKick
+	case commands.EscalateBan:
+		err = b.Session.GuildBanCreateWithReason(guildID, userID, reason, 0)
+		modCase.Action = commands.CaseBan
+	default:
+		return
+	}
+
+	if err != nil {
+		log.Printf("Failed to %s %s at %d warning points: %v", threshold.Step.Action, userID, points, err)
+		return
+	}
+	log.Printf("⚖️  Applied %s to %s at %d warning points", threshold.Step, userID, points)
+	b.RecordModCase(modCase)
+}
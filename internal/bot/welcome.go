@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/commands"
+)
+
+const defaultLeaveMessage = "**{tag}** has left {guild}. We're now {count} members."
+
+// defaultWelcomeConfig builds the settings for a guild that never configured welcomes
+func defaultWelcomeConfig(guildID string) *commands.WelcomeConfig {
+	return &commands.WelcomeConfig{
+		GuildID:        guildID,
+		Enabled:        Global.Features.WelcomeEnabledByDefault,
+		ChannelEnabled: Global.Welcome.ChannelEnabled,
+		DMEnabled:      Global.Welcome.DMEnabled,
+		Message:        Global.Welcome.DefaultMessage,
+		EmbedColor:     Global.Welcome.DefaultColor,
+		ImageURL:       Global.Welcome.EmbedImageURL,
+	}
+}
+
+// GetWelcomeConfig returns a guild's welcome settings with config.toml defaults filled in
+func (b *Bot) GetWelcomeConfig(guildID string) *commands.WelcomeConfig {
+	config, err := b.DB.GetWelcomeConfig(guildID)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error loading welcome config for guild %s: %v", guildID, err)
+		}
+		config = defaultWelcomeConfig(guildID)
+	}
+
+	if config.Message == "" {
+		config.Message = "Welcome {member} to {guild}!"
+	}
+	if config.LeaveMessage == "" {
+		config.LeaveMessage = defaultLeaveMessage
+	}
+	if config.EmbedColor == 0 {
+		config.EmbedColor = 16761035
+	}
+
+	return config
+}
+
+// SaveWelcomeConfig stores a guild's welcome settings
+func (b *Bot) SaveWelcomeConfig(config *commands.WelcomeConfig) error {
+	return b.DB.SaveWelcomeConfig(config)
+}
+
+// guildNameAndCount returns what the welcome templates need about a guild
+func (b *Bot) guildNameAndCount(guildID string) (*discordgo.Guild, int) {
+	if guild, err := b.Session.State.Guild(guildID); err == nil {
+		return guild, guild.MemberCount
+	}
+	if guild, err := b.Session.GuildWithCounts(guildID); err == nil {
+		return guild, guild.ApproximateMemberCount
+	}
+	return nil, 0
+}
+
+func (b *Bot) onMemberJoin(s *discordgo.Session, m *discordgo.GuildMemberAdd) {
+	defer RecoverFromPanic("onMemberJoin")
+
+	DebugLog("Member joined: %s in guild %s", m.User.String(), m.GuildID)
+
+	if Global.Debug.PrintRawEvents {
+		log.Printf("[RAW EVENT] GuildMemberAdd: %+v", m)
+	}
+
+	if m.User.Bot {
+		return
+	}
+
+	b.RaidMonitor.HandleJoin(m)
+
+	config := b.GetWelcomeConfig(m.GuildID)
+	if !config.Enabled {
+		return
+	}
+
+	guild, count := b.guildNameAndCount(m.GuildID)
+	if guild == nil {
+		return
+	}
+
+	msg := commands.BuildWelcomeMessage(config, false, m.User, guild.Name, count)
+
+	if config.ChannelEnabled {
+		channelID := config.ChannelID
+		if channelID == "" {
+			channelID = guild.SystemChannelID
+		}
+		if channelID != "" {
+			if _, err := s.ChannelMessageSendComplex(channelID, msg); err != nil {
+				DebugLog("Failed to send welcome message in %s: %v", channelID, err)
+			}
+		}
+	}
+
+	if config.DMEnabled {
+		dm, err := s.UserChannelCreate(m.User.ID)
+		if err != nil {
+			DebugLog("Failed to open DM with %s: %v", m.User.ID, err)
+			return
+		}
+
+		dmMsg := commands.BuildWelcomeMessage(config, false, m.User, guild.Name, count)
+		dmMsg.Content = ""
+		dmMsg.Embeds[0].Title = "Welcome to " + guild.Name + "! ♡"
+		if _, err := s.ChannelMessageSendComplex(dm.ID, dmMsg); err != nil {
+			DebugLog("Failed to send welcome DM to %s: %v", m.User.ID, err)
+		}
+	}
+}
+
+func (b *Bot) onMemberLeave(s *discordgo.Session, m *discordgo.GuildMemberRemove) {
+	defer RecoverFromPanic("onMemberLeave")
+
+	if m.User == nil || m.User.Bot {
+		return
+	}
+
+	DebugLog("Member left: %s from guild %s", m.User.String(), m.GuildID)
+
+	config := b.GetWelcomeConfig(m.GuildID)
+	if !config.LeaveEnabled {
+		return
+	}
+
+	guild, count := b.guildNameAndCount(m.GuildID)
+	if guild == nil {
+		return
+	}
+
+	channelID := config.LeaveChannelID
+	if channelID == "" && config.ChannelEnabled {
+		channelID = config.ChannelID
+	}
+	if channelID == "" {
+		channelID = guild.SystemChannelID
+	}
+	if channelID == "" {
+		return
+	}
+
+	msg := commands.BuildWelcomeMessage(config, true, m.User, guild.Name, count)
+	if _, err := s.ChannelMessageSendComplex(channelID, msg); err != nil {
+		DebugLog("Failed to send goodbye message in %s: %v", channelID, err)
+	}
+}
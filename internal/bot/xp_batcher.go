@@ -3,10 +3,10 @@ package bot
 import (
 	"fmt"
 	"log"
-	"math"
-	"strconv"
 	"sync"
 	"time"
+
+	"yuno-go/internal/leveling"
 )
 
 // ============================================================================
@@ -23,7 +23,9 @@ type batcherKey struct {
 type XPBatcher struct {
 	bot           *Bot
 	pending       map[batcherKey]*PendingXP
+	cooldowns     map[batcherKey]time.Time // When each user can earn message XP again
 	mu            sync.Mutex
+	inFlight      sync.WaitGroup // Batches flush handed to a goroutine
 	stopChan      chan struct{}
 	flushInterval time.Duration
 	maxBatchSize  int
@@ -35,6 +37,7 @@ type PendingXP struct {
 	UserID    string
 	ChannelID string // Last channel (for level-up message)
 	XP        int
+	VoiceXP   int // Part of XP earned in voice (the rest counts as text XP)
 	AddedAt   time.Time
 }
 
@@ -43,6 +46,7 @@ func NewXPBatcher(bot *Bot) *XPBatcher {
 	return &XPBatcher{
 		bot:           bot,
 		pending:       make(map[batcherKey]*PendingXP),
+		cooldowns:     make(map[batcherKey]time.Time),
 		stopChan:      make(chan struct{}),
 		flushInterval: 10 * time.Second,
 		maxBatchSize:  200,
@@ -60,20 +64,60 @@ func (xb *XPBatcher) Stop() {
 	xb.flush() // Final flush
 }
 
-// AddXP adds XP to the batch for a user
+// AddXP adds text XP to the batch for a user, without a cooldown
 func (xb *XPBatcher) AddXP(guildID, userID, channelID string, xp int) {
+	xb.mu.Lock()
+	xb.addLocked(batcherKey{guildID: guildID, userID: userID}, channelID, xp, false)
+	xb.mu.Unlock()
+}
+
+// AddVoiceXP adds XP earned in a voice channel to the batch for a user
+func (xb *XPBatcher) AddVoiceXP(guildID, userID string, xp int) {
+	xb.mu.Lock()
+	xb.addLocked(batcherKey{guildID: guildID, userID: userID}, "", xp, true)
+	xb.mu.Unlock()
+}
+
+// AddMessageXP adds XP unless the user earned message XP less than cooldown
+// ago. It reports whether the XP was added.
+func (xb *XPBatcher) AddMessageXP(guildID, userID, channelID string, xp int, cooldown time.Duration) bool {
 	key := batcherKey{guildID: guildID, userID: userID}
+	now := time.Now()
 
 	xb.mu.Lock()
+	defer xb.mu.Unlock()
+
+	if until, ok := xb.cooldowns[key]; ok && now.Before(until) {
+		return false
+	}
+	if cooldown > 0 {
+		xb.cooldowns[key] = now.Add(cooldown)
+	}
+
+	xb.addLocked(key, channelID, xp, false)
+	return true
+}
+
+// addLocked adds XP to the batch; the caller holds xb.mu
+func (xb *XPBatcher) addLocked(key batcherKey, channelID string, xp int, voice bool) {
+	voiceXP := 0
+	if voice {
+		voiceXP = xp
+	}
+
 	if existing, ok := xb.pending[key]; ok {
 		existing.XP += xp
-		existing.ChannelID = channelID // Update to latest channel
+		existing.VoiceXP += voiceXP
+		if channelID != "" {
+			existing.ChannelID = channelID // Update to latest channel
+		}
 	} else {
 		xb.pending[key] = &PendingXP{
-			GuildID:   guildID,
-			UserID:    userID,
+			GuildID:   key.guildID,
+			UserID:    key.userID,
 			ChannelID: channelID,
 			XP:        xp,
+			VoiceXP:   voiceXP,
 			AddedAt:   time.Now(),
 		}
 	}
@@ -82,7 +126,6 @@ func (xb *XPBatcher) AddXP(guildID, userID, channelID string, xp int) {
 	if len(xb.pending) >= xb.maxBatchSize {
 		xb.flushLocked()
 	}
-	xb.mu.Unlock()
 }
 
 func (xb *XPBatcher) run() {
@@ -105,7 +148,29 @@ func (xb *XPBatcher) flush() {
 	xb.mu.Unlock()
 }
 
+// flushSync writes pending XP before returning, after waiting for batches
+// flush already handed to a goroutine
+func (xb *XPBatcher) flushSync() {
+	xb.mu.Lock()
+	batch := xb.pending
+	xb.pending = make(map[batcherKey]*PendingXP)
+	xb.mu.Unlock()
+
+	xb.inFlight.Wait()
+	if len(batch) > 0 {
+		xb.processBatch(batch)
+	}
+}
+
 func (xb *XPBatcher) flushLocked() {
+	// Forget cooldowns that have run out
+	now := time.Now()
+	for key, until := range xb.cooldowns {
+		if now.After(until) {
+			delete(xb.cooldowns, key)
+		}
+	}
+
 	if len(xb.pending) == 0 {
 		return
 	}
@@ -115,12 +180,24 @@ func (xb *XPBatcher) flushLocked() {
 	xb.pending = make(map[batcherKey]*PendingXP)
 
 	// Process in background
-	go xb.processBatch(toProcess)
+	xb.inFlight.Add(1)
+	go func() {
+		defer xb.inFlight.Done()
+		xb.processBatch(toProcess)
+	}()
 }
 
 func (xb *XPBatcher) processBatch(batch map[batcherKey]*PendingXP) {
 	defer RecoverFromPanic("XPBatcher.processBatch")
 
+	// Resolve level curves before the transaction (cache misses hit the DB)
+	curves := make(map[string]leveling.Curve)
+	for key := range batch {
+		if _, ok := curves[key.guildID]; !ok {
+			curves[key.guildID] = xb.bot.LevelCurve(key.guildID)
+		}
+	}
+
 	tx, err := xb.bot.DB.Begin()
 	if err != nil {
 		log.Printf("[XP Batcher] Failed to begin transaction: %v", err)
@@ -136,7 +213,12 @@ func (xb *XPBatcher) processBatch(batch map[batcherKey]*PendingXP) {
 	}
 	defer selectStmt.Close()
 
-	insertStmt, err := tx.Prepare(`INSERT OR REPLACE INTO glevel (guild_id, user_id, exp, level, enabled) VALUES (?, ?, ?, ?, 'enabled')`)
+	insertStmt, err := tx.Prepare(`
+		INSERT INTO glevel (guild_id, user_id, exp, level, enabled, text_exp, voice_exp)
+		VALUES (?, ?, ?, ?, 'enabled', ?, ?)
+		ON CONFLICT(guild_id, user_id) DO UPDATE SET
+			exp = excluded.exp, level = excluded.level,
+			text_exp = text_exp + excluded.text_exp, voice_exp = voice_exp + excluded.voice_exp`)
 	if err != nil {
 		log.Printf("[XP Batcher] Failed to prepare insert: %v", err)
 		return
@@ -162,16 +244,16 @@ func (xb *XPBatcher) processBatch(batch map[batcherKey]*PendingXP) {
 		}
 
 		newXP := currentXP + p.XP
-		newLevel := int(math.Floor((math.Sqrt(1 + 8*float64(newXP)/50) - 1) / 2))
+		newLevel := curves[p.GuildID].LevelForExp(int64(newXP))
 
-		_, err = insertStmt.Exec(p.GuildID, p.UserID, newXP, newLevel)
+		_, err = insertStmt.Exec(p.GuildID, p.UserID, newXP, newLevel, p.XP-p.VoiceXP, p.VoiceXP)
 		if err != nil {
 			log.Printf("[XP Batcher] Failed to update XP for %s: %v", p.UserID, err)
 			continue
 		}
 
 		// Track level ups for notifications
-		if newLevel > level && p.ChannelID != "" {
+		if newLevel > level {
 			levelUps = append(levelUps, levelUpNotification{
 				GuildID:   p.GuildID,
 				UserID:    p.UserID,
@@ -206,14 +288,12 @@ type levelUpNotification struct {
 func (xb *XPBatcher) handleLevelUp(lu levelUpNotification) {
 	defer RecoverFromPanic(fmt.Sprintf("handleLevelUp(user=%s)", lu.UserID))
 
-	user, err := xb.bot.Session.User(lu.UserID)
-	if err == nil {
-		xb.bot.Session.ChannelMessageSend(lu.ChannelID,
-			user.Mention()+" just reached **Level "+strconv.Itoa(lu.NewLevel)+"**! ♡")
-	}
+	xb.bot.announceLevelUp(lu.GuildID, lu.UserID, lu.ChannelID, lu.NewLevel)
 
 	// Check for role rewards
-	xb.bot.checkLevelRoles(lu.GuildID, lu.UserID, lu.NewLevel)
+	if _, _, err := xb.bot.syncRankRoles(lu.GuildID, lu.UserID, lu.NewLevel); err != nil {
+		DebugLog("[XP Batcher] Failed to sync rank roles: %v", err)
+	}
 }
 
 // ============================================================================
@@ -0,0 +1,242 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// What happens to members who joined as part of a raid
+const (
+	RaidActionNone = "none"
+	RaidActionKick = "kick"
+	RaidActionBan  = "ban"
+)
+
+// RaidSettings configures join-raid detection for a guild
+type RaidSettings struct {
+	GuildID           string
+	Enabled           bool
+	JoinThreshold     int // Joins within WindowSeconds that count as a raid
+	WindowSeconds     int
+	AccountAgeDays    int // Accounts younger than this count towards age clusters (0 = off)
+	Action            string
+	Lockdown          bool // Deny @everyone from sending in text channels
+	RaiseVerification bool
+	DurationMinutes   int // How long the lockdown lasts before it lifts itself
+}
+
+// RaidStatus describes an ongoing raid lockdown
+type RaidStatus struct {
+	Active    bool
+	StartedAt time.Time
+	EndsAt    time.Time
+	Reason    string
+}
+
+// RaidStore reads anti-raid settings and starts or ends lockdowns
+type RaidStore interface {
+	GetRaidSettings(guildID string) *RaidSettings
+	SaveRaidSettings(settings *RaidSettings) error
+	GetRaidStatus(guildID string) RaidStatus
+	StartRaid(guildID, reason string) error
+	EndRaid(guildID, reason string) error
+}
+
+// AntiRaidCommand configures join-raid detection and controls lockdowns
+type AntiRaidCommand struct {
+	Raids RaidStore
+}
+
+func (c *AntiRaidCommand) Name() string        { return "anti-raid" }
+func (c *AntiRaidCommand) Aliases() []string   { return []string{"antiraid", "raid"} }
+func (c *AntiRaidCommand) Description() string { return "Detect join raids and lock the server down" }
+func (c *AntiRaidCommand) Usage() string {
+	return "anti-raid [on|off|threshold <joins> <seconds>|account-age <days>|action none|kick|ban|lockdown on|off|verification on|off|duration <time>|start [reason]|end]"
+}
+func (c *AntiRaidCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionAdministrator}
+}
+func (c *AntiRaidCommand) MasterOnly() bool { return false }
+
+func (c *AntiRaidCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	guildID := ctx.Message.GuildID
+	settings := c.Raids.GetRaidSettings(guildID)
+
+	if len(ctx.Args) == 0 {
+		return c.showStatus(ctx, settings)
+	}
+
+	subcommand := strings.ToLower(ctx.Args[0])
+	value := strings.TrimSpace(strings.Join(ctx.Args[1:], " "))
+	var result string
+
+	switch subcommand {
+	case "on", "off", "enable", "disable":
+		settings.Enabled, _ = parseToggle(subcommand)
+		result = "Anti-raid " + enabledWord(settings.Enabled)
+
+	case "threshold":
+		if len(ctx.Args) != 3 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "anti-raid threshold <joins> <seconds>`")
+			return nil
+		}
+		joins, err := strconv.Atoi(ctx.Args[1])
+		if err != nil || joins < 3 || joins > 500 {
+			ctx.Reply("❌ Joins must be between 3 and 500")
+			return nil
+		}
+		seconds, err := strconv.Atoi(ctx.Args[2])
+		if err != nil || seconds < 1 || seconds > 3600 {
+			ctx.Reply("❌ Seconds must be between 1 and 3600")
+			return nil
+		}
+		settings.JoinThreshold = joins
+		settings.WindowSeconds = seconds
+		result = fmt.Sprintf("A raid is %d joins within %d seconds", joins, seconds)
+
+	case "account-age", "age":
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 || days > 365 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "anti-raid account-age <days>` (0-365, 0 disables)")
+			return nil
+		}
+		settings.AccountAgeDays = days
+		if days == 0 {
+			result = "New-account clustering disabled"
+		} else {
+			result = fmt.Sprintf("Accounts younger than %d days count as new", days)
+		}
+
+	case "action":
+		action := strings.ToLower(value)
+		if action != RaidActionNone && action != RaidActionKick && action != RaidActionBan {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "anti-raid action none|kick|ban`")
+			return nil
+		}
+		settings.Action = action
+		result = "Raid members will be: " + raidActionLabel(action)
+
+	case "lockdown":
+		enabled, ok := parseToggle(value)
+		if !ok {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "anti-raid lockdown on|off`")
+			return nil
+		}
+		settings.Lockdown = enabled
+		result = "Channel lockdown on raid " + enabledWord(enabled)
+
+	case "verification":
+		enabled, ok := parseToggle(value)
+		if !ok {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "anti-raid verification on|off`")
+			return nil
+		}
+		settings.RaiseVerification = enabled
+		result = "Raising verification level on raid " + enabledWord(enabled)
+
+	case "duration":
+		duration, ok := parseDuration(value)
+		if !ok || duration < time.Minute || duration > 7*24*time.Hour {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "anti-raid duration <time>` (e.g. `15m`, `2h`; 1m-7d)")
+			return nil
+		}
+		settings.DurationMinutes = int(duration / time.Minute)
+		result = "Lockdowns lift after " + formatDuration(time.Duration(settings.DurationMinutes)*time.Minute)
+
+	case "start", "lockdown-now":
+		reason := value
+		if reason == "" {
+			reason = "Manual lockdown"
+		}
+		reason += " (by " + ctx.Message.Author.Username + ")"
+		if err := c.Raids.StartRaid(guildID, reason); err != nil {
+			ctx.Reply("❌ Failed to start lockdown: " + err.Error())
+			return err
+		}
+		_, err := ctx.Reply(fmt.Sprintf("🚨 Raid lockdown started. It lifts in %s, or use `%santi-raid end`.",
+			formatDuration(time.Duration(settings.DurationMinutes)*time.Minute), ctx.GetPrefix()))
+		return err
+
+	case "end", "stop", "lift":
+		if !c.Raids.GetRaidStatus(guildID).Active {
+			ctx.Reply("There's no raid lockdown right now")
+			return nil
+		}
+		if err := c.Raids.EndRaid(guildID, "Lifted by "+ctx.Message.Author.Username); err != nil {
+			ctx.Reply("❌ Failed to lift lockdown: " + err.Error())
+			return err
+		}
+		_, err := ctx.Reply("✅ Raid lockdown lifted; channels and verification level restored")
+		return err
+
+	default:
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	if err := c.Raids.SaveRaidSettings(settings); err != nil {
+		ctx.Reply("❌ Failed to save anti-raid settings: " + err.Error())
+		return err
+	}
+
+	_, err := ctx.Reply("✅ " + result)
+	return err
+}
+
+func (c *AntiRaidCommand) showStatus(ctx *Context, settings *RaidSettings) error {
+	status := c.Raids.GetRaidStatus(ctx.Message.GuildID)
+
+	raid := "None"
+	color := 0xFF51FF
+	if status.Active {
+		raid = fmt.Sprintf("🚨 Since <t:%d:R>, lifts <t:%d:R>\n%s",
+			status.StartedAt.Unix(), status.EndsAt.Unix(), status.Reason)
+		color = 0xFF0000
+	}
+
+	accountAge := "Off"
+	if settings.AccountAgeDays > 0 {
+		accountAge = fmt.Sprintf("Under %d days", settings.AccountAgeDays)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Anti-Raid",
+		Description: "A raid is detected when joins exceed the threshold, or when a cluster of joins share new-account ages, similar names or the same avatar.",
+		Color:       color,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Status", Value: onOff(settings.Enabled), Inline: true},
+			{Name: "Threshold", Value: fmt.Sprintf("%d joins / %ds", settings.JoinThreshold, settings.WindowSeconds), Inline: true},
+			{Name: "New Accounts", Value: accountAge, Inline: true},
+			{Name: "Raid Members", Value: raidActionLabel(settings.Action), Inline: true},
+			{Name: "Lockdown", Value: onOff(settings.Lockdown), Inline: true},
+			{Name: "Raise Verification", Value: onOff(settings.RaiseVerification), Inline: true},
+			{Name: "Lockdown Duration", Value: formatDuration(time.Duration(settings.DurationMinutes) * time.Minute), Inline: true},
+			{Name: "Current Raid", Value: raid, Inline: false},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
+
+func raidActionLabel(action string) string {
+	switch action {
+	case RaidActionKick:
+		return "Kicked"
+	case RaidActionBan:
+		return "Banned"
+	default:
+		return "Left alone"
+	}
+}
@@ -0,0 +1,282 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Spam detectors
+const (
+	DetectorFlood     = "flood"     // Messages within the window
+	DetectorDuplicate = "duplicate" // Identical messages within the window
+	DetectorMentions  = "mentions"  // User/role mentions within the window
+	DetectorEmoji     = "emoji"     // Emoji in one message
+	DetectorCaps      = "caps"      // Percentage of capital letters in one message
+	DetectorNewlines  = "newlines"  // Line breaks in one message
+)
+
+// SpamDetectorOrder lists the detectors in the order they're checked and shown
+var SpamDetectorOrder = []string{
+	DetectorFlood, DetectorDuplicate, DetectorMentions, DetectorEmoji, DetectorCaps, DetectorNewlines,
+}
+
+// Escalation step actions
+const (
+	EscalateDelete  = "delete"
+	EscalateWarn    = "warn"
+	EscalateTimeout = "timeout"
+	EscalateKick    = "kick"
+	EscalateBan     = "ban"
+)
+
+// SpamDetector is one detector's settings. WindowSeconds only applies to
+// detectors that count across messages.
+type SpamDetector struct {
+	Name          string
+	Enabled       bool
+	Threshold     int
+	WindowSeconds int
+}
+
+// Windowed reports whether the detector looks at several messages
+func (d *SpamDetector) Windowed() bool {
+	return d.Name == DetectorFlood || d.Name == DetectorDuplicate || d.Name == DetectorMentions
+}
+
+// EscalationStep is what happens on a given strike
+type EscalationStep struct {
+	Action   string
+	Duration time.Duration // Timeout length
+}
+
+func (s EscalationStep) String() string {
+	if s.Action == EscalateTimeout {
+		return s.Action + ":" + formatDuration(s.Duration)
+	}
+	return s.Action
+}
+
+// AntiSpamSettings holds a guild's spam detectors and escalation ladder
+type AntiSpamSettings struct {
+	GuildID    string
+	Detectors  map[string]*SpamDetector
+	Escalation []EscalationStep
+	// Strikes are forgotten after this long without a new one
+	StrikeResetMinutes int
+}
+
+// AntiSpamStore reads and writes anti-spam settings
+type AntiSpamStore interface {
+	GetAntiSpamSettings(guildID string) *AntiSpamSettings
+	SaveAntiSpamSettings(settings *AntiSpamSettings) error
+	ResetAntiSpamSettings(guildID string) error
+}
+
+// ParseEscalation reads steps like "delete warn timeout:10m ban"
+func ParseEscalation(args []string) ([]EscalationStep, error) {
+	var steps []EscalationStep
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+			if part == "" {
+				continue
+			}
+			action, value, _ := strings.Cut(part, ":")
+			step := EscalationStep{Action: action}
+			switch action {
+			case EscalateDelete, EscalateWarn, EscalateKick, EscalateBan:
+				if value != "" {
+					return nil, fmt.Errorf("%s doesn't take a duration", action)
+				}
+			case EscalateTimeout:
+				duration, ok := parseDuration(value)
+				if !ok || duration > maxTimeout {
+					return nil, fmt.Errorf("timeout needs a duration up to 28d, like timeout:10m")
+				}
+				step.Duration = duration
+			default:
+				return nil, fmt.Errorf("unknown step %q", part)
+			}
+			steps = append(steps, step)
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("no steps given")
+	}
+	if len(steps) > 10 {
+		return nil, fmt.Errorf("at most 10 steps")
+	}
+	return steps, nil
+}
+
+// FormatEscalation renders a ladder the way ParseEscalation reads it
+func FormatEscalation(steps []EscalationStep) string {
+	parts := make([]string, len(steps))
+	for i, step := range steps {
+		parts[i] = step.String()
+	}
+	return strings.Join(parts, " → ")
+}
+
+// AntiSpamCommand configures the message-rate spam detectors
+type AntiSpamCommand struct {
+	Settings AntiSpamStore
+}
+
+func (c *AntiSpamCommand) Name() string      { return "anti-spam" }
+func (c *AntiSpamCommand) Aliases() []string { return []string{"antispam", "spam-detectors"} }
+func (c *AntiSpamCommand) Description() string {
+	return "Configure flood, duplicate and mention spam detection"
+}
+func (c *AntiSpamCommand) Usage() string {
+	return "anti-spam [<detector> on|off|<threshold> [seconds]|escalation <steps...>|strike-reset <time>|reset]"
+}
+func (c *AntiSpamCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *AntiSpamCommand) MasterOnly() bool { return false }
+
+func (c *AntiSpamCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	guildID := ctx.Message.GuildID
+	settings := c.Settings.GetAntiSpamSettings(guildID)
+
+	if len(ctx.Args) == 0 {
+		return c.showStatus(ctx, settings)
+	}
+
+	subcommand := strings.ToLower(ctx.Args[0])
+	var result string
+
+	switch subcommand {
+	case "escalation", "ladder":
+		steps, err := ParseEscalation(ctx.Args[1:])
+		if err != nil {
+			ctx.Reply("❌ " + err.Error() + "\nExample: `" + ctx.GetPrefix() + "anti-spam escalation delete warn timeout:10m ban`")
+			return nil
+		}
+		settings.Escalation = steps
+		result = "Escalation set to " + FormatEscalation(steps)
+
+	case "strike-reset", "decay":
+		duration, ok := time.Duration(0), len(ctx.Args) == 2
+		if ok {
+			duration, ok = parseDuration(ctx.Args[1])
+		}
+		if !ok || duration < time.Minute || duration > 7*24*time.Hour {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "anti-spam strike-reset <time>` (1m-7d)")
+			return nil
+		}
+		settings.StrikeResetMinutes = int(duration / time.Minute)
+		result = "Strikes reset after " + formatDuration(time.Duration(settings.StrikeResetMinutes)*time.Minute) + " without spam"
+
+	case "reset", "defaults":
+		if err := c.Settings.ResetAntiSpamSettings(guildID); err != nil {
+			ctx.Reply("❌ Failed to reset anti-spam settings: " + err.Error())
+			return err
+		}
+		_, err := ctx.Reply("✅ Anti-spam settings reset to the defaults")
+		return err
+
+	default:
+		detector, ok := settings.Detectors[subcommand]
+		if !ok || len(ctx.Args) < 2 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`\nDetectors: " + strings.Join(SpamDetectorOrder, ", "))
+			return nil
+		}
+
+		if enabled, ok := parseToggle(ctx.Args[1]); ok {
+			detector.Enabled = enabled
+			result = fmt.Sprintf("%s detection %s", detector.Name, enabledWord(enabled))
+			break
+		}
+
+		threshold, err := strconv.Atoi(ctx.Args[1])
+		if err != nil || threshold < 1 || threshold > 1000 || (detector.Name == DetectorCaps && threshold > 100) {
+			ctx.Reply("❌ Threshold must be a number (caps is a percentage)")
+			return nil
+		}
+		if detector.Name == DetectorDuplicate && threshold < 2 {
+			ctx.Reply("❌ Duplicate detection needs a threshold of at least 2")
+			return nil
+		}
+		detector.Threshold = threshold
+		detector.Enabled = true
+
+		if len(ctx.Args) > 2 {
+			if !detector.Windowed() {
+				ctx.Reply(fmt.Sprintf("❌ %s is checked per message and has no window", detector.Name))
+				return nil
+			}
+			seconds, err := strconv.Atoi(ctx.Args[2])
+			if err != nil || seconds < 1 || seconds > 300 {
+				ctx.Reply("❌ The window must be between 1 and 300 seconds")
+				return nil
+			}
+			detector.WindowSeconds = seconds
+		}
+		result = fmt.Sprintf("%s detection: %s", detector.Name, describeDetector(detector))
+	}
+
+	if err := c.Settings.SaveAntiSpamSettings(settings); err != nil {
+		ctx.Reply("❌ Failed to save anti-spam settings: " + err.Error())
+		return err
+	}
+
+	_, err := ctx.Reply("✅ " + result)
+	return err
+}
+
+func (c *AntiSpamCommand) showStatus(ctx *Context, settings *AntiSpamSettings) error {
+	lines := make([]string, 0, len(SpamDetectorOrder))
+	for _, name := range SpamDetectorOrder {
+		detector := settings.Detectors[name]
+		status := "❌"
+		if detector.Enabled {
+			status = "✅"
+		}
+		lines = append(lines, fmt.Sprintf("%s **%s** — %s", status, name, describeDetector(detector)))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Anti-Spam Detectors",
+		Color: 0xFF51FF,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Detectors", Value: strings.Join(lines, "\n"), Inline: false},
+			{Name: "Escalation", Value: FormatEscalation(settings.Escalation), Inline: false},
+			{Name: "Strike Reset", Value: formatDuration(time.Duration(settings.StrikeResetMinutes) * time.Minute), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
+
+// describeDetector explains a detector's threshold in words
+func describeDetector(d *SpamDetector) string {
+	switch d.Name {
+	case DetectorFlood:
+		return fmt.Sprintf("%d messages in %ds", d.Threshold, d.WindowSeconds)
+	case DetectorDuplicate:
+		return fmt.Sprintf("%d identical messages in %ds", d.Threshold, d.WindowSeconds)
+	case DetectorMentions:
+		return fmt.Sprintf("%d mentions in %ds", d.Threshold, d.WindowSeconds)
+	case DetectorEmoji:
+		return fmt.Sprintf("%d emoji in one message", d.Threshold)
+	case DetectorCaps:
+		return fmt.Sprintf("%d%% capitals in one message", d.Threshold)
+	case DetectorNewlines:
+		return fmt.Sprintf("%d line breaks in one message", d.Threshold)
+	}
+	return strconv.Itoa(d.Threshold)
+}
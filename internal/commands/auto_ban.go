@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Auto-ban rules
+const (
+	AutoBanEveryone     = "everyone"     // @everyone mentions
+	AutoBanHere         = "here"         // @here mentions
+	AutoBanUnauthorized = "unauthorized" // ban/kick without the permission
+	AutoBanHierarchy    = "hierarchy"    // ban/kick on a higher-ranked member
+)
+
+// AutoBanRuleOrder lists the rules in the order they're shown
+var AutoBanRuleOrder = []string{
+	AutoBanEveryone, AutoBanHere, AutoBanUnauthorized, AutoBanHierarchy,
+}
+
+// AutoBanRule is what one rule does when it fires
+type AutoBanRule struct {
+	Name       string
+	Enabled    bool
+	Step       EscalationStep
+	Overridden bool // Set here rather than following the spam filter settings
+}
+
+// AutoBanSettings holds a guild's auto-ban rules and observe mode. In observe
+// mode the rules only report what they would have done.
+type AutoBanSettings struct {
+	GuildID string
+	Observe bool
+	Rules   map[string]*AutoBanRule
+}
+
+// AutoBanStore reads and writes auto-ban rule settings
+type AutoBanStore interface {
+	GetAutoBanSettings(guildID string) *AutoBanSettings
+	SaveAutoBanSettings(settings *AutoBanSettings) error
+}
+
+// AutoBanCommand sets observe mode and per-rule overrides for auto-bans
+type AutoBanCommand struct {
+	Settings AutoBanStore
+}
+
+func (c *AutoBanCommand) Name() string      { return "auto-ban" }
+func (c *AutoBanCommand) Aliases() []string { return []string{"autoban", "autoban-rules"} }
+func (c *AutoBanCommand) Description() string {
+	return "Configure the automatic ban rules, or only watch what they'd do"
+}
+func (c *AutoBanCommand) Usage() string {
+	return "auto-ban [observe on|off|<rule> on|off|<rule> action <ban|kick|timeout:1h|warn|delete>|<rule> reset]"
+}
+func (c *AutoBanCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionAdministrator}
+}
+func (c *AutoBanCommand) MasterOnly() bool { return false }
+
+func (c *AutoBanCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	settings := c.Settings.GetAutoBanSettings(ctx.Message.GuildID)
+
+	if len(ctx.Args) == 0 {
+		return c.showStatus(ctx, settings)
+	}
+	if len(ctx.Args) < 2 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	subcommand := strings.ToLower(ctx.Args[0])
+	var result string
+
+	if subcommand == "observe" || subcommand == "dry-run" {
+		observe, ok := parseToggle(ctx.Args[1])
+		if !ok {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "auto-ban observe on|off`")
+			return nil
+		}
+		settings.Observe = observe
+		result = "Observe mode " + enabledWord(observe)
+		if observe {
+			result += ": auto-ban rules will only report to the log channel"
+		}
+	} else {
+		rule, ok := settings.Rules[subcommand]
+		if !ok {
+			ctx.Reply("❌ Unknown rule. Rules: " + strings.Join(AutoBanRuleOrder, ", "))
+			return nil
+		}
+
+		switch value := strings.ToLower(ctx.Args[1]); value {
+		case "action":
+			if len(ctx.Args) != 3 {
+				ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "auto-ban " + rule.Name + " action <ban|kick|timeout:1h|warn|delete>`")
+				return nil
+			}
+			steps, err := ParseEscalation(ctx.Args[2:])
+			if err != nil {
+				ctx.Reply("❌ " + err.Error())
+				return nil
+			}
+			rule.Step = steps[0]
+			rule.Enabled, rule.Overridden = true, true
+			result = fmt.Sprintf("%s rule now does: %s", rule.Name, rule.Step)
+
+		case "reset", "default":
+			rule.Overridden = false
+			result = rule.Name + " rule follows the spam filter settings again"
+
+		default:
+			enabled, ok := parseToggle(value)
+			if !ok {
+				ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+				return nil
+			}
+			rule.Enabled, rule.Overridden = enabled, true
+			result = fmt.Sprintf("%s rule %s", rule.Name, enabledWord(enabled))
+		}
+	}
+
+	if err := c.Settings.SaveAutoBanSettings(settings); err != nil {
+		ctx.Reply("❌ Failed to save auto-ban settings: " + err.Error())
+		return err
+	}
+
+	_, err := ctx.Reply("✅ " + result)
+	return err
+}
+
+func (c *AutoBanCommand) showStatus(ctx *Context, settings *AutoBanSettings) error {
+	lines := make([]string, 0, len(AutoBanRuleOrder))
+	for _, name := range AutoBanRuleOrder {
+		rule := settings.Rules[name]
+		status := "❌"
+		if rule.Enabled {
+			status = "✅"
+		}
+		source := "set-spamfilter"
+		if rule.Overridden {
+			source = "this server"
+		}
+		lines = append(lines, fmt.Sprintf("%s **%s** — %s: %s · _%s_", status, name, describeAutoBanRule(name), rule.Step, source))
+	}
+
+	mode := "🔨 Enforcing"
+	if settings.Observe {
+		mode = "👁️ Observing — rules are logged, not acted on"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Auto-Ban Rules",
+		Color: 0xFF51FF,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Mode", Value: mode, Inline: false},
+			{Name: "Rules", Value: strings.Join(lines, "\n"), Inline: false},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
+
+// describeAutoBanRule explains what a rule catches
+func describeAutoBanRule(name string) string {
+	switch name {
+	case AutoBanEveryone:
+		return "@everyone mentions"
+	case AutoBanHere:
+		return "@here mentions"
+	case AutoBanUnauthorized:
+		return "ban/kick without permission"
+	case AutoBanHierarchy:
+		return "ban/kick on higher roles"
+	}
+	return name
+}
@@ -78,7 +78,7 @@ func (c *AutoCleanCommand) addAutoClean(ctx *Context) error {
 	// Usage: auto-clean add #channel hours [warning_minutes]
 	if len(ctx.Args) < 3 {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"❌ Usage: `auto-clean add #channel <hours> [warning_minutes]`")
+			"❌ Usage: `"+ctx.GetPrefix()+"auto-clean add #channel <hours> [warning_minutes]`")
 		return nil
 	}
 
@@ -283,7 +283,7 @@ func (c *SetCleanMessageCommand) Execute(ctx *Context) error {
 
 	if len(ctx.Message.MentionChannels) == 0 || len(ctx.Args) < 2 {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"❌ Usage: `set-clean-message #channel <message>`")
+			"❌ Usage: `"+ctx.GetPrefix()+"set-clean-message #channel <message>`")
 		return nil
 	}
 
@@ -343,7 +343,7 @@ func (c *SetCleanImageCommand) Execute(ctx *Context) error {
 
 	if len(ctx.Message.MentionChannels) == 0 || len(ctx.Args) < 2 {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"❌ Usage: `set-clean-image #channel <image_url>`")
+			"❌ Usage: `"+ctx.GetPrefix()+"set-clean-image #channel <image_url>`")
 		return nil
 	}
 
@@ -102,7 +102,10 @@ func (c *ExportBansCommand) Execute(ctx *Context) error {
 }
 
 // ImportBansCommand imports bans from a JSON file
-type ImportBansCommand struct{}
+type ImportBansCommand struct {
+	Cases      CaseStore
+	Protection ProtectionChecker
+}
 
 func (c *ImportBansCommand) Name() string        { return "importbans" }
 func (c *ImportBansCommand) Aliases() []string   { return []string{"import-bans", "bansimport"} }
@@ -112,6 +115,11 @@ func (c *ImportBansCommand) RequiredPermissions() []int64 {
 	return []int64{discordgo.PermissionBanMembers}
 }
 func (c *ImportBansCommand) MasterOnly() bool { return false }
+func (c *ImportBansCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "file", Description: "JSON file from exportbans", Type: ArgAttachment, Required: true},
+	}
+}
 
 func (c *ImportBansCommand) Execute(ctx *Context) error {
 	if ctx.Message == nil {
@@ -156,6 +164,7 @@ func (c *ImportBansCommand) Execute(ctx *Context) error {
 
 	imported := 0
 	skipped := 0
+	protected := 0
 	errors := 0
 
 	for _, entry := range entries {
@@ -170,6 +179,14 @@ func (c *ImportBansCommand) Execute(ctx *Context) error {
 		}
 		reason = fmt.Sprintf("[Import] %s | Imported by %s", reason, ctx.Message.Author.Username)
 
+		if c.Protection != nil {
+			if why := c.Protection.ProtectedReason(ctx.Message.GuildID, entry.UserID); why != "" {
+				recordCase(ctx, c.Cases, CaseBlocked, entry.UserID, "Blocked imported ban: "+why, 0)
+				protected++
+				continue
+			}
+		}
+
 		err := ctx.Session.GuildBanCreateWithReason(ctx.Message.GuildID, entry.UserID, reason, 0)
 		if err != nil {
 			// Check if already banned
@@ -180,6 +197,7 @@ func (c *ImportBansCommand) Execute(ctx *Context) error {
 			}
 			continue
 		}
+		recordCase(ctx, c.Cases, CaseBan, entry.UserID, reason, 0)
 		imported++
 	}
 
@@ -189,6 +207,7 @@ func (c *ImportBansCommand) Execute(ctx *Context) error {
 		Fields: []*discordgo.MessageEmbedField{
 			{Name: "Imported", Value: strconv.Itoa(imported), Inline: true},
 			{Name: "Skipped", Value: strconv.Itoa(skipped), Inline: true},
+			{Name: "Protected", Value: strconv.Itoa(protected), Inline: true},
 			{Name: "Errors", Value: strconv.Itoa(errors), Inline: true},
 		},
 	}
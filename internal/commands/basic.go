@@ -19,6 +19,8 @@ func (c *PingCommand) Usage() string       { return "ping" }
 func (c *PingCommand) RequiredPermissions() []int64 { return nil }
 func (c *PingCommand) MasterOnly() bool    { return false }
 
+func (c *PingCommand) Arguments() []Argument { return nil }
+
 func (c *PingCommand) Execute(ctx *Context) error {
 	msg := "🏓 Pong!"
 	
@@ -27,13 +29,10 @@ func (c *PingCommand) Execute(ctx *Context) error {
 		timestamp := ctx.Message.Timestamp
 		latency := time.Since(timestamp)
 		msg = fmt.Sprintf("🏓 Pong! Latency: %dms", latency.Milliseconds())
-		
-		_, err := ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, msg)
-		return err
 	}
 	
-	fmt.Println(msg)
-	return nil
+	_, err := ctx.Reply(msg)
+	return err
 }
 
 // StatsCommand shows bot statistics
@@ -45,6 +44,7 @@ func (c *StatsCommand) Description() string { return "Show bot statistics" }
 func (c *StatsCommand) Usage() string       { return "stats" }
 func (c *StatsCommand) RequiredPermissions() []int64 { return nil }
 func (c *StatsCommand) MasterOnly() bool    { return false }
+func (c *StatsCommand) Arguments() []Argument { return nil }
 
 func (c *StatsCommand) Execute(ctx *Context) error {
 	var m runtime.MemStats
@@ -80,16 +80,10 @@ func (c *StatsCommand) Execute(ctx *Context) error {
 			Value:  strconv.Itoa(guilds),
 			Inline: true,
 		})
-		
-		_, err := ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
-		return err
 	}
 	
-	// Terminal output
-	fmt.Printf("Memory: %.2f MB\n", float64(m.Alloc)/1024/1024)
-	fmt.Printf("Goroutines: %d\n", runtime.NumGoroutine())
-	fmt.Printf("Go Version: %s\n", runtime.Version())
-	return nil
+	_, err := ctx.ReplyEmbed(embed)
+	return err
 }
 
 // ShutdownCommand gracefully shuts down the bot
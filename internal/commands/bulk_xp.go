@@ -2,30 +2,20 @@ package commands
 
 import (
 	"fmt"
-	"math"
 	"strconv"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/leveling"
 )
 
-// XP calculation constants
-const levelDivisor = 50
-
-func calcLevel(exp int64) int {
-	return int((math.Sqrt(1+8*float64(exp)/levelDivisor) - 1) / 2)
-}
-
-func calcExpForLevel(level int) int64 {
-	return int64(levelDivisor * level * (level + 1) / 2)
-}
-
 // XPDatabase interface for XP operations
 type XPDatabase interface {
 	GetUserXP(guildID, userID string) (exp int64, level int, err error)
 	SetUserXP(guildID, userID string, exp int64, level int) error
 	AddUserXP(guildID, userID string, amount int64) error
 	GetGuildRanks(guildID string) ([]GuildRank, error)
+	LevelCurve(guildID string) leveling.Curve
 }
 
 // GuildRank represents a level-role mapping
@@ -113,6 +103,7 @@ func (c *MassAddXPCommand) Execute(ctx *Context) error {
 		return err
 	}
 
+	curve := c.DB.LevelCurve(ctx.Message.GuildID)
 	updated := 0
 	levelUps := 0
 
@@ -138,7 +129,7 @@ func (c *MassAddXPCommand) Execute(ctx *Context) error {
 
 		// Add XP
 		newExp := exp + amount
-		newLevel := calcLevel(newExp)
+		newLevel := curve.LevelForExp(newExp)
 
 		if err := c.DB.SetUserXP(ctx.Message.GuildID, member.User.ID, newExp, newLevel); err != nil {
 			continue
@@ -231,7 +222,7 @@ func (c *MassSetXPCommand) Execute(ctx *Context) error {
 		return err
 	}
 
-	newLevel := calcLevel(amount)
+	newLevel := c.DB.LevelCurve(ctx.Message.GuildID).LevelForExp(amount)
 
 	msg, _ := ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
 		fmt.Sprintf("Setting XP to %d for members with %s...", amount, role.Name))
@@ -359,6 +350,7 @@ func (c *MassLevelUpCommand) Execute(ctx *Context) error {
 		return err
 	}
 
+	curve := c.DB.LevelCurve(ctx.Message.GuildID)
 	updated := 0
 
 	for _, member := range members {
@@ -378,8 +370,11 @@ func (c *MassLevelUpCommand) Execute(ctx *Context) error {
 		}
 
 		_, oldLevel, _ := c.DB.GetUserXP(ctx.Message.GuildID, member.User.ID)
-		newLevel := oldLevel + levels
-		newExp := calcExpForLevel(newLevel)
+		if oldLevel >= leveling.MaxLevel {
+			continue
+		}
+		newLevel := min(oldLevel+levels, leveling.MaxLevel)
+		newExp := curve.ExpForLevel(newLevel)
 
 		if err := c.DB.SetUserXP(ctx.Message.GuildID, member.User.ID, newExp, newLevel); err != nil {
 			continue
@@ -440,6 +435,7 @@ func (c *SyncXPFromRolesCommand) Execute(ctx *Context) error {
 		return err
 	}
 
+	curve := c.DB.LevelCurve(ctx.Message.GuildID)
 	updated := 0
 
 	for _, member := range members {
@@ -458,7 +454,7 @@ func (c *SyncXPFromRolesCommand) Execute(ctx *Context) error {
 		}
 
 		if highestLevel > 0 {
-			exp := calcExpForLevel(highestLevel)
+			exp := curve.ExpForLevel(highestLevel)
 			if err := c.DB.SetUserXP(ctx.Message.GuildID, member.User.ID, exp, highestLevel); err == nil {
 				updated++
 			}
@@ -0,0 +1,390 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Case actions recorded in mod_cases
+const (
+	CaseBan       = "ban"
+	CaseKick      = "kick"
+	CaseWarn      = "warn"
+	CaseTimeout   = "timeout"
+	CaseUntimeout = "untimeout"
+	CaseUnban     = "unban"
+	CaseMute      = "mute"
+	CaseUnmute    = "unmute"
+	CaseNote      = "note"
+	CaseBlocked   = "blocked" // A refused ban or kick on a protected member
+)
+
+// ModCase is a single numbered entry in a guild's moderation history
+type ModCase struct {
+	GuildID     string
+	CaseNumber  int
+	Action      string
+	UserID      string
+	ModeratorID string
+	Reason      string
+	Duration    time.Duration
+	CreatedAt   time.Time
+}
+
+// CaseStore is what the moderation commands need to record and look up cases
+type CaseStore interface {
+	RecordModCase(modCase *ModCase) (*ModCase, error)
+	GetModCase(guildID string, caseNumber int) (*ModCase, error)
+	UpdateModCaseReason(guildID string, caseNumber int, reason string) error
+	DeleteModCase(guildID string, caseNumber int) error
+	GetUserModCases(guildID, userID string) ([]*ModCase, error)
+}
+
+var caseColors = map[string]int{
+	CaseBan:       0xFF0000,
+	CaseKick:      0xFF8800,
+	CaseWarn:      0xFFAA00,
+	CaseTimeout:   0xFFD700,
+	CaseUntimeout: 0x43CC24,
+	CaseUnban:     0x43CC24,
+	CaseMute:      0xFFD700,
+	CaseUnmute:    0x43CC24,
+	CaseNote:      0xFF51FF,
+	CaseBlocked:   0x99AAB5,
+}
+
+// ModCaseEmbed renders a case for the log channel and the case command
+func ModCaseEmbed(modCase *ModCase) *discordgo.MessageEmbed {
+	reason := modCase.Reason
+	if reason == "" {
+		reason = "No reason provided"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("Case #%d | %s", modCase.CaseNumber, strings.ToUpper(modCase.Action[:1])+modCase.Action[1:]),
+		Color: caseColors[modCase.Action],
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "User", Value: fmt.Sprintf("<@%s> (%s)", modCase.UserID, modCase.UserID), Inline: true},
+			{Name: "Moderator", Value: fmt.Sprintf("<@%s>", modCase.ModeratorID), Inline: true},
+		},
+		Timestamp: modCase.CreatedAt.Format(time.RFC3339),
+	}
+
+	if modCase.Duration > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Duration",
+			Value:  formatDuration(modCase.Duration),
+			Inline: true,
+		})
+	}
+
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:   "Reason",
+		Value:  reason,
+		Inline: false,
+	})
+
+	return embed
+}
+
+// recordCase stores a case for a command-driven action and returns its number (0 on failure)
+func recordCase(ctx *Context, cases CaseStore, action, userID, reason string, duration time.Duration) int {
+	if cases == nil {
+		return 0
+	}
+
+	modCase, err := cases.RecordModCase(&ModCase{
+		GuildID:     ctx.Message.GuildID,
+		Action:      action,
+		UserID:      userID,
+		ModeratorID: ctx.Message.Author.ID,
+		Reason:      reason,
+		Duration:    duration,
+	})
+	if err != nil {
+		return 0
+	}
+	return modCase.CaseNumber
+}
+
+// caseSuffix renders " (Case #N)" when a case was recorded
+func caseSuffix(caseNumber int) string {
+	if caseNumber == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (Case #%d)", caseNumber)
+}
+
+// NoteCommand adds a note to a user's history without taking action
+type NoteCommand struct {
+	Cases CaseStore
+}
+
+func (c *NoteCommand) Name() string        { return "note" }
+func (c *NoteCommand) Aliases() []string   { return []string{"addnote"} }
+func (c *NoteCommand) Description() string { return "Add a moderator note to a user's history" }
+func (c *NoteCommand) Usage() string       { return "note <@user|id> <text>" }
+func (c *NoteCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionModerateMembers}
+}
+func (c *NoteCommand) MasterOnly() bool { return false }
+func (c *NoteCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "user", Description: "User the note is about", Type: ArgUser, Required: true},
+		{Name: "text", Description: "Note text", Type: ArgString, Required: true},
+	}
+}
+
+func (c *NoteCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) < 2 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	userID := parseUserID(ctx.Args[0])
+	if !isSnowflake(userID) {
+		ctx.Reply("❌ Please mention a user or give their ID")
+		return nil
+	}
+
+	caseNumber := recordCase(ctx, c.Cases, CaseNote, userID, strings.Join(ctx.Args[1:], " "), 0)
+	if caseNumber == 0 {
+		ctx.Reply("❌ Failed to save note")
+		return nil
+	}
+
+	_, err := ctx.Reply(fmt.Sprintf("📝 Note added for <@%s>%s", userID, caseSuffix(caseNumber)))
+	return err
+}
+
+// CaseCommand shows a single case
+type CaseCommand struct {
+	Cases CaseStore
+}
+
+func (c *CaseCommand) Name() string        { return "case" }
+func (c *CaseCommand) Aliases() []string   { return []string{"showcase"} }
+func (c *CaseCommand) Description() string { return "Show a moderation case" }
+func (c *CaseCommand) Usage() string       { return "case <number>" }
+func (c *CaseCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionModerateMembers}
+}
+func (c *CaseCommand) MasterOnly() bool { return false }
+func (c *CaseCommand) Arguments() []Argument {
+	return []Argument{{Name: "number", Description: "Case number", Type: ArgInteger, Required: true}}
+}
+
+func (c *CaseCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	caseNumber, ok := parseCaseNumber(ctx)
+	if !ok {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	modCase, err := c.Cases.GetModCase(ctx.Message.GuildID, caseNumber)
+	if err != nil {
+		ctx.Reply(fmt.Sprintf("❌ Case #%d not found", caseNumber))
+		return nil
+	}
+
+	_, err = ctx.ReplyEmbed(ModCaseEmbed(modCase))
+	return err
+}
+
+// ReasonCommand edits the reason of a case
+type ReasonCommand struct {
+	Cases CaseStore
+}
+
+func (c *ReasonCommand) Name() string        { return "reason" }
+func (c *ReasonCommand) Aliases() []string   { return []string{"editcase"} }
+func (c *ReasonCommand) Description() string { return "Change the reason of a moderation case" }
+func (c *ReasonCommand) Usage() string       { return "reason <case number> <new reason>" }
+func (c *ReasonCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionModerateMembers}
+}
+func (c *ReasonCommand) MasterOnly() bool { return false }
+func (c *ReasonCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "number", Description: "Case number", Type: ArgInteger, Required: true},
+		{Name: "reason", Description: "New reason", Type: ArgString, Required: true},
+	}
+}
+
+func (c *ReasonCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	caseNumber, ok := parseCaseNumber(ctx)
+	if !ok || len(ctx.Args) < 2 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	reason := strings.Join(ctx.Args[1:], " ")
+	if err := c.Cases.UpdateModCaseReason(ctx.Message.GuildID, caseNumber, reason); err != nil {
+		ctx.Reply(fmt.Sprintf("❌ Case #%d not found", caseNumber))
+		return nil
+	}
+
+	_, err := ctx.Reply(fmt.Sprintf("✅ Updated reason for case #%d", caseNumber))
+	return err
+}
+
+// DelCaseCommand removes a case from the history
+type DelCaseCommand struct {
+	Cases CaseStore
+}
+
+func (c *DelCaseCommand) Name() string        { return "delcase" }
+func (c *DelCaseCommand) Aliases() []string   { return []string{"deletecase", "rmcase"} }
+func (c *DelCaseCommand) Description() string { return "Delete a moderation case" }
+func (c *DelCaseCommand) Usage() string       { return "delcase <number>" }
+func (c *DelCaseCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *DelCaseCommand) MasterOnly() bool { return false }
+func (c *DelCaseCommand) Arguments() []Argument {
+	return []Argument{{Name: "number", Description: "Case number", Type: ArgInteger, Required: true}}
+}
+
+func (c *DelCaseCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	caseNumber, ok := parseCaseNumber(ctx)
+	if !ok {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	if err := c.Cases.DeleteModCase(ctx.Message.GuildID, caseNumber); err != nil {
+		ctx.Reply(fmt.Sprintf("❌ Case #%d not found", caseNumber))
+		return nil
+	}
+
+	_, err := ctx.Reply(fmt.Sprintf("🗑️ Deleted case #%d", caseNumber))
+	return err
+}
+
+// HistoryCommand lists a user's cases
+type HistoryCommand struct {
+	Cases CaseStore
+}
+
+func (c *HistoryCommand) Name() string        { return "history" }
+func (c *HistoryCommand) Aliases() []string   { return []string{"cases", "modlogs", "infractions"} }
+func (c *HistoryCommand) Description() string { return "List a user's moderation history" }
+func (c *HistoryCommand) Usage() string       { return "history <@user|id>" }
+func (c *HistoryCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionModerateMembers}
+}
+func (c *HistoryCommand) MasterOnly() bool { return false }
+func (c *HistoryCommand) Arguments() []Argument {
+	return []Argument{{Name: "user", Description: "User to look up", Type: ArgUser, Required: true}}
+}
+
+func (c *HistoryCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) < 1 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	userID := parseUserID(ctx.Args[0])
+	if !isSnowflake(userID) {
+		ctx.Reply("❌ Please mention a user or give their ID")
+		return nil
+	}
+
+	cases, err := c.Cases.GetUserModCases(ctx.Message.GuildID, userID)
+	if err != nil {
+		ctx.Reply("❌ Failed to load history: " + err.Error())
+		return err
+	}
+
+	if len(cases) == 0 {
+		_, err := ctx.Reply(fmt.Sprintf("✨ <@%s> has a clean record", userID))
+		return err
+	}
+
+	counts := make(map[string]int)
+	lines := []string{}
+	for _, modCase := range cases {
+		counts[modCase.Action]++
+
+		reason := modCase.Reason
+		if reason == "" {
+			reason = "No reason provided"
+		}
+		if len(reason) > 80 {
+			reason = reason[:77] + "..."
+		}
+
+		line := fmt.Sprintf("`#%d` **%s** • %s — %s",
+			modCase.CaseNumber, modCase.Action, modCase.CreatedAt.Format("2006-01-02"), reason)
+		if modCase.Duration > 0 {
+			line += fmt.Sprintf(" (%s)", formatDuration(modCase.Duration))
+		}
+		lines = append(lines, line)
+	}
+
+	// Keep the newest entries within the embed description limit
+	description := ""
+	shown := 0
+	for _, line := range lines {
+		if len(description)+len(line)+1 > 3900 {
+			break
+		}
+		description += line + "\n"
+		shown++
+	}
+
+	summary := []string{}
+	for _, action := range []string{CaseBan, CaseKick, CaseTimeout, CaseMute, CaseWarn, CaseUnban, CaseUntimeout, CaseUnmute, CaseNote} {
+		if counts[action] > 0 {
+			summary = append(summary, fmt.Sprintf("%s: %d", action, counts[action]))
+		}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Moderation History",
+		Description: fmt.Sprintf("<@%s>\n\n%s", userID, description),
+		Color:       0xFF51FF,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%s | Showing %d of %d cases", strings.Join(summary, " • "), shown, len(cases)),
+		},
+	}
+
+	_, err = ctx.ReplyEmbed(embed)
+	return err
+}
+
+// parseCaseNumber reads the case number from the first argument ("#12" or "12")
+func parseCaseNumber(ctx *Context) (int, bool) {
+	if len(ctx.Args) < 1 {
+		return 0, false
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(ctx.Args[0], "#"))
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return number, true
+}
@@ -0,0 +1,383 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// FilterMatch is a rule that would act on a message
+type FilterMatch struct {
+	Rule   string // What fired, e.g. "Regex filter #3"
+	Action string
+	Reason string
+}
+
+// FilterTester dry-runs a message through the spam filter
+type FilterTester interface {
+	TestFilters(guildID, channelID, content string) []FilterMatch
+}
+
+// guildChannelArg resolves a channel mention to a channel in this server
+func guildChannelArg(ctx *Context, arg string) (string, bool) {
+	channelID := parseChannelID(arg)
+	if !isSnowflake(channelID) {
+		return "", false
+	}
+	channel, err := ctx.Session.State.Channel(channelID)
+	if err != nil {
+		channel, err = ctx.Session.Channel(channelID)
+	}
+	if err != nil || channel.GuildID != ctx.Message.GuildID {
+		return "", false
+	}
+	return channelID, true
+}
+
+// AddChannelFilterCommand adds a regex filter for one channel
+type AddChannelFilterCommand struct {
+	Filters RegexFilterStore
+}
+
+func (c *AddChannelFilterCommand) Name() string      { return "addchannelfilter" }
+func (c *AddChannelFilterCommand) Aliases() []string { return []string{"addchannelregex"} }
+func (c *AddChannelFilterCommand) Description() string {
+	return "Add a regex filter that only applies in one channel"
+}
+func (c *AddChannelFilterCommand) Usage() string {
+	return "addchannelfilter <#channel> <pattern> | <action> | <reason>"
+}
+func (c *AddChannelFilterCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *AddChannelFilterCommand) MasterOnly() bool { return false }
+
+func (c *AddChannelFilterCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) < 2 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`\nActions: `warn`, `ban`, `delete`")
+		return nil
+	}
+
+	channelID, ok := guildChannelArg(ctx, ctx.Args[0])
+	if !ok {
+		ctx.Reply("❌ Please mention a channel in this server")
+		return nil
+	}
+
+	pattern, action, reason, err := parseFilterSpec(ctx.Args[1:])
+	if err != nil {
+		ctx.Reply("❌ " + err.Error())
+		return nil
+	}
+
+	id, err := c.Filters.AddRegexFilter(&RegexFilter{
+		GuildID:   ctx.Message.GuildID,
+		ChannelID: channelID,
+		Pattern:   pattern,
+		Action:    action,
+		Reason:    reason,
+		Enabled:   true,
+		CreatedBy: ctx.Message.Author.ID,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			ctx.Reply("❌ That channel already has a filter with this pattern!")
+			return nil
+		}
+		return fmt.Errorf("failed to add filter: %v", err)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "✅ Channel Filter Added",
+		Description: fmt.Sprintf("Added regex filter #%d for <#%s>", id, channelID),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Pattern", Value: fmt.Sprintf("`%s`", pattern), Inline: false},
+			{Name: "Action", Value: action, Inline: true},
+			{Name: "Reason", Value: reason, Inline: true},
+		},
+		Color: 0x43CC24,
+	}
+	_, err = ctx.ReplyEmbed(embed)
+	return err
+}
+
+// RemoveChannelFilterCommand removes a channel regex filter
+type RemoveChannelFilterCommand struct {
+	Filters RegexFilterStore
+}
+
+func (c *RemoveChannelFilterCommand) Name() string { return "removechannelfilter" }
+func (c *RemoveChannelFilterCommand) Aliases() []string {
+	return []string{"delchannelfilter", "removechannelregex"}
+}
+func (c *RemoveChannelFilterCommand) Description() string {
+	return "Remove a channel regex filter by ID"
+}
+func (c *RemoveChannelFilterCommand) Usage() string { return "removechannelfilter <#channel> <id>" }
+func (c *RemoveChannelFilterCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *RemoveChannelFilterCommand) MasterOnly() bool { return false }
+
+func (c *RemoveChannelFilterCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	channelID, id, ok := parseChannelFilterRef(ctx.Args)
+	if !ok || channelID == "" {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "` (use `listchannelfilters` to see IDs)")
+		return nil
+	}
+
+	removed, err := c.Filters.RemoveRegexFilter(ctx.Message.GuildID, channelID, id)
+	if err != nil {
+		return fmt.Errorf("failed to remove filter: %v", err)
+	}
+	if !removed {
+		ctx.Reply("❌ No filter with that ID in <#" + channelID + ">")
+		return nil
+	}
+
+	_, err = ctx.Reply(fmt.Sprintf("✅ Removed filter #%d from <#%s>", id, channelID))
+	return err
+}
+
+// ListChannelFiltersCommand lists a channel's regex filters
+type ListChannelFiltersCommand struct {
+	Filters RegexFilterStore
+}
+
+func (c *ListChannelFiltersCommand) Name() string { return "listchannelfilters" }
+func (c *ListChannelFiltersCommand) Aliases() []string {
+	return []string{"channelfilters", "listchannelregex"}
+}
+func (c *ListChannelFiltersCommand) Description() string {
+	return "List the regex filters for a channel"
+}
+func (c *ListChannelFiltersCommand) Usage() string { return "listchannelfilters [#channel]" }
+func (c *ListChannelFiltersCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *ListChannelFiltersCommand) MasterOnly() bool { return false }
+
+func (c *ListChannelFiltersCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	channelID := ctx.Message.ChannelID
+	if len(ctx.Args) > 0 {
+		var ok bool
+		if channelID, ok = guildChannelArg(ctx, ctx.Args[0]); !ok {
+			ctx.Reply("❌ Please mention a channel in this server")
+			return nil
+		}
+	}
+
+	filters, err := c.Filters.GetRegexFilters(ctx.Message.GuildID, channelID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch filters: %v", err)
+	}
+
+	if len(filters) == 0 {
+		ctx.Reply("No regex filters for <#" + channelID + ">.\nUse `addchannelfilter` to add one!")
+		return nil
+	}
+
+	var fields []*discordgo.MessageEmbedField
+	for _, filter := range filters {
+		status := "✅"
+		if !filter.Enabled {
+			status = "❌"
+		}
+
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name: fmt.Sprintf("%s Filter #%d - %s", status, filter.ID, filter.Action),
+			Value: fmt.Sprintf("**Pattern:** `%s`\n**Reason:** %s\n**Hits:** %s",
+				filter.Pattern, filter.Reason, formatFilterHits(filter)),
+			Inline: false,
+		})
+
+		// Discord has a limit of 25 fields
+		if len(fields) >= 25 {
+			break
+		}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Channel Filters (%d total)", len(filters)),
+		Description: "Filters for <#" + channelID + ">. Use `removechannelfilter <#channel> <id>` to remove one.",
+		Fields:      fields,
+		Color:       0xFF51FF,
+	}
+	_, err = ctx.ReplyEmbed(embed)
+	return err
+}
+
+// EnableFilterCommand turns a disabled regex filter back on
+type EnableFilterCommand struct {
+	Filters RegexFilterStore
+}
+
+func (c *EnableFilterCommand) Name() string      { return "enablefilter" }
+func (c *EnableFilterCommand) Aliases() []string { return []string{"filteron"} }
+func (c *EnableFilterCommand) Description() string {
+	return "Turn a disabled regex filter back on"
+}
+func (c *EnableFilterCommand) Usage() string { return "enablefilter [#channel] <id>" }
+func (c *EnableFilterCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *EnableFilterCommand) MasterOnly() bool { return false }
+
+func (c *EnableFilterCommand) Execute(ctx *Context) error {
+	return setFilterEnabled(ctx, c.Filters, c.Usage(), true)
+}
+
+// DisableFilterCommand turns a regex filter off without deleting it
+type DisableFilterCommand struct {
+	Filters RegexFilterStore
+}
+
+func (c *DisableFilterCommand) Name() string      { return "disablefilter" }
+func (c *DisableFilterCommand) Aliases() []string { return []string{"filteroff"} }
+func (c *DisableFilterCommand) Description() string {
+	return "Turn a regex filter off without deleting it"
+}
+func (c *DisableFilterCommand) Usage() string { return "disablefilter [#channel] <id>" }
+func (c *DisableFilterCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *DisableFilterCommand) MasterOnly() bool { return false }
+
+func (c *DisableFilterCommand) Execute(ctx *Context) error {
+	return setFilterEnabled(ctx, c.Filters, c.Usage(), false)
+}
+
+// setFilterEnabled toggles a guild-wide filter, or a channel filter when a channel is given
+func setFilterEnabled(ctx *Context, filters RegexFilterStore, usage string, enabled bool) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	channelID, id, ok := parseChannelFilterRef(ctx.Args)
+	if !ok {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + usage + "` (give a channel for channel filters)")
+		return nil
+	}
+
+	found, err := filters.SetRegexFilterEnabled(ctx.Message.GuildID, channelID, id, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to update filter: %v", err)
+	}
+
+	where := ""
+	if channelID != "" {
+		where = " in <#" + channelID + ">"
+	}
+	if !found {
+		ctx.Reply(fmt.Sprintf("❌ No filter #%d%s", id, where))
+		return nil
+	}
+
+	_, err = ctx.Reply(fmt.Sprintf("✅ Filter #%d%s %s", id, where, enabledWord(enabled)))
+	return err
+}
+
+// parseChannelFilterRef reads "[#channel] <id>"
+func parseChannelFilterRef(args []string) (channelID string, id int, ok bool) {
+	switch len(args) {
+	case 1:
+	case 2:
+		channelID = parseChannelID(args[0])
+		if !isSnowflake(channelID) {
+			return "", 0, false
+		}
+	default:
+		return "", 0, false
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(args[len(args)-1], "#"))
+	if err != nil || id <= 0 {
+		return "", 0, false
+	}
+	return channelID, id, true
+}
+
+// TestFilterCommand shows which rules would act on a sample message
+type TestFilterCommand struct {
+	Tester FilterTester
+}
+
+func (c *TestFilterCommand) Name() string      { return "testfilter" }
+func (c *TestFilterCommand) Aliases() []string { return []string{"filtertest", "dryrun"} }
+func (c *TestFilterCommand) Description() string {
+	return "Check which filters would act on a message, without acting"
+}
+func (c *TestFilterCommand) Usage() string { return "testfilter [#channel] <message>" }
+func (c *TestFilterCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *TestFilterCommand) MasterOnly() bool { return false }
+
+func (c *TestFilterCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) == 0 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	args := ctx.Args
+	channelID := ctx.Message.ChannelID
+	if len(args) > 1 {
+		if id, ok := guildChannelArg(ctx, args[0]); ok {
+			channelID = id
+			args = args[1:]
+		}
+	}
+	content := strings.Join(args, " ")
+
+	matches := c.Tester.TestFilters(ctx.Message.GuildID, channelID, content)
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Filter Test",
+		Color: 0x43CC24,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Channel", Value: "<#" + channelID + ">", Inline: true},
+			{Name: "Message", Value: truncateField("```\n" + strings.ReplaceAll(content, "```", "'''") + "\n```"), Inline: false},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Dry run as a regular member; nothing was deleted or counted",
+		},
+	}
+
+	if len(matches) == 0 {
+		embed.Description = "✅ No rules would act on this message."
+	} else {
+		embed.Color = 0xFFAA00
+		embed.Description = fmt.Sprintf("⚠️ %d rule(s) would fire. The first one acts.", len(matches))
+		for i, match := range matches {
+			if len(embed.Fields) >= 25 {
+				break
+			}
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   fmt.Sprintf("%d. %s", i+1, match.Rule),
+				Value:  truncateField(fmt.Sprintf("**Action:** %s\n**Reason:** %s", match.Action, match.Reason)),
+				Inline: false,
+			})
+		}
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Channel content policies
+const (
+	PolicyNone          = "none"           // Explicitly no policy, overriding a prefix
+	PolicyMediaOnly     = "media-only"     // Needs an attachment, sticker or link
+	PolicyLinksOnly     = "links-only"     // Needs a link
+	PolicyTextOnly      = "text-only"      // No attachments, stickers or links
+	PolicyNoAttachments = "no-attachments" // No uploaded files
+)
+
+// Policy target types
+const (
+	PolicyTargetChannel = "channel"
+	PolicyTargetPrefix  = "prefix"
+)
+
+// ChannelPolicy restricts what can be posted in a channel, or in every
+// channel whose name starts with a prefix
+type ChannelPolicy struct {
+	GuildID    string
+	TargetType string
+	Target     string // Channel ID or name prefix
+	Policy     string
+}
+
+// ChannelPolicyStore reads and writes channel content policies
+type ChannelPolicyStore interface {
+	GetChannelPolicies(guildID string) []ChannelPolicy
+	SetChannelPolicy(policy ChannelPolicy) error
+	RemoveChannelPolicy(guildID, targetType, target string) (bool, error)
+}
+
+// ResolveChannelPolicy returns the policy for a channel. A channel's own rule
+// beats prefix rules, and the longest matching prefix wins. It returns
+// PolicyNone when nothing applies.
+func ResolveChannelPolicy(policies []ChannelPolicy, channelID, channelName string) string {
+	policy := PolicyNone
+	longest := -1
+	for _, p := range policies {
+		switch p.TargetType {
+		case PolicyTargetChannel:
+			if p.Target == channelID {
+				return p.Policy
+			}
+		case PolicyTargetPrefix:
+			if strings.HasPrefix(channelName, p.Target) && len(p.Target) > longest {
+				policy, longest = p.Policy, len(p.Target)
+			}
+		}
+	}
+	return policy
+}
+
+// PolicyNotice explains a policy to someone who broke it
+func PolicyNotice(policy string) string {
+	switch policy {
+	case PolicyMediaOnly:
+		return "this channel is for images, videos and links only"
+	case PolicyLinksOnly:
+		return "this channel is for links only"
+	case PolicyTextOnly:
+		return "this channel is for text only, no files or links"
+	case PolicyNoAttachments:
+		return "files can't be uploaded in this channel"
+	}
+	return "that can't be posted here"
+}
+
+func isChannelPolicy(policy string) bool {
+	switch policy {
+	case PolicyNone, PolicyMediaOnly, PolicyLinksOnly, PolicyTextOnly, PolicyNoAttachments:
+		return true
+	}
+	return false
+}
+
+// ChannelPolicyCommand sets what kind of content channels accept
+type ChannelPolicyCommand struct {
+	Policies ChannelPolicyStore
+}
+
+func (c *ChannelPolicyCommand) Name() string      { return "channel-policy" }
+func (c *ChannelPolicyCommand) Aliases() []string { return []string{"channelpolicy", "content-policy"} }
+func (c *ChannelPolicyCommand) Description() string {
+	return "Restrict channels to media, links or text"
+}
+func (c *ChannelPolicyCommand) Usage() string {
+	return "channel-policy [<#channel|prefix:name> media-only|links-only|text-only|no-attachments|none|remove]"
+}
+func (c *ChannelPolicyCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageChannels}
+}
+func (c *ChannelPolicyCommand) MasterOnly() bool { return false }
+
+func (c *ChannelPolicyCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) == 0 {
+		return c.showList(ctx)
+	}
+	if len(ctx.Args) != 2 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	policy := ChannelPolicy{GuildID: ctx.Message.GuildID}
+	if prefix, ok := strings.CutPrefix(strings.ToLower(ctx.Args[0]), "prefix:"); ok {
+		if prefix == "" || len(prefix) > 50 {
+			ctx.Reply("❌ Give a channel name prefix, like `prefix:nsfw_`")
+			return nil
+		}
+		policy.TargetType, policy.Target = PolicyTargetPrefix, prefix
+	} else {
+		channelID := parseChannelID(ctx.Args[0])
+		if !isSnowflake(channelID) {
+			ctx.Reply("❌ Please mention a channel or use `prefix:<name>`")
+			return nil
+		}
+		policy.TargetType, policy.Target = PolicyTargetChannel, channelID
+	}
+
+	target := policyTargetLabel(policy.TargetType, policy.Target)
+	value := strings.ToLower(ctx.Args[1])
+
+	if value == "remove" || value == "reset" {
+		removed, err := c.Policies.RemoveChannelPolicy(policy.GuildID, policy.TargetType, policy.Target)
+		if err != nil {
+			ctx.Reply("❌ Failed to remove policy: " + err.Error())
+			return err
+		}
+		if !removed {
+			ctx.Reply("There's no policy set for " + target)
+			return nil
+		}
+		_, err = ctx.Reply("✅ Removed the policy for " + target)
+		return err
+	}
+
+	if !isChannelPolicy(value) {
+		ctx.Reply("❌ Policies: `media-only`, `links-only`, `text-only`, `no-attachments`, `none`")
+		return nil
+	}
+	policy.Policy = value
+
+	if err := c.Policies.SetChannelPolicy(policy); err != nil {
+		ctx.Reply("❌ Failed to save policy: " + err.Error())
+		return err
+	}
+
+	if value == PolicyNone {
+		_, err := ctx.Reply("✅ " + target + " accepts anything, whatever its prefix")
+		return err
+	}
+	_, err := ctx.Reply(fmt.Sprintf("✅ %s is now %s", target, value))
+	return err
+}
+
+func (c *ChannelPolicyCommand) showList(ctx *Context) error {
+	policies := c.Policies.GetChannelPolicies(ctx.Message.GuildID)
+	sort.Slice(policies, func(i, j int) bool {
+		if policies[i].TargetType != policies[j].TargetType {
+			return policies[i].TargetType < policies[j].TargetType
+		}
+		return policies[i].Target < policies[j].Target
+	})
+
+	lines := make([]string, 0, len(policies))
+	for _, p := range policies {
+		lines = append(lines, fmt.Sprintf("%s — **%s**", policyTargetLabel(p.TargetType, p.Target), p.Policy))
+	}
+
+	description := "No channel policies set."
+	if len(lines) > 0 {
+		description = truncateField(strings.Join(lines, "\n"))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Channel Content Policies",
+		Description: description,
+		Color:       0xFF51FF,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
+
+func policyTargetLabel(targetType, target string) string {
+	if targetType == PolicyTargetPrefix {
+		return "channels starting with `" + target + "`"
+	}
+	return "<#" + target + ">"
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ComponentHandler is implemented by commands that attach buttons or select
+// menus to their replies. A component's CustomID is "<command>:<data>" (see
+// ComponentID); the handler receives the data part and must answer the
+// interaction itself, usually with ctx.UpdateMessage.
+type ComponentHandler interface {
+	HandleComponent(ctx *Context, data string) error
+}
+
+// ComponentID builds a CustomID that routes back to cmd's HandleComponent
+func ComponentID(cmd Command, data string) string {
+	return cmd.Name() + ":" + data
+}
+
+// ExecuteComponent routes a component interaction to the command that created it
+func (m *Manager) ExecuteComponent(ctx *Context) error {
+	i := ctx.Interaction
+	name, data, _ := strings.Cut(i.MessageComponentData().CustomID, ":")
+
+	cmd, err := m.Get(name)
+	if err != nil {
+		return nil
+	}
+	handler, ok := cmd.(ComponentHandler)
+	if !ok {
+		return nil
+	}
+
+	author := i.User
+	if i.Member != nil && i.Member.User != nil {
+		author = i.Member.User
+	}
+	ctx.Message = &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        i.ID,
+		ChannelID: i.ChannelID,
+		GuildID:   i.GuildID,
+		Author:    author,
+		Member:    i.Member,
+	}}
+
+	m.setHelpers(ctx)
+
+	// Components keep the permissions of the command that posted them
+	if (cmd.MasterOnly() && !m.isMaster(ctx)) || !m.hasPermissions(ctx, cmd.RequiredPermissions()) {
+		return ctx.Session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "❌ You don't have permission to use this.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	return handler.HandleComponent(ctx, data)
+}
+
+// UpdateMessage answers a component interaction by editing the message the
+// component belongs to
+func (ctx *Context) UpdateMessage(data *discordgo.MessageSend) error {
+	ctx.replyMu.Lock()
+	ctx.hasReplied = true
+	ctx.replyMu.Unlock()
+
+	return ctx.Session.InteractionRespond(ctx.Interaction.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    data.Content,
+			Embeds:     data.Embeds,
+			Components: data.Components,
+		},
+	})
+}
@@ -7,60 +7,199 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-// SetPrefixCommand changes the bot prefix for a guild
+// SetPrefixCommand manages the command prefixes for a guild
 type SetPrefixCommand struct {
-	DB interface {
-		SetGuildPrefix(guildID, prefix string) error
-		GetGuildPrefix(guildID string) (string, error)
+	Prefixes interface {
+		GetGuildPrefixesCached(guildID string) *GuildPrefixes
+		SetGuildPrefixes(guildID string, prefixes *GuildPrefixes) error
+		ResetGuildPrefixes(guildID string) error
 	}
 }
 
 func (c *SetPrefixCommand) Name() string        { return "set-prefix" }
 func (c *SetPrefixCommand) Aliases() []string   { return []string{"setprefix", "prefix"} }
-func (c *SetPrefixCommand) Description() string { return "Set the command prefix for this server" }
-func (c *SetPrefixCommand) Usage() string       { return "set-prefix <new prefix>" }
+func (c *SetPrefixCommand) Description() string { return "Set the command prefixes for this server" }
+func (c *SetPrefixCommand) Usage() string {
+	return "set-prefix <prefix> | set-prefix add|remove <prefix> | set-prefix case|mention on|off | set-prefix reset"
+}
 func (c *SetPrefixCommand) RequiredPermissions() []int64 {
 	return []int64{discordgo.PermissionAdministrator}
 }
 func (c *SetPrefixCommand) MasterOnly() bool { return false }
 
 func (c *SetPrefixCommand) Execute(ctx *Context) error {
-	if ctx.Message == nil {
-		return nil
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
 	}
 
+	guildID := ctx.Message.GuildID
+	current := c.Prefixes.GetGuildPrefixesCached(guildID)
+
 	if len(ctx.Args) == 0 {
-		// Show current prefix
-		prefix, err := c.DB.GetGuildPrefix(ctx.Message.GuildID)
-		if err != nil {
-			prefix = ctx.GetPrefix()
-		}
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			fmt.Sprintf("Current prefix: `%s`\nUsage: `%sset-prefix <new prefix>`", prefix, prefix))
-		return nil
+		return c.showPrefixes(ctx, current)
 	}
 
-	newPrefix := ctx.Args[0]
-	if len(newPrefix) > 10 {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Prefix must be 10 characters or less.")
-		return nil
+	// Work on a copy so the cached value isn't mutated before the save succeeds
+	updated := &GuildPrefixes{
+		Prefixes:        append([]string{}, current.Prefixes...),
+		CaseInsensitive: current.CaseInsensitive,
+		MentionPrefix:   current.MentionPrefix,
+	}
+
+	subcommand := strings.ToLower(ctx.Args[0])
+	var result string
+
+	switch subcommand {
+	case "reset":
+		if err := c.Prefixes.ResetGuildPrefixes(guildID); err != nil {
+			ctx.Reply("❌ Error saving prefix. Please try again.")
+			return err
+		}
+		_, err := ctx.Reply(fmt.Sprintf("✅ Prefixes reset to the default `%s`", c.Prefixes.GetGuildPrefixesCached(guildID).Primary()))
+		return err
+
+	case "add":
+		if len(ctx.Args) < 2 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "set-prefix add <prefix>`")
+			return nil
+		}
+		prefix := ctx.Args[1]
+		if msg := validatePrefix(prefix); msg != "" {
+			ctx.Reply(msg)
+			return nil
+		}
+		if updated.Has(prefix) {
+			ctx.Reply(fmt.Sprintf("❌ `%s` is already a prefix", prefix))
+			return nil
+		}
+		if len(updated.Prefixes) >= MaxGuildPrefixes {
+			ctx.Reply(fmt.Sprintf("❌ A server can have at most %d prefixes", MaxGuildPrefixes))
+			return nil
+		}
+		updated.Prefixes = append(updated.Prefixes, prefix)
+		result = fmt.Sprintf("Added prefix `%s`", prefix)
+
+	case "remove", "delete", "del":
+		if len(ctx.Args) < 2 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "set-prefix remove <prefix>`")
+			return nil
+		}
+		prefix := ctx.Args[1]
+		remaining := []string{}
+		for _, existing := range updated.Prefixes {
+			if existing != prefix {
+				remaining = append(remaining, existing)
+			}
+		}
+		if len(remaining) == len(updated.Prefixes) {
+			ctx.Reply(fmt.Sprintf("❌ `%s` is not a prefix here", prefix))
+			return nil
+		}
+		if len(remaining) == 0 {
+			ctx.Reply("❌ You can't remove the last prefix. Use `set-prefix reset` to go back to the default.")
+			return nil
+		}
+		updated.Prefixes = remaining
+		result = fmt.Sprintf("Removed prefix `%s`", prefix)
+
+	case "case", "mention":
+		if len(ctx.Args) < 2 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "set-prefix " + subcommand + " on|off`")
+			return nil
+		}
+		enabled, ok := parseToggle(ctx.Args[1])
+		if !ok {
+			ctx.Reply("❌ Use `on` or `off`")
+			return nil
+		}
+
+		if subcommand == "case" {
+			updated.CaseInsensitive = enabled
+			result = "Case-insensitive prefixes " + enabledWord(enabled)
+		} else {
+			updated.MentionPrefix = enabled
+			result = "Mention prefix " + enabledWord(enabled)
+		}
+
+	default:
+		// set-prefix <prefix> replaces every prefix with a single one
+		prefix := ctx.Args[0]
+		if subcommand == "set" {
+			if len(ctx.Args) < 2 {
+				ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "set-prefix set <prefix>`")
+				return nil
+			}
+			prefix = ctx.Args[1]
+		}
+		if msg := validatePrefix(prefix); msg != "" {
+			ctx.Reply(msg)
+			return nil
+		}
+		updated.Prefixes = []string{prefix}
+		result = fmt.Sprintf("Command prefix set to `%s`", prefix)
 	}
 
-	if err := c.DB.SetGuildPrefix(ctx.Message.GuildID, newPrefix); err != nil {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Error saving prefix. Please try again.")
+	if err := c.Prefixes.SetGuildPrefixes(guildID, updated); err != nil {
+		ctx.Reply("❌ Error saving prefix. Please try again.")
 		return err
 	}
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "✅ Prefix Updated",
-		Description: fmt.Sprintf("Command prefix set to `%s`", newPrefix),
+		Description: result,
 		Color:       0x00FF00,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Prefixes: " + strings.Join(updated.Prefixes, "  "),
+		},
 	}
 
-	_, err := ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
+
+func (c *SetPrefixCommand) showPrefixes(ctx *Context, prefixes *GuildPrefixes) error {
+	quoted := []string{}
+	for _, prefix := range prefixes.Prefixes {
+		quoted = append(quoted, fmt.Sprintf("`%s`", prefix))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Command Prefixes",
+		Color: 0xFF51FF,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Prefixes", Value: strings.Join(quoted, ", "), Inline: false},
+			{Name: "Case-insensitive", Value: onOff(prefixes.CaseInsensitive), Inline: true},
+			{Name: "Mention as prefix", Value: onOff(prefixes.MentionPrefix), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + prefixedUsage(prefixes.Primary(), c.Usage()),
+		},
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
 	return err
 }
 
+// prefixedUsage puts the prefix in front of each "|"-separated usage form
+func prefixedUsage(prefix, usage string) string {
+	forms := strings.Split(usage, " | ")
+	for i, form := range forms {
+		forms[i] = prefix + strings.TrimSpace(form)
+	}
+	return strings.Join(forms, " | ")
+}
+
+// validatePrefix returns an error message if the prefix can't be used
+func validatePrefix(prefix string) string {
+	if len(prefix) > 10 {
+		return "❌ Prefix must be 10 characters or less."
+	}
+	if strings.HasPrefix(prefix, "/") {
+		return "❌ `/` is reserved for slash commands."
+	}
+	return ""
+}
+
 // SetPresenceCommand changes the bot's presence/status
 type SetPresenceCommand struct{}
 
@@ -389,68 +528,6 @@ func (c *InitGuildCommand) Execute(ctx *Context) error {
 	return err
 }
 
-// SetSpamFilterCommand toggles spam filter
-type SetSpamFilterCommand struct {
-	DB interface {
-		SetGuildSpamFilter(guildID string, enabled bool) error
-	}
-}
-
-func (c *SetSpamFilterCommand) Name() string        { return "set-spamfilter" }
-func (c *SetSpamFilterCommand) Aliases() []string   { return []string{"spamfilter", "togglespam"} }
-func (c *SetSpamFilterCommand) Description() string { return "Enable or disable the spam filter" }
-func (c *SetSpamFilterCommand) Usage() string       { return "set-spamfilter <on|off>" }
-func (c *SetSpamFilterCommand) RequiredPermissions() []int64 {
-	return []int64{discordgo.PermissionAdministrator}
-}
-func (c *SetSpamFilterCommand) MasterOnly() bool { return false }
-
-func (c *SetSpamFilterCommand) Execute(ctx *Context) error {
-	if ctx.Message == nil {
-		return nil
-	}
-
-	if len(ctx.Args) == 0 {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"Usage: `set-spamfilter on` or `set-spamfilter off`")
-		return nil
-	}
-
-	state := strings.ToLower(ctx.Args[0])
-	var enabled bool
-	switch state {
-	case "on", "enable", "true", "1":
-		enabled = true
-	case "off", "disable", "false", "0":
-		enabled = false
-	default:
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"Usage: `set-spamfilter on` or `set-spamfilter off`")
-		return nil
-	}
-
-	if err := c.DB.SetGuildSpamFilter(ctx.Message.GuildID, enabled); err != nil {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Error updating spam filter setting.")
-		return err
-	}
-
-	status := "enabled"
-	color := 0x00FF00
-	if !enabled {
-		status = "disabled"
-		color = 0xFFAA00
-	}
-
-	embed := &discordgo.MessageEmbed{
-		Title:       "Spam Filter Updated",
-		Description: fmt.Sprintf("Spam filter has been **%s**", status),
-		Color:       color,
-	}
-
-	_, err := ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
-	return err
-}
-
 // SetLevelingCommand toggles leveling system
 type SetLevelingCommand struct {
 	DB interface {
@@ -474,7 +551,7 @@ func (c *SetLevelingCommand) Execute(ctx *Context) error {
 
 	if len(ctx.Args) == 0 {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"Usage: `set-leveling on` or `set-leveling off`")
+			"Usage: `"+ctx.GetPrefix()+"set-leveling on` or `set-leveling off`")
 		return nil
 	}
 
@@ -487,7 +564,7 @@ func (c *SetLevelingCommand) Execute(ctx *Context) error {
 		enabled = false
 	default:
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"Usage: `set-leveling on` or `set-leveling off`")
+			"Usage: `"+ctx.GetPrefix()+"set-leveling on` or `set-leveling off`")
 		return nil
 	}
 
@@ -1,6 +1,9 @@
 package commands
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -10,13 +13,22 @@ type Context struct {
 	Message *discordgo.MessageCreate
 	Args    []string
 	Bot     interface{}
-	
+
+	// Interaction is set when the command was invoked as a slash command.
+	// Message is still populated (built from the interaction) so commands
+	// written against prefix invocation keep working unchanged.
+	Interaction *discordgo.InteractionCreate
+
 	// Helper functions (set by manager)
 	GetPrefix          func() string
+	GetPrefixes        func() *GuildPrefixes
 	IsOwner            func() bool
 	GetAllCommands     func() []Command
 	GetSourceURL       func() string
 	GetBanImagesPath   func() string
+
+	replyMu    sync.Mutex
+	hasReplied bool
 }
 
 // Command interface that all commands must implement
@@ -29,3 +41,86 @@ type Command interface {
 	MasterOnly() bool
 	Execute(ctx *Context) error
 }
+
+// Reply sends a text response to wherever the command was invoked from
+// (channel message, slash command response, or terminal output)
+func (ctx *Context) Reply(content string) (*discordgo.Message, error) {
+	return ctx.ReplyComplex(&discordgo.MessageSend{Content: content})
+}
+
+// ReplyEmbed sends an embed response to wherever the command was invoked from
+func (ctx *Context) ReplyEmbed(embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
+	return ctx.ReplyComplex(&discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}})
+}
+
+// ReplyComplex sends a response that may contain embeds, components or files
+func (ctx *Context) ReplyComplex(data *discordgo.MessageSend) (*discordgo.Message, error) {
+	if ctx.Interaction != nil {
+		return ctx.replyInteraction(data)
+	}
+
+	if ctx.Message != nil {
+		return ctx.Session.ChannelMessageSendComplex(ctx.Message.ChannelID, data)
+	}
+
+	// Terminal invocation
+	printToTerminal(data)
+	return nil, nil
+}
+
+// HasReplied reports whether the command already answered an interaction
+func (ctx *Context) HasReplied() bool {
+	ctx.replyMu.Lock()
+	defer ctx.replyMu.Unlock()
+	return ctx.hasReplied
+}
+
+// replyInteraction fills the deferred interaction response first, then uses follow-ups
+func (ctx *Context) replyInteraction(data *discordgo.MessageSend) (*discordgo.Message, error) {
+	ctx.replyMu.Lock()
+	first := !ctx.hasReplied
+	ctx.hasReplied = true
+	ctx.replyMu.Unlock()
+
+	if first {
+		edit := &discordgo.WebhookEdit{
+			Content:         &data.Content,
+			Embeds:          &data.Embeds,
+			Files:           data.Files,
+			AllowedMentions: data.AllowedMentions,
+		}
+		if data.Components != nil {
+			edit.Components = &data.Components
+		}
+		return ctx.Session.InteractionResponseEdit(ctx.Interaction.Interaction, edit)
+	}
+
+	return ctx.Session.FollowupMessageCreate(ctx.Interaction.Interaction, true, &discordgo.WebhookParams{
+		Content:         data.Content,
+		Embeds:          data.Embeds,
+		Components:      data.Components,
+		Files:           data.Files,
+		AllowedMentions: data.AllowedMentions,
+	})
+}
+
+// printToTerminal renders a response for terminal invocations
+func printToTerminal(data *discordgo.MessageSend) {
+	if data.Content != "" {
+		fmt.Println(data.Content)
+	}
+	for _, embed := range data.Embeds {
+		if embed.Title != "" {
+			fmt.Println(embed.Title)
+		}
+		if embed.Description != "" {
+			fmt.Println(embed.Description)
+		}
+		for _, field := range embed.Fields {
+			fmt.Printf("  %s: %s\n", field.Name, field.Value)
+		}
+	}
+	for _, file := range data.Files {
+		fmt.Printf("  [file: %s]\n", file.Name)
+	}
+}
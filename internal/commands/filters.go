@@ -2,14 +2,91 @@ package commands
 
 import (
 	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
+// Limits on filter patterns, so one filter can't slow down every message
+const (
+	maxFilterPatternLength = 500
+	maxFilterProgramSize   = 3000 // Compiled regexp instructions
+)
+
+// RegexFilter is a guild-wide or channel-specific regex filter
+type RegexFilter struct {
+	ID        int
+	GuildID   string
+	ChannelID string // Empty for guild-wide filters
+	Pattern   string
+	Action    string
+	Reason    string
+	Enabled   bool
+	CreatedBy string
+	Hits      int64
+	LastHit   time.Time
+}
+
+// RegexFilterStore reads and writes regex filters. channelID selects
+// channel-specific filters; "" means guild-wide ones.
+type RegexFilterStore interface {
+	GetRegexFilters(guildID, channelID string) ([]RegexFilter, error)
+	AddRegexFilter(filter *RegexFilter) (int, error)
+	RemoveRegexFilter(guildID, channelID string, id int) (bool, error)
+	SetRegexFilterEnabled(guildID, channelID string, id int, enabled bool) (bool, error)
+}
+
+// CompileFilterPattern compiles a filter pattern, rejecting ones too large to
+// run on every message
+func CompileFilterPattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > maxFilterPatternLength {
+		return nil, fmt.Errorf("pattern is longer than %d characters", maxFilterPatternLength)
+	}
+
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return nil, err
+	}
+	if len(prog.Inst) > maxFilterProgramSize {
+		return nil, fmt.Errorf("pattern is too complex (try fewer repetitions like {1000})")
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// parseFilterSpec reads "<pattern> | <action> | <reason>". The action and
+// reason are the last two fields, so the pattern can use "|" for alternation.
+func parseFilterSpec(args []string) (pattern, action, reason string, err error) {
+	parts := strings.Split(strings.Join(args, " "), "|")
+	if len(parts) < 3 {
+		return "", "", "", fmt.Errorf("missing arguments, expected `<pattern> | <action> | <reason>`")
+	}
+
+	pattern = strings.TrimSpace(strings.Join(parts[:len(parts)-2], "|"))
+	action = strings.ToLower(strings.TrimSpace(parts[len(parts)-2]))
+	reason = strings.TrimSpace(parts[len(parts)-1])
+
+	if !isFilterAction(action) {
+		return "", "", "", fmt.Errorf("invalid action, must be `warn`, `ban` or `delete`")
+	}
+	if _, err := CompileFilterPattern(pattern); err != nil {
+		return "", "", "", fmt.Errorf("invalid pattern: %v", err)
+	}
+	return pattern, action, reason, nil
+}
+
 // AddFilterCommand adds a regex filter
-type AddFilterCommand struct{}
+type AddFilterCommand struct {
+	Filters RegexFilterStore
+}
 
 func (c *AddFilterCommand) Name() string { return "addfilter" }
 func (c *AddFilterCommand) Aliases() []string { return []string{"addregex"} }
@@ -31,43 +108,25 @@ func (c *AddFilterCommand) Execute(ctx *Context) error {
 
 	if len(ctx.Args) == 0 {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"❌ Usage: `addfilter <pattern> | <action> | <reason>`\nActions: `warn`, `ban`, `delete`")
-		return nil
-	}
-
-	// Parse arguments
-	fullArgs := strings.Join(ctx.Args, " ")
-	parts := strings.Split(fullArgs, "|")
-
-	if len(parts) < 3 {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"❌ Missing arguments. Format: `addfilter <pattern> | <action> | <reason>`")
+			"❌ Usage: `"+ctx.GetPrefix()+"addfilter <pattern> | <action> | <reason>`\nActions: `warn`, `ban`, `delete`")
 		return nil
 	}
 
-	pattern := strings.TrimSpace(parts[0])
-	action := strings.TrimSpace(strings.ToLower(parts[1]))
-	reason := strings.TrimSpace(parts[2])
-
-	// Validate action
-	if action != "warn" && action != "ban" && action != "delete" {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"❌ Invalid action. Must be: `warn`, `ban`, or `delete`")
+	pattern, action, reason, err := parseFilterSpec(ctx.Args)
+	if err != nil {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "❌ "+err.Error())
 		return nil
 	}
 
-	// Get database - use type assertion to get the actual DB
-	type BotDB interface {
-		GetDB() interface{ Exec(string, ...interface{}) (interface{}, error) }
-	}
-	botDB := ctx.Bot.(BotDB).GetDB()
-
 	// Insert filter
-	timestamp := time.Now().Format(time.RFC3339)
-	_, err := botDB.Exec(`
-		INSERT INTO regex_filters (guild_id, pattern, action, reason, enabled, created_by, created_at)
-		VALUES (?, ?, ?, ?, 1, ?, ?)
-	`, ctx.Message.GuildID, pattern, action, reason, ctx.Message.Author.ID, timestamp)
+	id, err := c.Filters.AddRegexFilter(&RegexFilter{
+		GuildID:   ctx.Message.GuildID,
+		Pattern:   pattern,
+		Action:    action,
+		Reason:    reason,
+		Enabled:   true,
+		CreatedBy: ctx.Message.Author.ID,
+	})
 
 	if err != nil {
 		if strings.Contains(err.Error(), "UNIQUE") {
@@ -80,7 +139,7 @@ func (c *AddFilterCommand) Execute(ctx *Context) error {
 
 	embed := &discordgo.MessageEmbed{
 		Title:       "✅ Filter Added",
-		Description: fmt.Sprintf("Successfully added regex filter"),
+		Description: fmt.Sprintf("Successfully added regex filter #%d", id),
 		Fields: []*discordgo.MessageEmbedField{
 			{Name: "Pattern", Value: fmt.Sprintf("`%s`", pattern), Inline: false},
 			{Name: "Action", Value: action, Inline: true},
@@ -94,7 +153,9 @@ func (c *AddFilterCommand) Execute(ctx *Context) error {
 }
 
 // RemoveFilterCommand removes a regex filter
-type RemoveFilterCommand struct{}
+type RemoveFilterCommand struct {
+	Filters RegexFilterStore
+}
 
 func (c *RemoveFilterCommand) Name() string { return "removefilter" }
 func (c *RemoveFilterCommand) Aliases() []string { return []string{"delfilter", "removeregex"} }
@@ -114,46 +175,38 @@ func (c *RemoveFilterCommand) Execute(ctx *Context) error {
 
 	if len(ctx.Args) == 0 {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"❌ Usage: `removefilter <id>` (use `listfilters` to see IDs)")
+			"❌ Usage: `"+ctx.GetPrefix()+"removefilter <id>` (use `listfilters` to see IDs)")
 		return nil
 	}
 
-	filterID := ctx.Args[0]
-	type BotDB interface {
-		GetDB() interface{ Exec(string, ...interface{}) (interface{}, error) }
+	filterID, err := strconv.Atoi(strings.TrimPrefix(ctx.Args[0], "#"))
+	if err != nil {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
+			"❌ Filter IDs are numbers (use `listfilters` to see them)")
+		return nil
 	}
-	botDB := ctx.Bot.(BotDB).GetDB()
-
-	_, err := botDB.Exec(`
-		DELETE FROM regex_filters
-		WHERE guild_id = ? AND id = ?
-	`, ctx.Message.GuildID, filterID)
 
+	removed, err := c.Filters.RemoveRegexFilter(ctx.Message.GuildID, "", filterID)
 	if err != nil {
-		if strings.Contains(err.Error(), "no rows") {
-			ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-				"❌ No filter found with that ID")
-			return nil
-		}
 		return fmt.Errorf("failed to remove filter: %v", err)
 	}
 
-	// Check if filter existed - we can't easily get rows affected with the current interface
-	// so we'll just assume success if no error
-	if false {
+	if !removed {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
 			"❌ No filter found with that ID")
 		return nil
 	}
 
 	ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-		fmt.Sprintf("✅ Removed filter #%s", filterID))
+		fmt.Sprintf("✅ Removed filter #%d", filterID))
 
 	return nil
 }
 
 // ListFiltersCommand lists all regex filters
-type ListFiltersCommand struct{}
+type ListFiltersCommand struct {
+	Filters RegexFilterStore
+}
 
 func (c *ListFiltersCommand) Name() string { return "listfilters" }
 func (c *ListFiltersCommand) Aliases() []string { return []string{"filters", "listregex"} }
@@ -171,52 +224,24 @@ func (c *ListFiltersCommand) Execute(ctx *Context) error {
 		return fmt.Errorf("this command can only be used in a server")
 	}
 
-	type BotDB interface {
-		GetDB() interface{ Query(string, ...interface{}) (interface{}, error) }
-	}
-	botDB := ctx.Bot.(BotDB).GetDB()
-
-	result, err := botDB.Query(`
-		SELECT id, pattern, action, reason, enabled
-		FROM regex_filters
-		WHERE guild_id = ?
-		ORDER BY id
-	`, ctx.Message.GuildID)
-
+	filters, err := c.Filters.GetRegexFilters(ctx.Message.GuildID, "")
 	if err != nil {
 		return fmt.Errorf("failed to fetch filters: %v", err)
 	}
 
-	// Cast result to a Rows-like interface
-	type Rows interface {
-		Close() error
-		Next() bool
-		Scan(...interface{}) error
-	}
-	rows := result.(Rows)
-	defer rows.Close()
-
 	var fields []*discordgo.MessageEmbedField
 	count := 0
 
-	for rows.Next() {
-		var id int
-		var pattern, action, reason string
-		var enabled bool
-
-		if err := rows.Scan(&id, &pattern, &action, &reason, &enabled); err != nil {
-			continue
-		}
-
+	for _, filter := range filters {
 		status := "✅"
-		if !enabled {
+		if !filter.Enabled {
 			status = "❌"
 		}
 
 		fields = append(fields, &discordgo.MessageEmbedField{
-			Name: fmt.Sprintf("%s Filter #%d - %s", status, id, action),
-			Value: fmt.Sprintf("**Pattern:** `%s`\n**Reason:** %s",
-				pattern, reason),
+			Name: fmt.Sprintf("%s Filter #%d - %s", status, filter.ID, filter.Action),
+			Value: fmt.Sprintf("**Pattern:** `%s`\n**Reason:** %s\n**Hits:** %s",
+				filter.Pattern, filter.Reason, formatFilterHits(filter)),
 			Inline: false,
 		})
 		count++
@@ -234,7 +259,7 @@ func (c *ListFiltersCommand) Execute(ctx *Context) error {
 	}
 
 	embed := &discordgo.MessageEmbed{
-		Title:       fmt.Sprintf("Regex Filters (%d total)", count),
+		Title:       fmt.Sprintf("Regex Filters (%d total)", len(filters)),
 		Description: "Use `removefilter <id>` to remove a filter",
 		Fields:      fields,
 		Color:       0xFF51FF,
@@ -243,3 +268,11 @@ func (c *ListFiltersCommand) Execute(ctx *Context) error {
 	ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
 	return nil
 }
+
+// formatFilterHits renders a filter's hit count and when it last matched
+func formatFilterHits(filter RegexFilter) string {
+	if filter.Hits == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%s (last <t:%d:R>)", formatCount(filter.Hits), filter.LastHit.Unix())
+}
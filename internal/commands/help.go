@@ -16,6 +16,9 @@ func (c *HelpCommand) Description() string { return "Show all available commands
 func (c *HelpCommand) Usage() string       { return "help [command]" }
 func (c *HelpCommand) RequiredPermissions() []int64 { return nil }
 func (c *HelpCommand) MasterOnly() bool    { return false }
+func (c *HelpCommand) Arguments() []Argument {
+	return []Argument{{Name: "command", Description: "Command to show details for", Type: ArgString}}
+}
 
 func (c *HelpCommand) Execute(ctx *Context) error {
 	// Get bot prefix from config
@@ -57,7 +60,7 @@ func (c *HelpCommand) Execute(ctx *Context) error {
 		Color:       0xFF51FF,
 		Fields:      []*discordgo.MessageEmbedField{},
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: fmt.Sprintf("Prefix: %s | Total Commands: %d", prefix, len(allCommands)),
+			Text: fmt.Sprintf("Prefix: %s | Total Commands: %d", strings.Join(c.prefixList(ctx, prefix), " "), len(allCommands)),
 		},
 	}
 
@@ -114,7 +117,7 @@ func (c *HelpCommand) Execute(ctx *Context) error {
 	}
 
 	if ctx.Message != nil {
-		_, err := ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+		_, err := ctx.ReplyEmbed(embed)
 		return err
 	}
 
@@ -145,8 +148,7 @@ func (c *HelpCommand) showCommandHelp(ctx *Context, cmdName string, prefix strin
 
 	if targetCmd == nil {
 		if ctx.Message != nil {
-			ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, 
-				fmt.Sprintf("❌ Command `%s` not found. Use `%shelp` to see all commands.", cmdName, prefix))
+			ctx.Reply(fmt.Sprintf("❌ Command `%s` not found. Use `%shelp` to see all commands.", cmdName, prefix))
 		}
 		return nil
 	}
@@ -202,7 +204,7 @@ func (c *HelpCommand) showCommandHelp(ctx *Context, cmdName string, prefix strin
 	}
 
 	if ctx.Message != nil {
-		_, err := ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+		_, err := ctx.ReplyEmbed(embed)
 		return err
 	}
 
@@ -241,3 +243,17 @@ func getPermissionName(perm int64) string {
 		return fmt.Sprintf("Permission %d", perm)
 	}
 }
+
+// prefixList returns every way the command can be invoked in this guild
+func (c *HelpCommand) prefixList(ctx *Context, primary string) []string {
+	if ctx.Interaction != nil || ctx.GetPrefixes == nil {
+		return []string{primary}
+	}
+
+	prefixes := ctx.GetPrefixes()
+	list := append([]string{}, prefixes.Prefixes...)
+	if prefixes.MentionPrefix {
+		list = append(list, "@mention")
+	}
+	return list
+}
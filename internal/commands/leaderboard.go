@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Leaderboard types
+const (
+	LeaderboardAll   = "all"
+	LeaderboardText  = "text"
+	LeaderboardVoice = "voice"
+)
+
+const leaderboardPageSize = 10
+
+// LeaderboardEntry is one ranked member
+type LeaderboardEntry struct {
+	UserID string
+	XP     int64
+	Level  int
+}
+
+// LeaderboardStore reads ranked XP for a guild
+type LeaderboardStore interface {
+	GetLeaderboard(guildID, board string, offset, limit int) ([]LeaderboardEntry, error)
+	CountLeaderboard(guildID, board string) (int, error)
+	GetLeaderboardPosition(guildID, board, userID string) (position int, xp int64, err error)
+}
+
+// LeaderboardCommand shows the top of a guild's XP table
+type LeaderboardCommand struct {
+	Leaderboard LeaderboardStore
+}
+
+func (c *LeaderboardCommand) Name() string                 { return "leaderboard" }
+func (c *LeaderboardCommand) Aliases() []string            { return []string{"lb", "top", "levels"} }
+func (c *LeaderboardCommand) Description() string          { return "Show the server's XP leaderboard" }
+func (c *LeaderboardCommand) Usage() string                { return "leaderboard [all|text|voice] [page|me|@user]" }
+func (c *LeaderboardCommand) RequiredPermissions() []int64 { return nil }
+func (c *LeaderboardCommand) MasterOnly() bool             { return false }
+func (c *LeaderboardCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "type", Description: "Which XP to rank by", Type: ArgString, Choices: []string{
+			LeaderboardAll, LeaderboardText, LeaderboardVoice,
+		}},
+		{Name: "page", Description: "Page to open", Type: ArgInteger},
+		{Name: "user", Description: "Jump to this member's position", Type: ArgUser},
+	}
+}
+
+func (c *LeaderboardCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	board := LeaderboardAll
+	page := 1
+	jumpTo := ""
+
+	for _, arg := range ctx.Args {
+		lower := strings.ToLower(arg)
+		switch {
+		case lower == LeaderboardAll || lower == LeaderboardText || lower == LeaderboardVoice:
+			board = lower
+		case lower == "me":
+			jumpTo = ctx.Message.Author.ID
+		case isSnowflake(parseUserID(arg)):
+			jumpTo = parseUserID(arg)
+		default:
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 1 {
+				ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+				return nil
+			}
+			page = n
+		}
+	}
+
+	if jumpTo != "" {
+		position, _, err := c.Leaderboard.GetLeaderboardPosition(ctx.Message.GuildID, board, jumpTo)
+		if err != nil {
+			return err
+		}
+		if position == 0 {
+			_, err := ctx.Reply(fmt.Sprintf("<@%s> isn't on the %s leaderboard yet.", jumpTo, leaderboardLabel(board)))
+			return err
+		}
+		page = (position-1)/leaderboardPageSize + 1
+	}
+
+	msg, err := c.render(ctx, board, page, jumpTo)
+	if err != nil {
+		return err
+	}
+	_, err = ctx.ReplyComplex(msg)
+	return err
+}
+
+// HandleComponent turns the page for the pagination buttons. data is "<board>:<target>"
+// where target is a page number, "first", "last" or "me".
+func (c *LeaderboardCommand) HandleComponent(ctx *Context, data string) error {
+	board, target, _ := strings.Cut(data, ":")
+	if board != LeaderboardText && board != LeaderboardVoice {
+		board = LeaderboardAll
+	}
+	viewerID := ctx.Message.Author.ID
+	highlight := ""
+
+	page := 1
+	switch target {
+	case "first":
+	case "last":
+		page = int(^uint(0) >> 1) // Clamped to the last page by render
+	case "me":
+		position, _, err := c.Leaderboard.GetLeaderboardPosition(ctx.Message.GuildID, board, viewerID)
+		if err != nil {
+			return err
+		}
+		if position > 0 {
+			page = (position-1)/leaderboardPageSize + 1
+			highlight = viewerID
+		}
+	default:
+		if n, err := strconv.Atoi(target); err == nil {
+			page = n
+		}
+	}
+
+	msg, err := c.render(ctx, board, page, highlight)
+	if err != nil {
+		return err
+	}
+	return ctx.UpdateMessage(msg)
+}
+
+// render builds one leaderboard page with its pagination buttons
+func (c *LeaderboardCommand) render(ctx *Context, board string, page int, highlight string) (*discordgo.MessageSend, error) {
+	guildID := ctx.Message.GuildID
+
+	total, err := c.Leaderboard.CountLeaderboard(guildID, board)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "🏆 Leaderboard"
+	if guild, err := ctx.Session.State.Guild(guildID); err == nil {
+		title += " — " + guild.Name
+	}
+	if board != LeaderboardAll {
+		title += " (" + leaderboardLabel(board) + ")"
+	}
+
+	if total == 0 {
+		return &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{{
+			Title:       title,
+			Description: fmt.Sprintf("No one has earned %s yet~", leaderboardLabel(board)),
+			Color:       0xFF51FF,
+		}}}, nil
+	}
+
+	pages := (total + leaderboardPageSize - 1) / leaderboardPageSize
+	page = max(1, min(page, pages))
+	offset := (page - 1) * leaderboardPageSize
+
+	entries, err := c.Leaderboard.GetLeaderboard(guildID, board, offset, leaderboardPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(entries))
+	for i, entry := range entries {
+		rank := offset + i + 1
+		line := fmt.Sprintf("%s <@%s> — ", leaderboardRank(rank), entry.UserID)
+		if board == LeaderboardAll {
+			line += fmt.Sprintf("Level %d · %s XP", entry.Level, formatCount(entry.XP))
+		} else {
+			line += fmt.Sprintf("%s %s", formatCount(entry.XP), leaderboardLabel(board))
+		}
+		if entry.UserID == highlight {
+			line = "▸ **" + line + "**"
+		}
+		lines = append(lines, line)
+	}
+
+	// Where the person looking at the board stands
+	footer := "You're not ranked yet"
+	if position, _, err := c.Leaderboard.GetLeaderboardPosition(guildID, board, ctx.Message.Author.ID); err == nil && position > 0 {
+		footer = fmt.Sprintf("You are #%s of %s", formatCount(int64(position)), formatCount(int64(total)))
+	}
+	footer += fmt.Sprintf(" · Page %d/%d", page, pages)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: strings.Join(lines, "\n"),
+		Color:       0xFF51FF,
+		Footer:      &discordgo.MessageEmbedFooter{Text: footer},
+	}
+
+	button := func(label, target string, disabled bool) discordgo.Button {
+		return discordgo.Button{
+			Label:    label,
+			Style:    discordgo.SecondaryButton,
+			CustomID: ComponentID(c, board+":"+target),
+			Disabled: disabled,
+		}
+	}
+
+	return &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+				button("⏮", "first", page == 1),
+				button("◀", strconv.Itoa(page-1), page == 1),
+				button("📍 Me", "me", false),
+				button("▶", strconv.Itoa(page+1), page == pages),
+				button("⏭", "last", page == pages),
+			}},
+		},
+	}, nil
+}
+
+// leaderboardLabel names the XP a board ranks by
+func leaderboardLabel(board string) string {
+	switch board {
+	case LeaderboardText:
+		return "text XP"
+	case LeaderboardVoice:
+		return "voice XP"
+	default:
+		return "XP"
+	}
+}
+
+// leaderboardRank renders a position, with medals for the podium
+func leaderboardRank(rank int) string {
+	switch rank {
+	case 1:
+		return "🥇"
+	case 2:
+		return "🥈"
+	case 3:
+		return "🥉"
+	default:
+		return fmt.Sprintf("**#%d**", rank)
+	}
+}
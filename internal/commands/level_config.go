@@ -0,0 +1,332 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/leveling"
+)
+
+// LevelConfigStore reads and writes a guild's level curve
+type LevelConfigStore interface {
+	GetLevelSettings(guildID string) leveling.Settings
+	SetLevelSettings(guildID string, settings leveling.Settings) error
+	RecalculateLevels(guildID string, keepLevels bool) (int, error)
+}
+
+// curvePreviewLevels are the levels shown when describing a curve
+var curvePreviewLevels = []int{1, 5, 10, 20, 30, 50, 100}
+
+// curvePreview lists the total XP needed for a few milestone levels
+func curvePreview(curve leveling.Curve) string {
+	lines := make([]string, 0, len(curvePreviewLevels))
+	for _, level := range curvePreviewLevels {
+		lines = append(lines, fmt.Sprintf("Level %d → %s XP", level, strconv.FormatInt(curve.ExpForLevel(level), 10)))
+	}
+	return "```\n" + strings.Join(lines, "\n") + "\n```"
+}
+
+// LevelConfigCommand picks the XP → level curve for a guild
+type LevelConfigCommand struct {
+	Levels LevelConfigStore
+}
+
+func (c *LevelConfigCommand) Name() string        { return "level-config" }
+func (c *LevelConfigCommand) Aliases() []string   { return []string{"levelconfig", "xpcurve"} }
+func (c *LevelConfigCommand) Description() string { return "Show or change how XP turns into levels" }
+func (c *LevelConfigCommand) Usage() string       { return "level-config [curve] [parameters]" }
+func (c *LevelConfigCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionAdministrator}
+}
+func (c *LevelConfigCommand) MasterOnly() bool { return false }
+func (c *LevelConfigCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "curve", Description: "Level curve", Type: ArgString, Choices: []string{
+			leveling.Triangular, leveling.MEE6, leveling.Linear, leveling.Polynomial,
+		}},
+		{Name: "parameters", Description: "Curve parameters (step, XP per level, or a b c)", Type: ArgString},
+	}
+}
+
+func (c *LevelConfigCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	guildID := ctx.Message.GuildID
+
+	if len(ctx.Args) == 0 {
+		curve := leveling.New(c.Levels.GetLevelSettings(guildID))
+		curves := "`triangular [step]` · `mee6` · `linear [xp per level]` · `polynomial <a> <b> <c>`"
+		_, err := ctx.ReplyEmbed(&discordgo.MessageEmbed{
+			Title:       "📈 Level Curve",
+			Description: curve.Describe() + "\n" + curvePreview(curve) + "\nCurves: " + curves,
+			Color:       0xFF51FF,
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+			},
+		})
+		return err
+	}
+
+	settings, err := leveling.ParseSettings(ctx.Args[0], ctx.Args[1:])
+	if err != nil {
+		ctx.Reply("❌ " + err.Error() + "\nUsage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	if err := c.Levels.SetLevelSettings(guildID, settings); err != nil {
+		ctx.Reply("❌ Failed to save level curve: " + err.Error())
+		return err
+	}
+
+	curve := leveling.New(settings)
+	_, err = ctx.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "✅ Level Curve Updated",
+		Description: curve.Describe() + "\n" + curvePreview(curve),
+		Color:       0x43CC24,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name: "Existing levels",
+				Value: fmt.Sprintf("Stored levels still use the old curve. Run `%srecalc-levels` to recompute them from XP, "+
+					"or `%srecalc-levels keep-levels` to keep levels and adjust XP instead.", ctx.GetPrefix(), ctx.GetPrefix()),
+			},
+		},
+	})
+	return err
+}
+
+// RecalcLevelsCommand rewrites stored levels after a curve change
+type RecalcLevelsCommand struct {
+	Levels LevelConfigStore
+}
+
+func (c *RecalcLevelsCommand) Name() string        { return "recalc-levels" }
+func (c *RecalcLevelsCommand) Aliases() []string   { return []string{"recalclevels", "migrate-levels"} }
+func (c *RecalcLevelsCommand) Description() string { return "Recompute levels for the current curve" }
+func (c *RecalcLevelsCommand) Usage() string       { return "recalc-levels [keep-levels]" }
+func (c *RecalcLevelsCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionAdministrator}
+}
+func (c *RecalcLevelsCommand) MasterOnly() bool { return false }
+func (c *RecalcLevelsCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "mode", Description: "What to keep", Type: ArgString, Choices: []string{"keep-xp", "keep-levels"}},
+	}
+}
+
+func (c *RecalcLevelsCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	keepLevels := false
+	if len(ctx.Args) > 0 {
+		switch strings.ToLower(ctx.Args[0]) {
+		case "keep-levels", "levels":
+			keepLevels = true
+		case "keep-xp", "xp":
+		default:
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+			return nil
+		}
+	}
+
+	changed, err := c.Levels.RecalculateLevels(ctx.Message.GuildID, keepLevels)
+	if err != nil {
+		ctx.Reply("❌ Failed to recalculate levels: " + err.Error())
+		return err
+	}
+
+	mode := "Levels were recomputed from each member's XP."
+	if keepLevels {
+		mode = "Levels were kept; XP was moved to the start of each member's level."
+	}
+
+	_, err = ctx.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "✅ Levels Recalculated",
+		Description: fmt.Sprintf("%s\nUpdated **%d** members.", mode, changed),
+		Color:       0x43CC24,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Run " + ctx.GetPrefix() + "apply-ranks to update level roles",
+		},
+	})
+	return err
+}
+
+// Level-up announcement modes
+const (
+	LevelUpChannel = "channel" // A fixed channel
+	LevelUpHere    = "here"    // Wherever the member earned the level
+	LevelUpDM      = "dm"      // Direct message to the member
+	LevelUpOff     = "off"     // No announcement
+)
+
+// Limits for per-guild message XP settings
+const (
+	maxMessageXP  = 1000
+	maxXPCooldown = 3600
+)
+
+// XPSettings controls how a guild earns message XP and hears about level-ups
+type XPSettings struct {
+	GuildID          string
+	MinXP            int
+	MaxXP            int
+	CooldownSeconds  int
+	LevelUpMode      string
+	LevelUpChannelID string
+}
+
+// XPSettingsStore reads and writes a guild's message XP settings
+type XPSettingsStore interface {
+	GetXPSettings(guildID string) *XPSettings
+	SaveXPSettings(settings *XPSettings) error
+	ResetXPSettings(guildID string) error
+}
+
+// XPSettingsCommand overrides the [leveling] config for a guild
+type XPSettingsCommand struct {
+	Settings XPSettingsStore
+}
+
+func (c *XPSettingsCommand) Name() string        { return "xp-settings" }
+func (c *XPSettingsCommand) Aliases() []string   { return []string{"xpsettings", "xp-config"} }
+func (c *XPSettingsCommand) Description() string { return "Configure message XP and level-up messages" }
+func (c *XPSettingsCommand) Usage() string {
+	return "xp-settings [range <min> [max]|cooldown <seconds>|levelup <here|dm|off|#channel>|reset]"
+}
+func (c *XPSettingsCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *XPSettingsCommand) MasterOnly() bool { return false }
+
+func (c *XPSettingsCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	guildID := ctx.Message.GuildID
+	settings := c.Settings.GetXPSettings(guildID)
+
+	if len(ctx.Args) == 0 {
+		return c.showStatus(ctx, settings)
+	}
+
+	subcommand := strings.ToLower(ctx.Args[0])
+	values := ctx.Args[1:]
+	var result string
+
+	switch subcommand {
+	case "range", "xp":
+		if len(values) == 0 || len(values) > 2 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "xp-settings range <min> [max]`")
+			return nil
+		}
+		minXP, err := strconv.Atoi(values[0])
+		maxXP := minXP
+		if err == nil && len(values) == 2 {
+			maxXP, err = strconv.Atoi(values[1])
+		}
+		if err != nil || minXP < 1 || maxXP < minXP || maxXP > maxMessageXP {
+			ctx.Reply(fmt.Sprintf("❌ XP must be between 1 and %d, with min ≤ max", maxMessageXP))
+			return nil
+		}
+		settings.MinXP, settings.MaxXP = minXP, maxXP
+		result = fmt.Sprintf("Messages now earn **%s** XP", xpRange(settings))
+
+	case "cooldown":
+		if len(values) != 1 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "xp-settings cooldown <seconds>`")
+			return nil
+		}
+		seconds, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(values[0]), "s"))
+		if err != nil || seconds < 0 || seconds > maxXPCooldown {
+			ctx.Reply(fmt.Sprintf("❌ Cooldown must be between 0 and %d seconds", maxXPCooldown))
+			return nil
+		}
+		settings.CooldownSeconds = seconds
+		result = fmt.Sprintf("XP cooldown set to **%ds** per member", seconds)
+
+	case "levelup", "level-up", "announce":
+		if len(values) != 1 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "xp-settings levelup <here|dm|off|#channel>`")
+			return nil
+		}
+		switch mode := strings.ToLower(values[0]); mode {
+		case LevelUpHere, LevelUpDM:
+			settings.LevelUpMode, settings.LevelUpChannelID = mode, ""
+		case LevelUpOff, "none", "silent":
+			settings.LevelUpMode, settings.LevelUpChannelID = LevelUpOff, ""
+		default:
+			channelID := parseChannelID(values[0])
+			channel, err := ctx.Session.Channel(channelID)
+			if err != nil || channel.GuildID != guildID {
+				ctx.Reply("❌ Invalid channel! Please mention a valid channel in this server.")
+				return nil
+			}
+			settings.LevelUpMode, settings.LevelUpChannelID = LevelUpChannel, channelID
+		}
+		result = "Level-up messages: " + levelUpTarget(settings)
+
+	case "reset", "default":
+		if err := c.Settings.ResetXPSettings(guildID); err != nil {
+			ctx.Reply("❌ Failed to reset XP settings: " + err.Error())
+			return err
+		}
+		_, err := ctx.Reply("✅ XP settings reset to the bot defaults")
+		return err
+
+	default:
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	if err := c.Settings.SaveXPSettings(settings); err != nil {
+		ctx.Reply("❌ Failed to save XP settings: " + err.Error())
+		return err
+	}
+
+	_, err := ctx.Reply("✅ " + result)
+	return err
+}
+
+func (c *XPSettingsCommand) showStatus(ctx *Context, settings *XPSettings) error {
+	_, err := ctx.ReplyEmbed(&discordgo.MessageEmbed{
+		Title: "XP Settings",
+		Color: 0xFF51FF,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "XP per message", Value: xpRange(settings), Inline: true},
+			{Name: "Cooldown", Value: fmt.Sprintf("%ds", settings.CooldownSeconds), Inline: true},
+			{Name: "Level-up messages", Value: levelUpTarget(settings), Inline: true},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	})
+	return err
+}
+
+// xpRange renders the message XP range
+func xpRange(settings *XPSettings) string {
+	if settings.MinXP == settings.MaxXP {
+		return strconv.Itoa(settings.MinXP)
+	}
+	return fmt.Sprintf("%d–%d", settings.MinXP, settings.MaxXP)
+}
+
+// levelUpTarget describes where level-up messages go
+func levelUpTarget(settings *XPSettings) string {
+	switch settings.LevelUpMode {
+	case LevelUpChannel:
+		return fmt.Sprintf("<#%s>", settings.LevelUpChannelID)
+	case LevelUpDM:
+		return "Direct message"
+	case LevelUpOff:
+		return "Off"
+	default:
+		return "Current channel"
+	}
+}
@@ -0,0 +1,299 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Link rule kinds
+const (
+	LinkAllow = "allow"
+	LinkBlock = "block"
+)
+
+// LinkAnyDomain is the catch-all domain; "block *" turns a scope into an allowlist
+const LinkAnyDomain = "*"
+
+// maxLinkRules keeps rule lists small enough to check on every message
+const maxLinkRules = 200
+
+// LinkFilterSettings holds a guild's invite and link filter options
+type LinkFilterSettings struct {
+	GuildID      string
+	Enabled      bool
+	InviteAction string // Action for invites to other servers ("off" allows them)
+	LinkAction   string // Action for links to blocked domains
+}
+
+// LinkRule allows or blocks a domain and its subdomains, guild-wide or in one channel
+type LinkRule struct {
+	GuildID   string
+	ChannelID string // Empty for guild-wide rules
+	Domain    string
+	Kind      string
+}
+
+// LinkFilterStore reads and writes link filter settings and domain rules
+type LinkFilterStore interface {
+	GetLinkFilterSettings(guildID string) *LinkFilterSettings
+	SaveLinkFilterSettings(settings *LinkFilterSettings) error
+	GetLinkRules(guildID string) []LinkRule
+	SetLinkRule(rule LinkRule) error
+	RemoveLinkRule(guildID, channelID, domain string) (bool, error)
+}
+
+// NormaliseDomain turns "https://www.Example.com/path" into "example.com".
+// It returns "" if the input isn't a domain.
+func NormaliseDomain(input string) string {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == LinkAnyDomain {
+		return input
+	}
+	if !strings.Contains(input, "://") {
+		input = "http://" + input
+	}
+
+	parsed, err := url.Parse(input)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(parsed.Hostname(), ".")
+	host = strings.TrimPrefix(host, "www.")
+	if !strings.Contains(host, ".") || strings.ContainsAny(host, " *") {
+		return ""
+	}
+	return host
+}
+
+// domainMatches reports whether domain is ruleDomain or one of its subdomains
+func domainMatches(ruleDomain, domain string) bool {
+	return ruleDomain == LinkAnyDomain || domain == ruleDomain || strings.HasSuffix(domain, "."+ruleDomain)
+}
+
+// ResolveLinkRule finds the rule that decides a domain in a channel. The most
+// specific domain wins; on a tie, a channel rule beats a guild-wide one.
+// It returns nil when no rule matches.
+func ResolveLinkRule(rules []LinkRule, channelID, domain string) *LinkRule {
+	var best *LinkRule
+	bestScore := -1
+	for i := range rules {
+		rule := &rules[i]
+		if rule.ChannelID != "" && rule.ChannelID != channelID {
+			continue
+		}
+		if !domainMatches(rule.Domain, domain) {
+			continue
+		}
+
+		score := 0
+		if rule.Domain != LinkAnyDomain {
+			score = 2 * (strings.Count(rule.Domain, ".") + 1)
+		}
+		if rule.ChannelID != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = rule, score
+		}
+	}
+	return best
+}
+
+// LinkFilterCommand configures invite and link filtering
+type LinkFilterCommand struct {
+	Links LinkFilterStore
+}
+
+func (c *LinkFilterCommand) Name() string        { return "link-filter" }
+func (c *LinkFilterCommand) Aliases() []string   { return []string{"linkfilter", "links"} }
+func (c *LinkFilterCommand) Description() string { return "Filter server invites and links by domain" }
+func (c *LinkFilterCommand) Usage() string {
+	return "link-filter [on|off|invites off|warn|delete|ban|action warn|delete|ban|allow <domain> [#channel]|block <domain|*> [#channel]|remove <domain> [#channel]]"
+}
+func (c *LinkFilterCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *LinkFilterCommand) MasterOnly() bool { return false }
+
+func (c *LinkFilterCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	guildID := ctx.Message.GuildID
+	settings := c.Links.GetLinkFilterSettings(guildID)
+
+	if len(ctx.Args) == 0 {
+		return c.showStatus(ctx, settings)
+	}
+
+	subcommand := strings.ToLower(ctx.Args[0])
+	value := strings.ToLower(strings.TrimSpace(strings.Join(ctx.Args[1:], " ")))
+	var result string
+
+	switch subcommand {
+	case "on", "off", "enable", "disable":
+		settings.Enabled, _ = parseToggle(subcommand)
+		result = "Link filter " + enabledWord(settings.Enabled)
+
+	case "invites", "invite":
+		if value != "off" && !isFilterAction(value) {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "link-filter invites off|warn|delete|ban`")
+			return nil
+		}
+		settings.InviteAction = value
+		if value == "off" {
+			result = "Invites to other servers are allowed"
+		} else {
+			result = "Invites to other servers: " + value
+		}
+
+	case "action":
+		if !isFilterAction(value) {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "link-filter action warn|delete|ban`")
+			return nil
+		}
+		settings.LinkAction = value
+		result = "Blocked links: " + value
+
+	case "allow", "block", "remove", "unblock":
+		return c.editRule(ctx, subcommand)
+
+	default:
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	if err := c.Links.SaveLinkFilterSettings(settings); err != nil {
+		ctx.Reply("❌ Failed to save link filter settings: " + err.Error())
+		return err
+	}
+
+	_, err := ctx.Reply("✅ " + result)
+	return err
+}
+
+// editRule adds or removes a domain rule
+func (c *LinkFilterCommand) editRule(ctx *Context, subcommand string) error {
+	if len(ctx.Args) < 2 || len(ctx.Args) > 3 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "link-filter " + subcommand + " <domain> [#channel]`")
+		return nil
+	}
+
+	domain := NormaliseDomain(ctx.Args[1])
+	if domain == "" {
+		ctx.Reply("❌ That doesn't look like a domain. Try something like `youtube.com` or `*`")
+		return nil
+	}
+
+	channelID := ""
+	scope := "server-wide"
+	if len(ctx.Args) == 3 {
+		channelID = parseChannelID(ctx.Args[2])
+		if !isSnowflake(channelID) {
+			ctx.Reply("❌ Please mention a channel or give its ID")
+			return nil
+		}
+		scope = "in <#" + channelID + ">"
+	}
+
+	guildID := ctx.Message.GuildID
+	if subcommand == "remove" || subcommand == "unblock" {
+		removed, err := c.Links.RemoveLinkRule(guildID, channelID, domain)
+		if err != nil {
+			ctx.Reply("❌ Failed to remove rule: " + err.Error())
+			return err
+		}
+		if !removed {
+			ctx.Reply(fmt.Sprintf("There's no rule for `%s` %s", domain, scope))
+			return nil
+		}
+		_, err = ctx.Reply(fmt.Sprintf("✅ Removed the rule for `%s` %s", domain, scope))
+		return err
+	}
+
+	if len(c.Links.GetLinkRules(guildID)) >= maxLinkRules {
+		ctx.Reply(fmt.Sprintf("❌ This server already has %d link rules", maxLinkRules))
+		return nil
+	}
+
+	rule := LinkRule{GuildID: guildID, ChannelID: channelID, Domain: domain, Kind: subcommand}
+	if err := c.Links.SetLinkRule(rule); err != nil {
+		ctx.Reply("❌ Failed to save rule: " + err.Error())
+		return err
+	}
+
+	if domain == LinkAnyDomain {
+		if subcommand == LinkBlock {
+			_, err := ctx.Reply("✅ Links are now blocked " + scope + " unless their domain is allowed")
+			return err
+		}
+		_, err := ctx.Reply("✅ Links are now allowed " + scope + " unless their domain is blocked")
+		return err
+	}
+	_, err := ctx.Reply(fmt.Sprintf("✅ `%s` is now %sed %s", domain, subcommand, scope))
+	return err
+}
+
+func (c *LinkFilterCommand) showStatus(ctx *Context, settings *LinkFilterSettings) error {
+	rules := c.Links.GetLinkRules(ctx.Message.GuildID)
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].ChannelID != rules[j].ChannelID {
+			return rules[i].ChannelID < rules[j].ChannelID
+		}
+		return rules[i].Domain < rules[j].Domain
+	})
+
+	var allowed, blocked []string
+	for _, rule := range rules {
+		line := "`" + rule.Domain + "`"
+		if rule.ChannelID != "" {
+			line += " in <#" + rule.ChannelID + ">"
+		}
+		if rule.Kind == LinkAllow {
+			allowed = append(allowed, line)
+		} else {
+			blocked = append(blocked, line)
+		}
+	}
+
+	invites := "Allowed"
+	if settings.InviteAction != "off" {
+		invites = settings.InviteAction
+	}
+
+	listOrNone := func(lines []string) string {
+		if len(lines) == 0 {
+			return "None"
+		}
+		return truncateField(strings.Join(lines, "\n"))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Link Filter",
+		Description: "Invites to this server are always allowed. For links, the most specific matching domain rule wins, and channel rules beat server-wide ones.",
+		Color:       0xFF51FF,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Status", Value: onOff(settings.Enabled), Inline: true},
+			{Name: "Other Servers' Invites", Value: invites, Inline: true},
+			{Name: "Blocked Links", Value: settings.LinkAction, Inline: true},
+			{Name: "Allowed Domains", Value: listOrNone(allowed), Inline: false},
+			{Name: "Blocked Domains", Value: listOrNone(blocked), Inline: false},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
+
+// isFilterAction reports whether action is one regex and link filters can take
+func isFilterAction(action string) bool {
+	return action == "warn" || action == "delete" || action == "ban"
+}
@@ -2,25 +2,128 @@ package commands
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-// LoggingConfigInterface defines what logging commands need from the bot
-type LoggingConfigInterface interface {
-	GetDB() DBInterface
-	GetLoggingConfig(guildID string) (interface{}, error)
+// LoggingStore runs the logging commands' SQL; cached configs must be
+// invalidated after a change
+type LoggingStore interface {
+	DBInterface
+	InvalidateLoggingConfigCache(guildID string)
+}
+
+// logType is a loggable event, stored as a logging_config column
+type logType struct {
+	Names        []string // First is the one shown in usage
+	Column       string
+	FriendlyName string
+}
+
+var logTypes = []logType{
+	{[]string{"message_delete", "msgdelete", "delete"}, "message_delete", "Message Deletion"},
+	{[]string{"message_edit", "msgedit", "edit"}, "message_edit", "Message Editing"},
+	{[]string{"voice_join", "join", "voicejoin"}, "member_join_voice", "Voice Channel Join"},
+	{[]string{"voice_leave", "leave", "voiceleave"}, "member_leave_voice", "Voice Channel Leave"},
+	{[]string{"nickname", "nick"}, "nickname_change", "Nickname Changes"},
+	{[]string{"avatar", "pfp"}, "avatar_change", "Avatar Changes"},
+	{[]string{"presence", "status"}, "presence_change", "Presence Changes"},
+	{[]string{"member_join", "memberjoin", "joins"}, "member_join", "Member Joins"},
+	{[]string{"member_leave", "memberleave", "leaves"}, "member_leave", "Member Leaves"},
+	{[]string{"ban", "bans"}, "member_ban", "Bans"},
+	{[]string{"unban", "unbans"}, "member_unban", "Unbans"},
+	{[]string{"member_roles", "memberroles", "grants"}, "member_role_change", "Role Grants & Removals"},
+	{[]string{"roles", "role"}, "role_change", "Role Changes"},
+	{[]string{"channels", "channel"}, "channel_change", "Channel Changes"},
+	{[]string{"invites", "invite"}, "invite_change", "Invite Changes"},
+	{[]string{"emojis", "emoji"}, "emoji_change", "Emoji Changes"},
+	{[]string{"server", "guild"}, "guild_update", "Server Setting Changes"},
+}
+
+// lookupLogType finds a log type by any of its names
+func lookupLogType(name string) (logType, bool) {
+	name = strings.ToLower(name)
+	for _, t := range logTypes {
+		for _, n := range t.Names {
+			if n == name {
+				return t, true
+			}
+		}
+	}
+	return logType{}, false
+}
+
+// logTypeNames lists the main name of every log type
+func logTypeNames() string {
+	names := make([]string, len(logTypes))
+	for i, t := range logTypes {
+		names[i] = t.Names[0]
+	}
+	return strings.Join(names, ", ")
+}
+
+// LogModActions routes mod cases, observe reports and raid alerts. It can
+// have its own channel but isn't a log type that can be switched off.
+const LogModActions = "mod_actions"
+
+// logGroups route several log types at once with setlogchannel
+var logGroups = []struct {
+	Name    string
+	Label   string
+	Columns []string
+}{
+	{"messages", "Message logs", []string{"message_delete", "message_edit"}},
+	{"voice", "Voice logs", []string{"member_join_voice", "member_leave_voice"}},
+	{"members", "Member logs", []string{"member_join", "member_leave", "nickname_change", "avatar_change", "member_role_change"}},
+	{"mod", "Mod logs", []string{"member_ban", "member_unban", LogModActions}},
+	{"audit", "Server change logs", []string{"role_change", "channel_change", "invite_change", "emoji_change", "guild_update"}},
+}
+
+// lookupLogRoute finds the log types a group or single type routes
+func lookupLogRoute(name string) ([]string, string, bool) {
+	name = strings.ToLower(name)
+	for _, g := range logGroups {
+		if g.Name == name {
+			return g.Columns, g.Label, true
+		}
+	}
+	switch name {
+	case LogModActions, "modactions", "cases":
+		return []string{LogModActions}, "Mod actions", true
+	}
+	if t, ok := lookupLogType(name); ok {
+		return []string{t.Column}, t.FriendlyName + " logs", true
+	}
+	return nil, "", false
+}
+
+// logRouteName names a routed log type for logstatus
+func logRouteName(column string) string {
+	if column == LogModActions {
+		return "Mod Actions"
+	}
+	for _, t := range logTypes {
+		if t.Column == column {
+			return t.FriendlyName
+		}
+	}
+	return column
 }
 
 // SetLogChannelCommand sets the log channel for a guild
-type SetLogChannelCommand struct{}
+type SetLogChannelCommand struct {
+	Store LoggingStore
+}
 
 func (c *SetLogChannelCommand) Name() string        { return "setlogchannel" }
 func (c *SetLogChannelCommand) Aliases() []string   { return []string{"logchannel"} }
 func (c *SetLogChannelCommand) Description() string { return "Set the channel for logging events" }
-func (c *SetLogChannelCommand) Usage() string       { return "setlogchannel <#channel>" }
+func (c *SetLogChannelCommand) Usage() string {
+	return "setlogchannel <#channel> [type|group]\nsetlogchannel default <type|group>\nGroups: messages, voice, members, mod, audit (or mod_actions and any configlog type)"
+}
 func (c *SetLogChannelCommand) RequiredPermissions() []int64 {
 	return []int64{discordgo.PermissionAdministrator}
 }
@@ -36,6 +139,10 @@ func (c *SetLogChannelCommand) Execute(ctx *Context) error {
 		return nil
 	}
 
+	if len(ctx.Args) >= 2 {
+		return c.route(ctx)
+	}
+
 	// Parse channel mention
 	channelID := strings.Trim(ctx.Args[0], "<>#")
 
@@ -47,7 +154,7 @@ func (c *SetLogChannelCommand) Execute(ctx *Context) error {
 	}
 
 	// Get database
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
+	db := c.Store
 
 	// Update or insert logging config
 	_, err = db.Exec(`
@@ -62,12 +169,72 @@ func (c *SetLogChannelCommand) Execute(ctx *Context) error {
 		return err
 	}
 
+	db.InvalidateLoggingConfigCache(ctx.Message.GuildID)
+
 	ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, fmt.Sprintf("Logging channel set to <#%s>! Logging is now enabled.", channelID))
 	return nil
 }
 
+// route sends some log types to their own channel, or back to the default one
+func (c *SetLogChannelCommand) route(ctx *Context) error {
+	columns, label, ok := lookupLogRoute(ctx.Args[1])
+	if !ok {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Invalid log type! Use a group (messages, voice, members, mod, audit), mod_actions, or: "+logTypeNames())
+		return nil
+	}
+
+	db := c.Store
+	guildID := ctx.Message.GuildID
+
+	switch strings.ToLower(ctx.Args[0]) {
+	case "default", "reset":
+		for _, column := range columns {
+			_, err := db.Exec(`DELETE FROM logging_type_channels WHERE guild_id = ? AND log_type = ?`, guildID, column)
+			if err != nil {
+				ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Failed to reset log channel: "+err.Error())
+				return err
+			}
+		}
+		db.InvalidateLoggingConfigCache(guildID)
+
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, fmt.Sprintf("%s will go to the default log channel!", label))
+		return nil
+	}
+
+	channelID := strings.Trim(ctx.Args[0], "<>#")
+	channel, err := ctx.Session.Channel(channelID)
+	if err != nil || channel.GuildID != guildID {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Invalid channel! Please mention a valid channel in this server.")
+		return nil
+	}
+
+	// A guild may route everything without ever setting a default channel
+	if _, err := db.Exec(`INSERT OR IGNORE INTO logging_config (guild_id) VALUES (?)`, guildID); err != nil {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Failed to set log channel: "+err.Error())
+		return err
+	}
+	for _, column := range columns {
+		_, err := db.Exec(`
+			INSERT INTO logging_type_channels (guild_id, log_type, channel_id)
+			VALUES (?, ?, ?)
+			ON CONFLICT(guild_id, log_type) DO UPDATE SET channel_id = ?`,
+			guildID, column, channelID, channelID,
+		)
+		if err != nil {
+			ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Failed to set log channel: "+err.Error())
+			return err
+		}
+	}
+	db.InvalidateLoggingConfigCache(guildID)
+
+	ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, fmt.Sprintf("%s will be sent to <#%s>!", label, channelID))
+	return nil
+}
+
 // ToggleLoggingCommand enables or disables logging for a guild
-type ToggleLoggingCommand struct{}
+type ToggleLoggingCommand struct {
+	Store LoggingStore
+}
 
 func (c *ToggleLoggingCommand) Name() string        { return "togglelogging" }
 func (c *ToggleLoggingCommand) Aliases() []string   { return []string{"logging"} }
@@ -99,7 +266,7 @@ func (c *ToggleLoggingCommand) Execute(ctx *Context) error {
 		return nil
 	}
 
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
+	db := c.Store
 
 	_, err := db.Exec(`
 		INSERT INTO logging_config (guild_id, enabled)
@@ -113,6 +280,8 @@ func (c *ToggleLoggingCommand) Execute(ctx *Context) error {
 		return err
 	}
 
+	db.InvalidateLoggingConfigCache(ctx.Message.GuildID)
+
 	status := "disabled"
 	if enabled == 1 {
 		status = "enabled"
@@ -122,8 +291,132 @@ func (c *ToggleLoggingCommand) Execute(ctx *Context) error {
 	return nil
 }
 
+// LogWebhooksCommand switches log delivery between webhooks and the bot account
+type LogWebhooksCommand struct {
+	Store LoggingStore
+}
+
+func (c *LogWebhooksCommand) Name() string      { return "logwebhooks" }
+func (c *LogWebhooksCommand) Aliases() []string { return []string{"logwebhook"} }
+func (c *LogWebhooksCommand) Description() string {
+	return "Post logs through webhooks so they don't slow down command replies"
+}
+func (c *LogWebhooksCommand) Usage() string { return "logwebhooks <on|off>" }
+func (c *LogWebhooksCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionAdministrator}
+}
+func (c *LogWebhooksCommand) MasterOnly() bool { return false }
+
+func (c *LogWebhooksCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) < 1 {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Usage: "+c.Usage())
+		return nil
+	}
+
+	enabled, ok := parseToggle(ctx.Args[0])
+	if !ok {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Usage: "+c.Usage())
+		return nil
+	}
+
+	useWebhooks := 0
+	if enabled {
+		useWebhooks = 1
+	}
+
+	db := c.Store
+
+	_, err := db.Exec(`
+		INSERT INTO logging_config (guild_id, use_webhooks)
+		VALUES (?, ?)
+		ON CONFLICT(guild_id) DO UPDATE SET use_webhooks = ?`,
+		ctx.Message.GuildID, useWebhooks, useWebhooks,
+	)
+
+	if err != nil {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Failed to change log delivery: "+err.Error())
+		return err
+	}
+
+	db.InvalidateLoggingConfigCache(ctx.Message.GuildID)
+
+	if enabled {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Logs will be posted through a webhook in each log channel! I need **Manage Webhooks** there, otherwise I'll keep posting them myself.")
+	} else {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Logs will be posted by the bot again!")
+	}
+	return nil
+}
+
+// LogAttachmentsCommand toggles archiving attachments for deletion logs
+type LogAttachmentsCommand struct {
+	Store LoggingStore
+}
+
+func (c *LogAttachmentsCommand) Name() string      { return "logattachments" }
+func (c *LogAttachmentsCommand) Aliases() []string { return []string{"archiveattachments"} }
+func (c *LogAttachmentsCommand) Description() string {
+	return "Keep copies of attachments so deleted ones can be re-uploaded to the logs"
+}
+func (c *LogAttachmentsCommand) Usage() string { return "logattachments <on|off>" }
+func (c *LogAttachmentsCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionAdministrator}
+}
+func (c *LogAttachmentsCommand) MasterOnly() bool { return false }
+
+func (c *LogAttachmentsCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) < 1 {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Usage: "+c.Usage())
+		return nil
+	}
+
+	enabled, ok := parseToggle(ctx.Args[0])
+	if !ok {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Usage: "+c.Usage())
+		return nil
+	}
+
+	archive := 0
+	if enabled {
+		archive = 1
+	}
+
+	db := c.Store
+
+	_, err := db.Exec(`
+		INSERT INTO logging_config (guild_id, archive_attachments)
+		VALUES (?, ?)
+		ON CONFLICT(guild_id) DO UPDATE SET archive_attachments = ?`,
+		ctx.Message.GuildID, archive, archive,
+	)
+
+	if err != nil {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Failed to change attachment archiving: "+err.Error())
+		return err
+	}
+
+	db.InvalidateLoggingConfigCache(ctx.Message.GuildID)
+
+	if enabled {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Attachments will be kept for up to 7 days (8 MB per message) and re-uploaded when their message is deleted!")
+	} else {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Attachments won't be archived anymore. Copies already kept expire on their own.")
+	}
+	return nil
+}
+
 // ConfigureLogTypeCommand configures specific log types
-type ConfigureLogTypeCommand struct{}
+type ConfigureLogTypeCommand struct {
+	Store LoggingStore
+}
 
 func (c *ConfigureLogTypeCommand) Name() string      { return "configlog" }
 func (c *ConfigureLogTypeCommand) Aliases() []string { return []string{"logconfig"} }
@@ -131,7 +424,7 @@ func (c *ConfigureLogTypeCommand) Description() string {
 	return "Configure specific logging types"
 }
 func (c *ConfigureLogTypeCommand) Usage() string {
-	return "configlog <type> <on|off>\nTypes: message_delete, message_edit, voice_join, voice_leave, nickname, avatar, presence"
+	return "configlog <type> <on|off>\nTypes: " + logTypeNames()
 }
 func (c *ConfigureLogTypeCommand) RequiredPermissions() []int64 {
 	return []int64{discordgo.PermissionAdministrator}
@@ -148,7 +441,6 @@ func (c *ConfigureLogTypeCommand) Execute(ctx *Context) error {
 		return nil
 	}
 
-	logType := strings.ToLower(ctx.Args[0])
 	enabled := 0
 	switch strings.ToLower(ctx.Args[1]) {
 	case "on", "enable", "enabled", "true", "1":
@@ -160,37 +452,14 @@ func (c *ConfigureLogTypeCommand) Execute(ctx *Context) error {
 		return nil
 	}
 
-	// Map friendly names to database columns
-	columnName := ""
-	friendlyName := ""
-	switch logType {
-	case "message_delete", "msgdelete", "delete":
-		columnName = "message_delete"
-		friendlyName = "Message Deletion"
-	case "message_edit", "msgedit", "edit":
-		columnName = "message_edit"
-		friendlyName = "Message Editing"
-	case "voice_join", "join", "voicejoin":
-		columnName = "member_join_voice"
-		friendlyName = "Voice Channel Join"
-	case "voice_leave", "leave", "voiceleave":
-		columnName = "member_leave_voice"
-		friendlyName = "Voice Channel Leave"
-	case "nickname", "nick":
-		columnName = "nickname_change"
-		friendlyName = "Nickname Changes"
-	case "avatar", "pfp":
-		columnName = "avatar_change"
-		friendlyName = "Avatar Changes"
-	case "presence", "status":
-		columnName = "presence_change"
-		friendlyName = "Presence Changes"
-	default:
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Invalid log type! Use: message_delete, message_edit, voice_join, voice_leave, nickname, avatar, or presence")
+	t, ok := lookupLogType(ctx.Args[0])
+	if !ok {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Invalid log type! Use: "+logTypeNames())
 		return nil
 	}
+	columnName, friendlyName := t.Column, t.FriendlyName
 
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
+	db := c.Store
 
 	query := fmt.Sprintf(`
 		INSERT INTO logging_config (guild_id, %s)
@@ -205,6 +474,8 @@ func (c *ConfigureLogTypeCommand) Execute(ctx *Context) error {
 		return err
 	}
 
+	db.InvalidateLoggingConfigCache(ctx.Message.GuildID)
+
 	status := "disabled"
 	if enabled == 1 {
 		status = "enabled"
@@ -215,7 +486,9 @@ func (c *ConfigureLogTypeCommand) Execute(ctx *Context) error {
 }
 
 // SetPresenceBatchCommand sets the presence batch interval
-type SetPresenceBatchCommand struct{}
+type SetPresenceBatchCommand struct {
+	Store LoggingStore
+}
 
 func (c *SetPresenceBatchCommand) Name() string        { return "setpresencebatch" }
 func (c *SetPresenceBatchCommand) Aliases() []string   { return []string{"presencebatch"} }
@@ -244,7 +517,7 @@ func (c *SetPresenceBatchCommand) Execute(ctx *Context) error {
 		return nil
 	}
 
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
+	db := c.Store
 
 	_, err = db.Exec(`
 		INSERT INTO logging_config (guild_id, presence_batch_seconds)
@@ -258,12 +531,16 @@ func (c *SetPresenceBatchCommand) Execute(ctx *Context) error {
 		return err
 	}
 
+	db.InvalidateLoggingConfigCache(ctx.Message.GuildID)
+
 	ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, fmt.Sprintf("Presence batch interval set to %d seconds!", seconds))
 	return nil
 }
 
 // DisableChannelLoggingCommand disables specific logging types for a channel
-type DisableChannelLoggingCommand struct{}
+type DisableChannelLoggingCommand struct {
+	Store LoggingStore
+}
 
 func (c *DisableChannelLoggingCommand) Name() string      { return "disablechannellog" }
 func (c *DisableChannelLoggingCommand) Aliases() []string { return []string{"nolog"} }
@@ -271,7 +548,7 @@ func (c *DisableChannelLoggingCommand) Description() string {
 	return "Disable specific logging types for a channel"
 }
 func (c *DisableChannelLoggingCommand) Usage() string {
-	return "disablechannellog <#channel> <type>\nTypes: message_delete, message_edit, voice_join, voice_leave, nickname, avatar, presence"
+	return "disablechannellog <#channel> <type>\nTypes: " + logTypeNames()
 }
 func (c *DisableChannelLoggingCommand) RequiredPermissions() []int64 {
 	return []int64{discordgo.PermissionAdministrator}
@@ -298,39 +575,14 @@ func (c *DisableChannelLoggingCommand) Execute(ctx *Context) error {
 		return nil
 	}
 
-	logType := strings.ToLower(ctx.Args[1])
-
-	// Map friendly names to database columns
-	columnName := ""
-	friendlyName := ""
-	switch logType {
-	case "message_delete", "msgdelete", "delete":
-		columnName = "message_delete"
-		friendlyName = "Message Deletion"
-	case "message_edit", "msgedit", "edit":
-		columnName = "message_edit"
-		friendlyName = "Message Editing"
-	case "voice_join", "join", "voicejoin":
-		columnName = "member_join_voice"
-		friendlyName = "Voice Channel Join"
-	case "voice_leave", "leave", "voiceleave":
-		columnName = "member_leave_voice"
-		friendlyName = "Voice Channel Leave"
-	case "nickname", "nick":
-		columnName = "nickname_change"
-		friendlyName = "Nickname Changes"
-	case "avatar", "pfp":
-		columnName = "avatar_change"
-		friendlyName = "Avatar Changes"
-	case "presence", "status":
-		columnName = "presence_change"
-		friendlyName = "Presence Changes"
-	default:
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Invalid log type! Use: message_delete, message_edit, voice_join, voice_leave, nickname, avatar, or presence")
+	t, ok := lookupLogType(ctx.Args[1])
+	if !ok {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Invalid log type! Use: "+logTypeNames())
 		return nil
 	}
+	columnName, friendlyName := t.Column, t.FriendlyName
 
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
+	db := c.Store
 
 	query := fmt.Sprintf(`
 		INSERT INTO logging_channel_overrides (guild_id, channel_id, %s)
@@ -350,7 +602,9 @@ func (c *DisableChannelLoggingCommand) Execute(ctx *Context) error {
 }
 
 // EnableChannelLoggingCommand enables specific logging types for a channel
-type EnableChannelLoggingCommand struct{}
+type EnableChannelLoggingCommand struct {
+	Store LoggingStore
+}
 
 func (c *EnableChannelLoggingCommand) Name() string      { return "enablechannellog" }
 func (c *EnableChannelLoggingCommand) Aliases() []string { return []string{"yeslog"} }
@@ -358,7 +612,7 @@ func (c *EnableChannelLoggingCommand) Description() string {
 	return "Enable specific logging types for a channel"
 }
 func (c *EnableChannelLoggingCommand) Usage() string {
-	return "enablechannellog <#channel> <type>\nTypes: message_delete, message_edit, voice_join, voice_leave, nickname, avatar, presence"
+	return "enablechannellog <#channel> <type>\nTypes: " + logTypeNames()
 }
 func (c *EnableChannelLoggingCommand) RequiredPermissions() []int64 {
 	return []int64{discordgo.PermissionAdministrator}
@@ -385,39 +639,14 @@ func (c *EnableChannelLoggingCommand) Execute(ctx *Context) error {
 		return nil
 	}
 
-	logType := strings.ToLower(ctx.Args[1])
-
-	// Map friendly names to database columns
-	columnName := ""
-	friendlyName := ""
-	switch logType {
-	case "message_delete", "msgdelete", "delete":
-		columnName = "message_delete"
-		friendlyName = "Message Deletion"
-	case "message_edit", "msgedit", "edit":
-		columnName = "message_edit"
-		friendlyName = "Message Editing"
-	case "voice_join", "join", "voicejoin":
-		columnName = "member_join_voice"
-		friendlyName = "Voice Channel Join"
-	case "voice_leave", "leave", "voiceleave":
-		columnName = "member_leave_voice"
-		friendlyName = "Voice Channel Leave"
-	case "nickname", "nick":
-		columnName = "nickname_change"
-		friendlyName = "Nickname Changes"
-	case "avatar", "pfp":
-		columnName = "avatar_change"
-		friendlyName = "Avatar Changes"
-	case "presence", "status":
-		columnName = "presence_change"
-		friendlyName = "Presence Changes"
-	default:
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Invalid log type! Use: message_delete, message_edit, voice_join, voice_leave, nickname, avatar, or presence")
+	t, ok := lookupLogType(ctx.Args[1])
+	if !ok {
+		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Invalid log type! Use: "+logTypeNames())
 		return nil
 	}
+	columnName, friendlyName := t.Column, t.FriendlyName
 
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
+	db := c.Store
 
 	query := fmt.Sprintf(`
 		INSERT INTO logging_channel_overrides (guild_id, channel_id, %s)
@@ -437,7 +666,9 @@ func (c *EnableChannelLoggingCommand) Execute(ctx *Context) error {
 }
 
 // LogStatusCommand shows the current logging configuration
-type LogStatusCommand struct{}
+type LogStatusCommand struct {
+	Store LoggingStore
+}
 
 func (c *LogStatusCommand) Name() string        { return "logstatus" }
 func (c *LogStatusCommand) Aliases() []string   { return []string{"loggingstatus"} }
@@ -453,22 +684,25 @@ func (c *LogStatusCommand) Execute(ctx *Context) error {
 		return fmt.Errorf("this command can only be used in a server")
 	}
 
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
+	db := c.Store
 
 	var (
-		logChannelID                                                            string
-		msgDel, msgEdit, joinVoice, leaveVoice, nick, avatar, presence, enabled int
-		presenceBatchSeconds                                                    int
+		logChannelID                                        string
+		enabled, presenceBatchSeconds, useWebhooks, archive int
 	)
 
-	err := db.QueryRow(`
-		SELECT log_channel_id, message_delete, message_edit, member_join_voice,
-		       member_leave_voice, nickname_change, avatar_change, presence_change,
-		       presence_batch_seconds, enabled
-		FROM logging_config WHERE guild_id = ?`, ctx.Message.GuildID).Scan(
-		&logChannelID, &msgDel, &msgEdit, &joinVoice, &leaveVoice,
-		&nick, &avatar, &presence, &presenceBatchSeconds, &enabled,
-	)
+	columns := make([]string, len(logTypes))
+	typeEnabled := make([]int, len(logTypes))
+	dest := []interface{}{&logChannelID, &presenceBatchSeconds, &enabled, &useWebhooks, &archive}
+	for i, t := range logTypes {
+		columns[i] = t.Column
+		dest = append(dest, &typeEnabled[i])
+	}
+
+	query := fmt.Sprintf(`
+		SELECT COALESCE(log_channel_id, ''), presence_batch_seconds, enabled, use_webhooks, archive_attachments, %s
+		FROM logging_config WHERE guild_id = ?`, strings.Join(columns, ", "))
+	err := db.QueryRow(query, ctx.Message.GuildID).Scan(dest...)
 
 	if err != nil {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "Logging is not configured for this server. Use `setlogchannel` to configure it.")
@@ -480,7 +714,7 @@ func (c *LogStatusCommand) Execute(ctx *Context) error {
 		status = "enabled"
 	}
 
-	logChannelText := logChannelID
+	logChannelText := "Not set"
 	if logChannelID != "" {
 		logChannelText = fmt.Sprintf("<#%s>", logChannelID)
 	}
@@ -507,28 +741,48 @@ func (c *LogStatusCommand) Execute(ctx *Context) error {
 		},
 	}
 
-	// Add logging types
-	types := []struct {
-		name    string
-		enabled int
-	}{
-		{"Message Delete", msgDel},
-		{"Message Edit", msgEdit},
-		{"Voice Join", joinVoice},
-		{"Voice Leave", leaveVoice},
-		{"Nickname Change", nick},
-		{"Avatar Change", avatar},
-		{"Presence Change", presence},
+	delivery := "Bot"
+	if useWebhooks == 1 {
+		delivery = "Webhooks"
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:   "Delivery",
+		Value:  delivery,
+		Inline: true,
+	})
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:   "Attachment Archive",
+		Value:  enabledWord(archive == 1),
+		Inline: true,
+	})
+
+	// Log types sent somewhere other than the log channel, as "type=channel,..."
+	var routes string
+	db.QueryRow(`
+		SELECT COALESCE(group_concat(log_type || '=' || channel_id, ','), '')
+		FROM logging_type_channels WHERE guild_id = ?`, ctx.Message.GuildID).Scan(&routes)
+	if routes != "" {
+		var lines []string
+		for _, route := range strings.Split(routes, ",") {
+			logType, channelID, _ := strings.Cut(route, "=")
+			lines = append(lines, fmt.Sprintf("%s → <#%s>", logRouteName(logType), channelID))
+		}
+		sort.Strings(lines)
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Log Channels",
+			Value:  truncateField(strings.Join(lines, "\n")),
+			Inline: false,
+		})
 	}
 
 	var enabledTypes []string
 	var disabledTypes []string
 
-	for _, t := range types {
-		if t.enabled == 1 {
-			enabledTypes = append(enabledTypes, t.name)
+	for i, t := range logTypes {
+		if typeEnabled[i] == 1 {
+			enabledTypes = append(enabledTypes, t.FriendlyName)
 		} else {
-			disabledTypes = append(disabledTypes, t.name)
+			disabledTypes = append(disabledTypes, t.FriendlyName)
 		}
 	}
 
@@ -16,6 +16,9 @@ type Manager struct {
 	ownerIDs       []string
 	sourceURL      string
 	banImagesPath  string
+
+	// prefixResolver looks up a guild's prefixes; nil means the global prefix only
+	prefixResolver func(guildID string) *GuildPrefixes
 }
 
 // NewManager creates a new command manager
@@ -44,15 +47,23 @@ func (m *Manager) Register(cmd Command) {
 	log.Printf("Registered command: %s", cmd.Name())
 }
 
+// SetPrefixResolver sets the lookup used for per-guild prefixes
+func (m *Manager) SetPrefixResolver(resolver func(guildID string) *GuildPrefixes) {
+	m.prefixResolver = resolver
+}
+
+// Prefixes returns the prefixes that apply in a guild
+func (m *Manager) Prefixes(guildID string) *GuildPrefixes {
+	if m.prefixResolver != nil && guildID != "" {
+		if prefixes := m.prefixResolver(guildID); prefixes != nil {
+			return prefixes
+		}
+	}
+	return &GuildPrefixes{Prefixes: []string{m.prefix}, MentionPrefix: true}
+}
+
 // Execute runs a command
 func (m *Manager) Execute(ctx *Context, content string) error {
-	// Set helper functions
-	ctx.GetPrefix = func() string { return m.prefix }
-	ctx.IsOwner = func() bool { return m.isOwner(ctx) }
-	ctx.GetAllCommands = func() []Command { return m.GetAll() }
-	ctx.GetSourceURL = func() string { return m.sourceURL }
-	ctx.GetBanImagesPath = func() string { return m.banImagesPath }
-	
 	// Parse command and args
 	parts := strings.Fields(content)
 	if len(parts) == 0 {
@@ -73,11 +84,17 @@ func (m *Manager) Execute(ctx *Context, content string) error {
 		return nil
 	}
 
+	return m.run(ctx, cmd)
+}
+
+// run performs the owner/permission checks shared by prefix and slash invocations
+func (m *Manager) run(ctx *Context, cmd Command) error {
+	m.setHelpers(ctx)
+
 	// Check if master only
 	if cmd.MasterOnly() && !m.isMaster(ctx) {
 		if ctx.Message != nil {
-			ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, 
-				"❌ This command can only be used by bot owners.")
+			ctx.Reply("❌ This command can only be used by bot owners.")
 		}
 		return nil
 	}
@@ -85,8 +102,7 @@ func (m *Manager) Execute(ctx *Context, content string) error {
 	// Check permissions
 	if ctx.Message != nil && ctx.Message.GuildID != "" {
 		if !m.hasPermissions(ctx, cmd.RequiredPermissions()) {
-			ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, 
-				"❌ You don't have permission to use this command.")
+			ctx.Reply("❌ You don't have permission to use this command.")
 			return nil
 		}
 	}
@@ -95,6 +111,29 @@ func (m *Manager) Execute(ctx *Context, content string) error {
 	return cmd.Execute(ctx)
 }
 
+// setHelpers fills in the helper functions commands use
+func (m *Manager) setHelpers(ctx *Context) {
+	ctx.GetPrefix = func() string {
+		if ctx.Interaction != nil {
+			return "/"
+		}
+		if ctx.Message == nil {
+			return m.prefix
+		}
+		return m.Prefixes(ctx.Message.GuildID).Primary()
+	}
+	ctx.GetPrefixes = func() *GuildPrefixes {
+		if ctx.Message == nil {
+			return m.Prefixes("")
+		}
+		return m.Prefixes(ctx.Message.GuildID)
+	}
+	ctx.IsOwner = func() bool { return m.isOwner(ctx) }
+	ctx.GetAllCommands = func() []Command { return m.GetAll() }
+	ctx.GetSourceURL = func() string { return m.sourceURL }
+	ctx.GetBanImagesPath = func() string { return m.banImagesPath }
+}
+
 // isMaster checks if user is a bot owner
 func (m *Manager) isMaster(ctx *Context) bool {
 	if ctx.Message == nil {
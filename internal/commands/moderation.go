@@ -2,22 +2,35 @@ package commands
 
 import (
 	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
 
 // BanCommand bans users
-type BanCommand struct{}
+type BanCommand struct {
+	Cases      CaseStore
+	Schedule   Scheduler
+	Protection ProtectionChecker
+}
 
 func (c *BanCommand) Name() string        { return "ban" }
 func (c *BanCommand) Aliases() []string   { return []string{"bean", "banne"} }
 func (c *BanCommand) Description() string { return "Ban users from the server" }
-func (c *BanCommand) Usage() string       { return "ban <@user|id> [more...] | reason" }
+func (c *BanCommand) Usage() string       { return "ban <@user|id> [more...] [duration] | reason" }
 func (c *BanCommand) RequiredPermissions() []int64 {
 	return []int64{discordgo.PermissionBanMembers}
 }
 func (c *BanCommand) MasterOnly() bool { return false }
+func (c *BanCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "user", Description: "User to ban", Type: ArgUser, Required: true},
+		{Name: "duration", Description: "Temporary ban length, e.g. 12h, 7d (permanent if omitted)", Type: ArgString},
+		{Name: "reason", Description: "Reason for the ban", Type: ArgString, Separator: "|"},
+	}
+}
 
 func (c *BanCommand) Execute(ctx *Context) error {
 	if ctx.Message == nil || ctx.Message.GuildID == "" {
@@ -25,36 +38,44 @@ func (c *BanCommand) Execute(ctx *Context) error {
 	}
 
 	if len(ctx.Args) == 0 {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "❌ Usage: `ban <@user|id> [more...] | reason`")
+		ctx.Reply(fmt.Sprintf("❌ Usage: `%sban <@user|id> [more...] [duration] | reason`", ctx.GetPrefix()))
 		return nil
 	}
 
 	// Parse reason and targets
 	fullArgs := strings.Join(ctx.Args, " ")
 	parts := strings.Split(fullArgs, "|")
-	
+
 	reason := "Banned by " + ctx.Message.Author.Username
+	caseReason := ""
 	targets := parts[0]
 	if len(parts) > 1 {
-		reason = strings.TrimSpace(parts[1]) + " / " + reason
+		caseReason = strings.TrimSpace(parts[1])
+		reason = caseReason + " / " + reason
 	}
 
 	// Collect users to ban
 	var userIDs []string
-	
+
 	// Add mentioned users
 	for _, user := range ctx.Message.Mentions {
 		userIDs = append(userIDs, user.ID)
 	}
 
-	// Parse remaining arguments for IDs
+	// Parse remaining arguments for IDs and an optional tempban duration
+	var duration time.Duration
 	targetParts := strings.Fields(targets)
 	for _, part := range targetParts {
 		// Skip mentions (already processed)
 		if strings.HasPrefix(part, "<@") {
 			continue
 		}
-		
+
+		if d, ok := parseDuration(part); ok {
+			duration = d
+			continue
+		}
+
 		// Check if it looks like a user ID (numeric)
 		if len(part) >= 17 && len(part) <= 19 {
 			// Verify it's a valid user
@@ -66,18 +87,20 @@ func (c *BanCommand) Execute(ctx *Context) error {
 	}
 
 	if len(userIDs) == 0 {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "❌ No valid users to ban")
+		ctx.Reply("❌ No valid users to ban")
 		return nil
 	}
 
 	// Ban each user
 	successCount := 0
 	failCount := 0
-	
+
 	for _, userID := range userIDs {
-		// Check if user is a master user
-		// (You'd need to implement this check)
-		
+		if refuseProtected(ctx, c.Protection, c.Cases, CaseBan, userID) {
+			failCount++
+			continue
+		}
+
 		err := ctx.Session.GuildBanCreateWithReason(
 			ctx.Message.GuildID,
 			userID,
@@ -87,31 +110,53 @@ func (c *BanCommand) Execute(ctx *Context) error {
 
 		if err != nil {
 			failCount++
-			
+
 			// Send error embed
 			embed := &discordgo.MessageEmbed{
 				Title:       "❌ Ban failed",
 				Description: fmt.Sprintf("Failed to ban <@%s>: %s", userID, err.Error()),
 				Color:       0xFF0000,
 			}
-			ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+			ctx.ReplyEmbed(embed)
 		} else {
 			successCount++
-			
+			caseNumber := recordCase(ctx, c.Cases, CaseBan, userID, caseReason, duration)
+
+			// A new ban replaces any pending unban; tempbans schedule their own
+			length := ""
+			if c.Schedule != nil {
+				c.Schedule.CancelScheduledActions(ctx.Message.GuildID, userID, ScheduledUnban)
+				if duration > 0 {
+					err := c.Schedule.ScheduleAction(&ScheduledAction{
+						GuildID:    ctx.Message.GuildID,
+						UserID:     userID,
+						Action:     ScheduledUnban,
+						ExecuteAt:  time.Now().Add(duration),
+						CaseNumber: caseNumber,
+					})
+					if err == nil {
+						length = " for **" + formatDuration(duration) + "**"
+					} else {
+						log.Printf("Failed to schedule unban of %s in guild %s: %v", userID, ctx.Message.GuildID, err)
+						ctx.Reply(fmt.Sprintf("⚠️ Banned <@%s>, but the unban couldn't be scheduled: %v\nThe ban stays until someone lifts it.", userID, err))
+					}
+				}
+			}
+
 			// Get user info
 			user, _ := ctx.Session.User(userID)
 			username := userID
 			if user != nil {
 				username = user.Username + "#" + user.Discriminator
 			}
-			
+
 			// Send success embed
 			embed := &discordgo.MessageEmbed{
 				Title:       "✅ Ban successful",
-				Description: fmt.Sprintf("User %s has been successfully banned.", username),
+				Description: fmt.Sprintf("User %s has been successfully banned%s.%s", username, length, caseSuffix(caseNumber)),
 				Color:       0x43CC24,
 			}
-			ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+			ctx.ReplyEmbed(embed)
 		}
 	}
 
@@ -119,7 +164,10 @@ func (c *BanCommand) Execute(ctx *Context) error {
 }
 
 // KickCommand kicks users
-type KickCommand struct{}
+type KickCommand struct {
+	Cases      CaseStore
+	Protection ProtectionChecker
+}
 
 func (c *KickCommand) Name() string        { return "kick" }
 func (c *KickCommand) Aliases() []string   { return []string{} }
@@ -129,6 +177,12 @@ func (c *KickCommand) RequiredPermissions() []int64 {
 	return []int64{discordgo.PermissionKickMembers}
 }
 func (c *KickCommand) MasterOnly() bool { return false }
+func (c *KickCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "user", Description: "User to kick", Type: ArgUser, Required: true},
+		{Name: "reason", Description: "Reason for the kick", Type: ArgString, Separator: "|"},
+	}
+}
 
 func (c *KickCommand) Execute(ctx *Context) error {
 	if ctx.Message == nil || ctx.Message.GuildID == "" {
@@ -136,23 +190,25 @@ func (c *KickCommand) Execute(ctx *Context) error {
 	}
 
 	if len(ctx.Args) == 0 {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "❌ Usage: `kick <@user|id> [more...] | reason`")
+		ctx.Reply(fmt.Sprintf("❌ Usage: `%skick <@user|id> [more...] | reason`", ctx.GetPrefix()))
 		return nil
 	}
 
 	// Parse reason and targets
 	fullArgs := strings.Join(ctx.Args, " ")
 	parts := strings.Split(fullArgs, "|")
-	
+
 	reason := "Kicked by " + ctx.Message.Author.Username
+	caseReason := ""
 	targets := parts[0]
 	if len(parts) > 1 {
-		reason = strings.TrimSpace(parts[1]) + " / " + reason
+		caseReason = strings.TrimSpace(parts[1])
+		reason = caseReason + " / " + reason
 	}
 
 	// Collect users to kick
 	var userIDs []string
-	
+
 	// Add mentioned users
 	for _, user := range ctx.Message.Mentions {
 		userIDs = append(userIDs, user.ID)
@@ -164,7 +220,7 @@ func (c *KickCommand) Execute(ctx *Context) error {
 		if strings.HasPrefix(part, "<@") {
 			continue
 		}
-		
+
 		if len(part) >= 17 && len(part) <= 19 {
 			member, err := ctx.Session.GuildMember(ctx.Message.GuildID, part)
 			if err == nil && member != nil {
@@ -174,7 +230,7 @@ func (c *KickCommand) Execute(ctx *Context) error {
 	}
 
 	if len(userIDs) == 0 {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "❌ No valid users to kick")
+		ctx.Reply("❌ No valid users to kick")
 		return nil
 	}
 
@@ -187,7 +243,11 @@ func (c *KickCommand) Execute(ctx *Context) error {
 				Description: "You can also leave the server instead of kicking yourself ;)",
 				Color:       0xFF0000,
 			}
-			ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+			ctx.ReplyEmbed(embed)
+			continue
+		}
+
+		if refuseProtected(ctx, c.Protection, c.Cases, CaseKick, userID) {
 			continue
 		}
 
@@ -203,22 +263,279 @@ func (c *KickCommand) Execute(ctx *Context) error {
 				Description: fmt.Sprintf("Failed to kick <@%s>: %s", userID, err.Error()),
 				Color:       0xFF0000,
 			}
-			ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+			ctx.ReplyEmbed(embed)
 		} else {
+			caseNumber := recordCase(ctx, c.Cases, CaseKick, userID, caseReason, 0)
 			member, _ := ctx.Session.GuildMember(ctx.Message.GuildID, userID)
 			username := userID
 			if member != nil {
 				username = member.User.Username
 			}
-			
+
 			embed := &discordgo.MessageEmbed{
 				Title:       "✅ Kick successful",
-				Description: fmt.Sprintf("User %s has been successfully kicked.", username),
+				Description: fmt.Sprintf("User %s has been successfully kicked.%s", username, caseSuffix(caseNumber)),
 				Color:       0x43CC24,
 			}
-			ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+			ctx.ReplyEmbed(embed)
 		}
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// formatAuditReason builds the audit log reason in the same "reason / Action by mod" form as ban/kick
+func formatAuditReason(reason, action string, moderator *discordgo.User) string {
+	if reason == "" {
+		return action + " by " + moderator.Username
+	}
+	return reason + " / " + action + " by " + moderator.Username
+}
+
+// maxTimeout is the longest timeout Discord allows
+const maxTimeout = 28 * 24 * time.Hour
+
+// WarnCommand records a warning against a user
+type WarnCommand struct {
+	Cases     CaseStore
+	Points    WarnPointStore
+	Hierarchy HierarchyChecker
+}
+
+func (c *WarnCommand) Name() string        { return "warn" }
+func (c *WarnCommand) Aliases() []string   { return []string{} }
+func (c *WarnCommand) Description() string { return "Warn a user and record it in their history" }
+func (c *WarnCommand) Usage() string       { return "warn <@user|id> [reason]" }
+func (c *WarnCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionModerateMembers}
+}
+func (c *WarnCommand) MasterOnly() bool { return false }
+func (c *WarnCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "user", Description: "User to warn", Type: ArgUser, Required: true},
+		{Name: "reason", Description: "Reason for the warning", Type: ArgString},
+	}
+}
+
+func (c *WarnCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) == 0 {
+		ctx.Reply(fmt.Sprintf("❌ Usage: `%s%s`", ctx.GetPrefix(), c.Usage()))
+		return nil
+	}
+
+	userID := parseUserID(ctx.Args[0])
+	if !isSnowflake(userID) {
+		ctx.Reply("❌ Please mention a user or give their ID")
+		return nil
+	}
+	if userID == ctx.Message.Author.ID {
+		ctx.Reply("❌ You can't warn yourself")
+		return nil
+	}
+	if refuseHierarchy(ctx, c.Hierarchy, "warn", userID) {
+		return nil
+	}
+
+	before := -1
+	if c.Points != nil {
+		if points, err := c.Points.GetActiveWarningPoints(ctx.Message.GuildID, userID); err == nil {
+			before = TotalPoints(points)
+		}
+	}
+
+	reason := strings.Join(ctx.Args[1:], " ")
+	caseNumber := recordCase(ctx, c.Cases, CaseWarn, userID, reason, 0)
+
+	// Let the user know; DMs may be closed so failures are ignored
+	if dm, err := ctx.Session.UserChannelCreate(userID); err == nil {
+		guildName := "the server"
+		if guild, err := ctx.Session.State.Guild(ctx.Message.GuildID); err == nil {
+			guildName = guild.Name
+		}
+		text := fmt.Sprintf("⚠️ You have been warned in **%s**", guildName)
+		if reason != "" {
+			text += ": " + reason
+		}
+		ctx.Session.ChannelMessageSend(dm.ID, text)
+	}
+
+	description := fmt.Sprintf("<@%s> has been warned.%s", userID, caseSuffix(caseNumber))
+	if c.Points != nil {
+		if points, err := c.Points.GetActiveWarningPoints(ctx.Message.GuildID, userID); err == nil {
+			after := TotalPoints(points)
+			description += fmt.Sprintf("\nThey now have **%d** warning point(s).", after)
+			if before >= 0 {
+				description += withheldThresholdNote(ctx, c.Points, before, after)
+			}
+		}
+	}
+
+	_, err := ctx.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "⚠️ User warned",
+		Description: description,
+		Color:       0xFFAA00,
+	})
+	return err
+}
+
+// withheldThresholdNote explains a kick or ban threshold the warning reached
+// but the moderator wasn't allowed to apply
+func withheldThresholdNote(ctx *Context, store WarnPointStore, before, after int) string {
+	apply, withheld := store.WarnThresholdFor(ctx.Message.GuildID, ctx.Message.Author.ID, before, after)
+	if withheld == nil {
+		return ""
+	}
+
+	perm := "Ban Members"
+	if withheld.Step.Action == EscalateKick {
+		perm = "Kick Members"
+	}
+	note := fmt.Sprintf("\nThat reaches the **%s** threshold, but you don't have %s", withheld.Step, perm)
+	if apply != nil {
+		return note + fmt.Sprintf(", so it stopped at **%s**.", apply.Step)
+	}
+	return note + ", so nothing further was applied."
+}
+
+// TimeoutCommand uses Discord's native member timeout
+type TimeoutCommand struct {
+	Cases     CaseStore
+	Hierarchy HierarchyChecker
+}
+
+func (c *TimeoutCommand) Name() string        { return "timeout" }
+func (c *TimeoutCommand) Aliases() []string   { return []string{"to", "shush"} }
+func (c *TimeoutCommand) Description() string { return "Time out a member (up to 28 days)" }
+func (c *TimeoutCommand) Usage() string       { return "timeout <@user|id> <duration|off> [reason]" }
+func (c *TimeoutCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionModerateMembers}
+}
+func (c *TimeoutCommand) MasterOnly() bool { return false }
+func (c *TimeoutCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "user", Description: "Member to time out", Type: ArgUser, Required: true},
+		{Name: "duration", Description: "How long, e.g. 10m, 2h, 7d (or off)", Type: ArgString, Required: true},
+		{Name: "reason", Description: "Reason for the timeout", Type: ArgString},
+	}
+}
+
+func (c *TimeoutCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) < 2 {
+		ctx.Reply(fmt.Sprintf("❌ Usage: `%s%s`\nDurations: `30s`, `10m`, `2h`, `7d`, `1d12h`", ctx.GetPrefix(), c.Usage()))
+		return nil
+	}
+
+	userID := parseUserID(ctx.Args[0])
+	if !isSnowflake(userID) {
+		ctx.Reply("❌ Please mention a user or give their ID")
+		return nil
+	}
+	if refuseHierarchy(ctx, c.Hierarchy, "time out", userID) {
+		return nil
+	}
+
+	reason := strings.Join(ctx.Args[2:], " ")
+	auditReason := formatAuditReason(reason, "Timed out", ctx.Message.Author)
+
+	// Lift an existing timeout
+	if strings.EqualFold(ctx.Args[1], "off") || strings.EqualFold(ctx.Args[1], "remove") {
+		if err := ctx.Session.GuildMemberTimeout(ctx.Message.GuildID, userID, nil, discordgo.WithAuditLogReason(auditReason)); err != nil {
+			ctx.Reply(fmt.Sprintf("❌ Failed to remove timeout: %v", err))
+			return nil
+		}
+		caseNumber := recordCase(ctx, c.Cases, CaseUntimeout, userID, reason, 0)
+		_, err := ctx.Reply(fmt.Sprintf("✅ Removed timeout for <@%s>%s", userID, caseSuffix(caseNumber)))
+		return err
+	}
+
+	duration, ok := parseDuration(ctx.Args[1])
+	if !ok {
+		ctx.Reply("❌ Invalid duration. Examples: `30s`, `10m`, `2h`, `7d`, `1d12h`")
+		return nil
+	}
+	if duration > maxTimeout {
+		ctx.Reply("❌ Discord timeouts can't be longer than 28 days")
+		return nil
+	}
+
+	until := time.Now().Add(duration)
+	if err := ctx.Session.GuildMemberTimeout(ctx.Message.GuildID, userID, &until, discordgo.WithAuditLogReason(auditReason)); err != nil {
+		ctx.Reply(fmt.Sprintf("❌ Failed to time out <@%s>: %v", userID, err))
+		return nil
+	}
+
+	caseNumber := recordCase(ctx, c.Cases, CaseTimeout, userID, reason, duration)
+
+	_, err := ctx.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "🔇 Member timed out",
+		Description: fmt.Sprintf("<@%s> has been timed out for **%s**.%s", userID, formatDuration(duration), caseSuffix(caseNumber)),
+		Color:       0xFFD700,
+	})
+	return err
+}
+
+// UnbanCommand lifts a ban
+type UnbanCommand struct {
+	Cases    CaseStore
+	Schedule Scheduler
+}
+
+func (c *UnbanCommand) Name() string        { return "unban" }
+func (c *UnbanCommand) Aliases() []string   { return []string{"pardon"} }
+func (c *UnbanCommand) Description() string { return "Unban a user from the server" }
+func (c *UnbanCommand) Usage() string       { return "unban <id> [reason]" }
+func (c *UnbanCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionBanMembers}
+}
+func (c *UnbanCommand) MasterOnly() bool { return false }
+func (c *UnbanCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "user", Description: "User to unban", Type: ArgUser, Required: true},
+		{Name: "reason", Description: "Reason for the unban", Type: ArgString},
+	}
+}
+
+func (c *UnbanCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) == 0 {
+		ctx.Reply(fmt.Sprintf("❌ Usage: `%s%s`", ctx.GetPrefix(), c.Usage()))
+		return nil
+	}
+
+	userID := parseUserID(ctx.Args[0])
+	if !isSnowflake(userID) {
+		ctx.Reply("❌ Please give the ID of the banned user")
+		return nil
+	}
+
+	reason := strings.Join(ctx.Args[1:], " ")
+	auditReason := formatAuditReason(reason, "Unbanned", ctx.Message.Author)
+
+	if err := ctx.Session.GuildBanDelete(ctx.Message.GuildID, userID, discordgo.WithAuditLogReason(auditReason)); err != nil {
+		ctx.Reply(fmt.Sprintf("❌ Failed to unban <@%s>: %v", userID, err))
+		return nil
+	}
+
+	if c.Schedule != nil {
+		c.Schedule.CancelScheduledActions(ctx.Message.GuildID, userID, ScheduledUnban)
+	}
+	caseNumber := recordCase(ctx, c.Cases, CaseUnban, userID, reason, 0)
+
+	_, err := ctx.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "✅ Unban successful",
+		Description: fmt.Sprintf("<@%s> has been unbanned.%s", userID, caseSuffix(caseNumber)),
+		Color:       0x43CC24,
+	})
+	return err
+}
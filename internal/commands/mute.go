@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Scheduled action types
+const (
+	ScheduledUnban      = "unban"
+	ScheduledRemoveRole = "remove_role"
+	ScheduledEndRaid    = "end_raid" // Lifts an anti-raid lockdown; UserID is empty
+)
+
+// ScheduledAction is a pending moderation reversal (tempban expiry, mute expiry)
+type ScheduledAction struct {
+	ID         int64
+	GuildID    string
+	UserID     string
+	Action     string
+	RoleID     string
+	ExecuteAt  time.Time
+	CaseNumber int
+	Attempts   int // Failed runs so far
+}
+
+// Scheduler persists actions that must run after a delay
+type Scheduler interface {
+	ScheduleAction(action *ScheduledAction) error
+	CancelScheduledActions(guildID, userID, action string) (int, error)
+}
+
+// MuteRoleStore stores the role used by the mute command
+type MuteRoleStore interface {
+	GetMuteRole(guildID string) string
+	SetMuteRole(guildID, roleID string) error
+}
+
+// MuteCommand mutes a member with the configured mute role (or a native timeout)
+type MuteCommand struct {
+	Cases     CaseStore
+	Schedule  Scheduler
+	MuteRoles MuteRoleStore
+	Hierarchy HierarchyChecker
+}
+
+func (c *MuteCommand) Name() string        { return "mute" }
+func (c *MuteCommand) Aliases() []string   { return []string{"silence"} }
+func (c *MuteCommand) Description() string { return "Mute a member, optionally for a limited time" }
+func (c *MuteCommand) Usage() string       { return "mute <@user|id> [duration] [reason]" }
+func (c *MuteCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageRoles}
+}
+func (c *MuteCommand) MasterOnly() bool { return false }
+func (c *MuteCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "user", Description: "Member to mute", Type: ArgUser, Required: true},
+		{Name: "duration", Description: "How long, e.g. 10m, 2h, 7d (permanent if omitted)", Type: ArgString},
+		{Name: "reason", Description: "Reason for the mute", Type: ArgString},
+	}
+}
+
+func (c *MuteCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) == 0 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	guildID := ctx.Message.GuildID
+	userID := parseUserID(ctx.Args[0])
+	if !isSnowflake(userID) {
+		ctx.Reply("❌ Please mention a user or give their ID")
+		return nil
+	}
+	if refuseHierarchy(ctx, c.Hierarchy, "mute", userID) {
+		return nil
+	}
+
+	rest := ctx.Args[1:]
+	var duration time.Duration
+	if len(rest) > 0 {
+		if d, ok := parseDuration(rest[0]); ok {
+			duration = d
+			rest = rest[1:]
+		}
+	}
+	reason := strings.Join(rest, " ")
+	auditReason := formatAuditReason(reason, "Muted", ctx.Message.Author)
+
+	roleID := c.MuteRoles.GetMuteRole(guildID)
+	if roleID == "" {
+		// No mute role configured: fall back to Discord's native timeout
+		if duration == 0 || duration > maxTimeout {
+			ctx.Reply("❌ No mute role is set, so mutes use Discord timeouts which need a duration of up to 28 days.\n" +
+				"Set one with `" + ctx.GetPrefix() + "set-mute-role @role`")
+			return nil
+		}
+
+		until := time.Now().Add(duration)
+		if err := ctx.Session.GuildMemberTimeout(guildID, userID, &until, discordgo.WithAuditLogReason(auditReason)); err != nil {
+			ctx.Reply(fmt.Sprintf("❌ Failed to mute <@%s>: %v", userID, err))
+			return nil
+		}
+
+		caseNumber := recordCase(ctx, c.Cases, CaseTimeout, userID, reason, duration)
+		_, err := ctx.Reply(fmt.Sprintf("🔇 <@%s> has been timed out for **%s**%s", userID, formatDuration(duration), caseSuffix(caseNumber)))
+		return err
+	}
+
+	if err := ctx.Session.GuildMemberRoleAdd(guildID, userID, roleID, discordgo.WithAuditLogReason(auditReason)); err != nil {
+		ctx.Reply(fmt.Sprintf("❌ Failed to mute <@%s>: %v", userID, err))
+		return nil
+	}
+
+	// A new mute replaces any pending unmute
+	c.Schedule.CancelScheduledActions(guildID, userID, ScheduledRemoveRole)
+
+	caseNumber := recordCase(ctx, c.Cases, CaseMute, userID, reason, duration)
+
+	length := "permanently"
+	if duration > 0 {
+		length = "for **" + formatDuration(duration) + "**"
+		err := c.Schedule.ScheduleAction(&ScheduledAction{
+			GuildID:    guildID,
+			UserID:     userID,
+			Action:     ScheduledRemoveRole,
+			RoleID:     roleID,
+			ExecuteAt:  time.Now().Add(duration),
+			CaseNumber: caseNumber,
+		})
+		if err != nil {
+			ctx.Reply(fmt.Sprintf("⚠️ Muted, but the unmute couldn't be scheduled: %v", err))
+			return err
+		}
+	}
+
+	_, err := ctx.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "🔇 Member muted",
+		Description: fmt.Sprintf("<@%s> has been muted %s.%s", userID, length, caseSuffix(caseNumber)),
+		Color:       0xFFD700,
+	})
+	return err
+}
+
+// UnmuteCommand lifts a mute before it expires
+type UnmuteCommand struct {
+	Cases     CaseStore
+	Schedule  Scheduler
+	MuteRoles MuteRoleStore
+	Hierarchy HierarchyChecker
+}
+
+func (c *UnmuteCommand) Name() string        { return "unmute" }
+func (c *UnmuteCommand) Aliases() []string   { return []string{} }
+func (c *UnmuteCommand) Description() string { return "Unmute a member" }
+func (c *UnmuteCommand) Usage() string       { return "unmute <@user|id> [reason]" }
+func (c *UnmuteCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageRoles}
+}
+func (c *UnmuteCommand) MasterOnly() bool { return false }
+func (c *UnmuteCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "user", Description: "Member to unmute", Type: ArgUser, Required: true},
+		{Name: "reason", Description: "Reason for the unmute", Type: ArgString},
+	}
+}
+
+func (c *UnmuteCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) == 0 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	guildID := ctx.Message.GuildID
+	userID := parseUserID(ctx.Args[0])
+	if !isSnowflake(userID) {
+		ctx.Reply("❌ Please mention a user or give their ID")
+		return nil
+	}
+	if refuseHierarchy(ctx, c.Hierarchy, "unmute", userID) {
+		return nil
+	}
+
+	reason := strings.Join(ctx.Args[1:], " ")
+	auditReason := formatAuditReason(reason, "Unmuted", ctx.Message.Author)
+
+	roleID := c.MuteRoles.GetMuteRole(guildID)
+	if roleID == "" {
+		if err := ctx.Session.GuildMemberTimeout(guildID, userID, nil, discordgo.WithAuditLogReason(auditReason)); err != nil {
+			ctx.Reply(fmt.Sprintf("❌ Failed to unmute <@%s>: %v", userID, err))
+			return nil
+		}
+		caseNumber := recordCase(ctx, c.Cases, CaseUntimeout, userID, reason, 0)
+		_, err := ctx.Reply(fmt.Sprintf("🔊 Removed timeout for <@%s>%s", userID, caseSuffix(caseNumber)))
+		return err
+	}
+
+	if err := ctx.Session.GuildMemberRoleRemove(guildID, userID, roleID, discordgo.WithAuditLogReason(auditReason)); err != nil {
+		ctx.Reply(fmt.Sprintf("❌ Failed to unmute <@%s>: %v", userID, err))
+		return nil
+	}
+
+	c.Schedule.CancelScheduledActions(guildID, userID, ScheduledRemoveRole)
+	caseNumber := recordCase(ctx, c.Cases, CaseUnmute, userID, reason, 0)
+
+	_, err := ctx.Reply(fmt.Sprintf("🔊 <@%s> has been unmuted%s", userID, caseSuffix(caseNumber)))
+	return err
+}
+
+// SetMuteRoleCommand chooses the role applied by the mute command
+type SetMuteRoleCommand struct {
+	MuteRoles MuteRoleStore
+}
+
+func (c *SetMuteRoleCommand) Name() string        { return "set-mute-role" }
+func (c *SetMuteRoleCommand) Aliases() []string   { return []string{"muterole", "setmuterole"} }
+func (c *SetMuteRoleCommand) Description() string { return "Set the role used by the mute command" }
+func (c *SetMuteRoleCommand) Usage() string       { return "set-mute-role <@role|id|none>" }
+func (c *SetMuteRoleCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *SetMuteRoleCommand) MasterOnly() bool { return false }
+
+func (c *SetMuteRoleCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	guildID := ctx.Message.GuildID
+
+	if len(ctx.Args) == 0 {
+		current := "None (mutes use Discord timeouts)"
+		if roleID := c.MuteRoles.GetMuteRole(guildID); roleID != "" {
+			current = fmt.Sprintf("<@&%s>", roleID)
+		}
+		_, err := ctx.Reply("Mute role: " + current + "\nUsage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return err
+	}
+
+	roleID := ""
+	if !strings.EqualFold(ctx.Args[0], "none") && !strings.EqualFold(ctx.Args[0], "off") {
+		roleID = parseRoleID(ctx.Args[0])
+		if _, err := ctx.Session.State.Role(guildID, roleID); err != nil {
+			ctx.Reply("❌ Role not found in this server")
+			return nil
+		}
+	}
+
+	if err := c.MuteRoles.SetMuteRole(guildID, roleID); err != nil {
+		ctx.Reply("❌ Failed to save mute role: " + err.Error())
+		return err
+	}
+
+	if roleID == "" {
+		_, err := ctx.Reply("✅ Mute role cleared. Mutes will use Discord timeouts.")
+		return err
+	}
+	_, err := ctx.Reply(fmt.Sprintf("✅ Mute role set to <@&%s>", roleID))
+	return err
+}
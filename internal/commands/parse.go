@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseChannelID accepts a channel mention (<#id>) or a raw ID
+func parseChannelID(arg string) string {
+	return strings.Trim(arg, "<>#")
+}
+
+// parseUserID accepts a user mention (<@id>, <@!id>) or a raw ID
+func parseUserID(arg string) string {
+	return strings.Trim(arg, "<>@!")
+}
+
+// parseRoleID accepts a role mention (<@&id>) or a raw ID
+func parseRoleID(arg string) string {
+	return strings.Trim(arg, "<>@&")
+}
+
+// isSnowflake reports whether s looks like a Discord ID
+func isSnowflake(s string) bool {
+	if len(s) < 17 || len(s) > 20 {
+		return false
+	}
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// parseToggle reads on/off style arguments
+func parseToggle(arg string) (enabled bool, ok bool) {
+	switch strings.ToLower(arg) {
+	case "on", "true", "enable", "enabled", "yes":
+		return true, true
+	case "off", "false", "disable", "disabled", "no":
+		return false, true
+	}
+	return false, false
+}
+
+// parseColor accepts #RRGGBB, 0xRRGGBB or a decimal colour
+func parseColor(arg string) (int, bool) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if strings.HasPrefix(arg, "#") || strings.HasPrefix(arg, "0x") {
+		value, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimPrefix(arg, "#"), "0x"), 16, 32)
+		if err != nil || value < 0 || value > 0xFFFFFF {
+			return 0, false
+		}
+		return int(value), true
+	}
+
+	value, err := strconv.Atoi(arg)
+	if err != nil || value < 0 || value > 0xFFFFFF {
+		return 0, false
+	}
+	return value, true
+}
+
+// enabledWord renders a flag for confirmation messages
+func enabledWord(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+// onOff renders a flag for status embeds
+func onOff(enabled bool) string {
+	if enabled {
+		return "✅ On"
+	}
+	return "❌ Off"
+}
+
+// durationUnits maps the suffixes accepted by parseDuration
+var durationUnits = map[byte]time.Duration{
+	's': time.Second,
+	'm': time.Minute,
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseDuration reads moderation durations like "30m", "7d" or "1d12h"
+func parseDuration(arg string) (time.Duration, bool) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if arg == "" {
+		return 0, false
+	}
+
+	var total time.Duration
+	number := 0
+	digits := 0
+	for i := 0; i < len(arg); i++ {
+		ch := arg[i]
+		if ch >= '0' && ch <= '9' {
+			number = number*10 + int(ch-'0')
+			digits++
+			if digits > 6 {
+				return 0, false
+			}
+			continue
+		}
+
+		unit, ok := durationUnits[ch]
+		if !ok || digits == 0 {
+			return 0, false
+		}
+		total += time.Duration(number) * unit
+		number, digits = 0, 0
+	}
+
+	// Trailing digits without a unit aren't a duration
+	if digits > 0 || total <= 0 {
+		return 0, false
+	}
+	return total, true
+}
+
+// formatDuration renders a duration in the same style parseDuration accepts
+func formatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "0s"
+	}
+
+	parts := []string{}
+	for _, unit := range []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"w", 7 * 24 * time.Hour},
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	} {
+		if d >= unit.size {
+			parts = append(parts, strconv.Itoa(int(d/unit.size))+unit.suffix)
+			d %= unit.size
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// formatCount renders a number with thousands separators (3210 → "3,210")
+func formatCount(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return sign + b.String()
+}
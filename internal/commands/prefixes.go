@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"strings"
+)
+
+// MaxGuildPrefixes limits how many prefixes a single guild can register
+const MaxGuildPrefixes = 5
+
+// GuildPrefixes is the prefix configuration used to recognise commands in a guild
+type GuildPrefixes struct {
+	// Prefixes lists every accepted prefix; the first one is shown in help and usage
+	Prefixes        []string
+	CaseInsensitive bool
+	MentionPrefix   bool
+}
+
+// Primary returns the prefix shown in help and usage strings
+func (p *GuildPrefixes) Primary() string {
+	if p == nil || len(p.Prefixes) == 0 {
+		return "?"
+	}
+	return p.Prefixes[0]
+}
+
+// Has reports whether prefix is already registered
+func (p *GuildPrefixes) Has(prefix string) bool {
+	for _, existing := range p.Prefixes {
+		if existing == prefix || (p.CaseInsensitive && strings.EqualFold(existing, prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match strips the longest matching prefix from content.
+// Mention prefixes are handled by the dispatcher since they need the bot's ID.
+func (p *GuildPrefixes) Match(content string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+
+	best := ""
+	for _, prefix := range p.Prefixes {
+		if prefix == "" || len(prefix) <= len(best) || len(content) < len(prefix) {
+			continue
+		}
+
+		head := content[:len(prefix)]
+		if head == prefix || (p.CaseInsensitive && strings.EqualFold(head, prefix)) {
+			best = prefix
+		}
+	}
+
+	if best == "" {
+		return "", false
+	}
+	return content[len(best):], true
+}
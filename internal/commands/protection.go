@@ -0,0 +1,212 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Protected target types
+const (
+	ProtectRole = "role"
+	ProtectUser = "user"
+)
+
+// ProtectedTarget is a role or user the bot refuses to ban or kick
+type ProtectedTarget struct {
+	GuildID    string
+	TargetType string
+	TargetID   string
+	AddedBy    string
+	CreatedAt  time.Time
+}
+
+// ProtectionStore reads and writes a guild's protected roles and users
+type ProtectionStore interface {
+	GetProtectedTargets(guildID string) []ProtectedTarget
+	AddProtectedTarget(target ProtectedTarget) error
+	RemoveProtectedTarget(guildID, targetType, targetID string) (bool, error)
+}
+
+// ProtectionChecker says why a member can't be banned or kicked, or "" if they can
+type ProtectionChecker interface {
+	ProtectedReason(guildID, userID string) string
+}
+
+// refuseProtected stops a ban or kick on a protected member, telling the
+// moderator why and recording the attempt. It reports whether it refused.
+func refuseProtected(ctx *Context, protection ProtectionChecker, cases CaseStore, action, userID string) bool {
+	if protection == nil {
+		return false
+	}
+	why := protection.ProtectedReason(ctx.Message.GuildID, userID)
+	if why == "" {
+		return false
+	}
+
+	caseNumber := recordCase(ctx, cases, CaseBlocked, userID, fmt.Sprintf("Blocked %s: %s", action, why), 0)
+	ctx.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "🛡️ Protected member",
+		Description: fmt.Sprintf("<@%s> can't be %s: %s.%s", userID, pastTense(action), why, caseSuffix(caseNumber)),
+		Color:       0xFF0000,
+	})
+	return true
+}
+
+// HierarchyChecker says why a moderator can't act on a member, or "" if they can
+type HierarchyChecker interface {
+	HierarchyReason(guildID, moderatorID, targetID string) string
+}
+
+// refuseHierarchy stops an action on a member the moderator doesn't outrank,
+// telling them why. It reports whether it refused.
+func refuseHierarchy(ctx *Context, hierarchy HierarchyChecker, action, userID string) bool {
+	if hierarchy == nil {
+		return false
+	}
+	why := hierarchy.HierarchyReason(ctx.Message.GuildID, ctx.Message.Author.ID, userID)
+	if why == "" {
+		return false
+	}
+
+	ctx.Reply(fmt.Sprintf("❌ You can't %s <@%s>: %s", action, userID, why))
+	return true
+}
+
+func pastTense(action string) string {
+	if action == CaseBan {
+		return "banned"
+	}
+	return action + "ed"
+}
+
+// ProtectCommand manages the roles and users the bot won't ban or kick
+type ProtectCommand struct {
+	Protection ProtectionStore
+}
+
+func (c *ProtectCommand) Name() string      { return "protect" }
+func (c *ProtectCommand) Aliases() []string { return []string{"protected", "protection"} }
+func (c *ProtectCommand) Description() string {
+	return "Stop roles or users from being banned or kicked through the bot"
+}
+func (c *ProtectCommand) Usage() string { return "protect [add|remove <@role|@user|id>]" }
+func (c *ProtectCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionAdministrator}
+}
+func (c *ProtectCommand) MasterOnly() bool { return false }
+
+func (c *ProtectCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) == 0 {
+		return c.showList(ctx)
+	}
+	if len(ctx.Args) != 2 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	target := ProtectedTarget{GuildID: ctx.Message.GuildID, AddedBy: ctx.Message.Author.ID, CreatedAt: time.Now()}
+	switch {
+	case len(ctx.Message.MentionRoles) > 0:
+		target.TargetType, target.TargetID = ProtectRole, ctx.Message.MentionRoles[0]
+	case strings.HasPrefix(ctx.Args[1], "<@&"):
+		target.TargetType, target.TargetID = ProtectRole, parseRoleID(ctx.Args[1])
+	default:
+		target.TargetType, target.TargetID = ProtectUser, parseUserID(ctx.Args[1])
+		// A bare ID may be a role
+		if !strings.HasPrefix(ctx.Args[1], "<@") && c.isRole(ctx, target.TargetID) {
+			target.TargetType = ProtectRole
+		}
+	}
+	if !isSnowflake(target.TargetID) {
+		ctx.Reply("❌ Please mention a role or user, or give an ID")
+		return nil
+	}
+
+	label := protectedLabel(target.TargetType, target.TargetID)
+	switch strings.ToLower(ctx.Args[0]) {
+	case "add":
+		if err := c.Protection.AddProtectedTarget(target); err != nil {
+			ctx.Reply("❌ Failed to protect " + label + ": " + err.Error())
+			return err
+		}
+		_, err := ctx.Reply("✅ " + label + " can no longer be banned or kicked through the bot")
+		return err
+
+	case "remove", "delete":
+		removed, err := c.Protection.RemoveProtectedTarget(target.GuildID, target.TargetType, target.TargetID)
+		if err != nil {
+			ctx.Reply("❌ Failed to remove protection: " + err.Error())
+			return err
+		}
+		if !removed {
+			ctx.Reply(label + " isn't protected")
+			return nil
+		}
+		_, err = ctx.Reply("✅ " + label + " is no longer protected")
+		return err
+	}
+
+	ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+	return nil
+}
+
+// isRole reports whether an ID belongs to one of the guild's roles
+func (c *ProtectCommand) isRole(ctx *Context, id string) bool {
+	roles, err := ctx.Session.GuildRoles(ctx.Message.GuildID)
+	if err != nil {
+		return false
+	}
+	for _, role := range roles {
+		if role.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ProtectCommand) showList(ctx *Context) error {
+	targets := c.Protection.GetProtectedTargets(ctx.Message.GuildID)
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].TargetType != targets[j].TargetType {
+			return targets[i].TargetType < targets[j].TargetType
+		}
+		return targets[i].CreatedAt.Before(targets[j].CreatedAt)
+	})
+
+	lines := make([]string, 0, len(targets))
+	for _, t := range targets {
+		lines = append(lines, fmt.Sprintf("%s — added by <@%s>", protectedLabel(t.TargetType, t.TargetID), t.AddedBy))
+	}
+
+	description := "Nobody is protected beyond the server owner and bot owners."
+	if len(lines) > 0 {
+		description = truncateField(strings.Join(lines, "\n"))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "🛡️ Protected Roles & Users",
+		Description: description,
+		Color:       0xFF51FF,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
+
+func protectedLabel(targetType, targetID string) string {
+	if targetType == ProtectRole {
+		return "<@&" + targetID + ">"
+	}
+	return "<@" + targetID + ">"
+}
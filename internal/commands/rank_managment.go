@@ -153,7 +153,7 @@ func (c *AddRankCommand) Execute(ctx *Context) error {
 
 	if len(ctx.Message.MentionRoles) == 0 || len(ctx.Args) < 2 {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"❌ Usage: `add-rank @role <level>`\nExample: `add-rank @Member 5`")
+			"❌ Usage: `"+ctx.GetPrefix()+"add-rank @role <level>`\nExample: `add-rank @Member 5`")
 		return nil
 	}
 
@@ -229,7 +229,7 @@ func (c *RemoveRankCommand) Execute(ctx *Context) error {
 
 	if len(ctx.Message.MentionRoles) == 0 {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"❌ Usage: `remove-rank @role`")
+			"❌ Usage: `"+ctx.GetPrefix()+"remove-rank @role`")
 		return nil
 	}
 
@@ -346,8 +346,92 @@ func (c *ListRanksCommand) Execute(ctx *Context) error {
 	return err
 }
 
-// ApplyRanksCommand applies rank roles to all eligible members
-type ApplyRanksCommand struct{}
+// Rank reward modes
+const (
+	RankModeStack   = "stack"   // Keep every rank role earned so far
+	RankModeReplace = "replace" // Keep only the highest rank role reached
+)
+
+// RankSyncResult summarises a guild-wide rank role reconcile
+type RankSyncResult struct {
+	Processed int
+	Added     int
+	Removed   int
+	Errors    int
+}
+
+// RankRoleStore reads the rank reward mode and reconciles members' rank roles
+type RankRoleStore interface {
+	GetRankMode(guildID string) string
+	SetRankMode(guildID, mode string) error
+	ReconcileRankRoles(guildID string, progress func(processed int)) (RankSyncResult, error)
+}
+
+// RankModeCommand switches between stacking rank roles and keeping only the highest
+type RankModeCommand struct {
+	Ranks RankRoleStore
+}
+
+func (c *RankModeCommand) Name() string        { return "rank-mode" }
+func (c *RankModeCommand) Aliases() []string   { return []string{"rankmode"} }
+func (c *RankModeCommand) Description() string { return "Stack rank roles or keep only the highest" }
+func (c *RankModeCommand) Usage() string       { return "rank-mode [stack|replace]" }
+func (c *RankModeCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageRoles}
+}
+func (c *RankModeCommand) MasterOnly() bool { return false }
+func (c *RankModeCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "mode", Description: "How rank roles are rewarded", Type: ArgString, Choices: []string{
+			RankModeStack, RankModeReplace,
+		}},
+	}
+}
+
+func (c *RankModeCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	guildID := ctx.Message.GuildID
+
+	if len(ctx.Args) == 0 {
+		_, err := ctx.ReplyEmbed(&discordgo.MessageEmbed{
+			Title:       "Rank Reward Mode",
+			Description: "Currently **" + c.Ranks.GetRankMode(guildID) + "**\n\n" + rankModeHelp(),
+			Color:       0xFF51FF,
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+			},
+		})
+		return err
+	}
+
+	mode := strings.ToLower(ctx.Args[0])
+	if mode != RankModeStack && mode != RankModeReplace {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	if err := c.Ranks.SetRankMode(guildID, mode); err != nil {
+		ctx.Reply("❌ Failed to save rank mode: " + err.Error())
+		return err
+	}
+
+	_, err := ctx.Reply(fmt.Sprintf("✅ Rank mode set to **%s**. Run `%sapply-ranks` to update existing members.",
+		mode, ctx.GetPrefix()))
+	return err
+}
+
+func rankModeHelp() string {
+	return "`stack` — members keep every rank role they've earned\n" +
+		"`replace` — members only keep the highest rank role they've reached"
+}
+
+// ApplyRanksCommand reconciles rank roles for every member with XP
+type ApplyRanksCommand struct {
+	Ranks RankRoleStore
+}
 
 func (c *ApplyRanksCommand) Name() string        { return "apply-ranks" }
 func (c *ApplyRanksCommand) Aliases() []string   { return []string{"applyranks"} }
@@ -365,71 +449,20 @@ func (c *ApplyRanksCommand) Execute(ctx *Context) error {
 
 	statusMsg, err := ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, &discordgo.MessageEmbed{
 		Title:       "🔄 Applying Ranks...",
-		Description: "Fetching rank configuration and member data...",
+		Description: "Comparing member roles with their levels...",
 		Color:       0xFFAA00,
 	})
 	if err != nil {
 		return err
 	}
 
-	// Get database
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
-
-	// Get all rank roles
-	rows, err := db.(interface {
-		Query(query string, args ...interface{}) (interface {
-			Next() bool
-			Scan(dest ...interface{}) error
-			Close() error
-		}, error)
-	}).Query(`
-		SELECT role_id, level 
-		FROM ranks 
-		WHERE guild_id = ? 
-		ORDER BY level ASC`,
-		ctx.Message.GuildID)
-
-	if err != nil {
-		ctx.Session.ChannelMessageEditEmbed(ctx.Message.ChannelID, statusMsg.ID, &discordgo.MessageEmbed{
-			Title:       "❌ Failed",
-			Description: fmt.Sprintf("Error fetching ranks: %v", err),
-			Color:       0xFF0000,
-		})
-		return err
-	}
-
-	rankRoles := make(map[int]string) // level -> roleID
-	for rows.Next() {
-		var roleID string
-		var level int
-		if err := rows.Scan(&roleID, &level); err == nil {
-			rankRoles[level] = roleID
-		}
-	}
-	rows.Close()
-
-	if len(rankRoles) == 0 {
+	result, err := c.Ranks.ReconcileRankRoles(ctx.Message.GuildID, func(processed int) {
 		ctx.Session.ChannelMessageEditEmbed(ctx.Message.ChannelID, statusMsg.ID, &discordgo.MessageEmbed{
-			Title:       "⚠️ No Ranks Configured",
-			Description: "Use `sync-ranks` or `add-rank` to configure rank roles first.",
+			Title:       "🔄 Applying Ranks...",
+			Description: fmt.Sprintf("Processed %d members...", processed),
 			Color:       0xFFAA00,
 		})
-		return nil
-	}
-
-	// Get all members with levels
-	levelRows, err := db.(interface {
-		Query(query string, args ...interface{}) (interface {
-			Next() bool
-			Scan(dest ...interface{}) error
-			Close() error
-		}, error)
-	}).Query(`
-		SELECT user_id, level 
-		FROM glevel 
-		WHERE guild_id = ?`,
-		ctx.Message.GuildID)
-
+	})
 	if err != nil {
 		ctx.Session.ChannelMessageEditEmbed(ctx.Message.ChannelID, statusMsg.ID, &discordgo.MessageEmbed{
 			Title:       "❌ Failed",
@@ -438,72 +471,35 @@ func (c *ApplyRanksCommand) Execute(ctx *Context) error {
 		})
 		return err
 	}
-	defer levelRows.Close()
-
-	processed := 0
-	rolesAdded := 0
-	errors := 0
-
-	for levelRows.Next() {
-		var userID string
-		var level int
-		
-		if err := levelRows.Scan(&userID, &level); err != nil {
-			continue
-		}
-
-		// Find all roles this user should have
-		for reqLevel, roleID := range rankRoles {
-			if level >= reqLevel {
-				err := ctx.Session.GuildMemberRoleAdd(ctx.Message.GuildID, userID, roleID)
-				if err == nil {
-					rolesAdded++
-				} else {
-					errors++
-				}
-			}
-		}
-
-		processed++
-
-		// Update every 50 members
-		if processed%50 == 0 {
-			ctx.Session.ChannelMessageEditEmbed(ctx.Message.ChannelID, statusMsg.ID, &discordgo.MessageEmbed{
-				Title:       "🔄 Applying Ranks...",
-				Description: fmt.Sprintf("Processed %d members...", processed),
-				Color:       0xFFAA00,
-			})
-		}
-	}
 
 	// Final summary
 	embed := &discordgo.MessageEmbed{
 		Title:       "✅ Ranks Applied",
-		Description: fmt.Sprintf("Finished applying rank roles to members."),
+		Description: fmt.Sprintf("Rank roles now match member levels (mode: **%s**).", c.Ranks.GetRankMode(ctx.Message.GuildID)),
 		Color:       0x43CC24,
 		Fields: []*discordgo.MessageEmbedField{
 			{
 				Name:   "Members Processed",
-				Value:  strconv.Itoa(processed),
+				Value:  strconv.Itoa(result.Processed),
 				Inline: true,
 			},
 			{
 				Name:   "Roles Added",
-				Value:  strconv.Itoa(rolesAdded),
+				Value:  strconv.Itoa(result.Added),
 				Inline: true,
 			},
 			{
-				Name:   "Rank Roles",
-				Value:  fmt.Sprintf("%d configured", len(rankRoles)),
+				Name:   "Roles Removed",
+				Value:  strconv.Itoa(result.Removed),
 				Inline: true,
 			},
 		},
 	}
 
-	if errors > 0 {
+	if result.Errors > 0 {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
 			Name:   "⚠️ Errors",
-			Value:  fmt.Sprintf("%d errors (missing permissions?)", errors),
+			Value:  fmt.Sprintf("%d members could not be updated (missing permissions?)", result.Errors),
 			Inline: true,
 		})
 		embed.Color = 0xFFAA00
@@ -0,0 +1,312 @@
+package commands
+
+import (
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ArgumentType is the Discord option type used for a declared argument
+type ArgumentType int
+
+const (
+	ArgString ArgumentType = iota
+	ArgInteger
+	ArgNumber
+	ArgBoolean
+	ArgUser
+	ArgChannel
+	ArgRole
+	ArgAttachment
+)
+
+// Argument describes one positional argument of a command.
+// Arguments are declared in the order the command reads them from ctx.Args;
+// required arguments must come before optional ones (a Discord restriction).
+type Argument struct {
+	Name        string
+	Description string
+	Type        ArgumentType
+	Required    bool
+
+	// Separator is inserted into Args before this argument's value, for
+	// commands that split their input on it (e.g. "|" before a ban reason)
+	Separator string
+
+	// Choices restricts a string argument to a fixed set of values
+	Choices []string
+}
+
+// ArgumentProvider is implemented by commands that declare typed arguments.
+// Commands without it get a single free-form "arguments" option.
+type ArgumentProvider interface {
+	Arguments() []Argument
+}
+
+// slashNamePattern matches names Discord accepts for chat input commands
+var slashNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+var argumentOptionTypes = map[ArgumentType]discordgo.ApplicationCommandOptionType{
+	ArgString:     discordgo.ApplicationCommandOptionString,
+	ArgInteger:    discordgo.ApplicationCommandOptionInteger,
+	ArgNumber:     discordgo.ApplicationCommandOptionNumber,
+	ArgBoolean:    discordgo.ApplicationCommandOptionBoolean,
+	ArgUser:       discordgo.ApplicationCommandOptionUser,
+	ArgChannel:    discordgo.ApplicationCommandOptionChannel,
+	ArgRole:       discordgo.ApplicationCommandOptionRole,
+	ArgAttachment: discordgo.ApplicationCommandOptionAttachment,
+}
+
+// ApplicationCommands builds the slash command definitions for every registered
+// command. Owner-only commands are left out since they can't be hidden per user.
+func (m *Manager) ApplicationCommands() []*discordgo.ApplicationCommand {
+	names := make([]string, 0, len(m.commands))
+	for name := range m.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	appCommands := []*discordgo.ApplicationCommand{}
+	for _, name := range names {
+		cmd := m.commands[name]
+		if cmd.MasterOnly() || !slashNamePattern.MatchString(name) {
+			continue
+		}
+
+		appCmd := &discordgo.ApplicationCommand{
+			Name:        name,
+			Description: truncateDescription(cmd.Description()),
+			Options:     commandOptions(cmd),
+			Contexts:    &[]discordgo.InteractionContextType{discordgo.InteractionContextGuild},
+		}
+
+		// Hide the command from members who couldn't run it anyway
+		if perms := cmd.RequiredPermissions(); len(perms) > 0 {
+			var required int64
+			for _, perm := range perms {
+				required |= perm
+			}
+			appCmd.DefaultMemberPermissions = &required
+		}
+
+		appCommands = append(appCommands, appCmd)
+	}
+
+	return appCommands
+}
+
+// ExecuteInteraction runs a slash command through the same checks as a prefix command
+func (m *Manager) ExecuteInteraction(ctx *Context) error {
+	i := ctx.Interaction
+	data := i.ApplicationCommandData()
+
+	cmd, err := m.Get(data.Name)
+	if err != nil {
+		return nil
+	}
+
+	// Acknowledge first; commands may take longer than the 3 second deadline
+	err = ctx.Session.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx.Message, ctx.Args = messageFromInteraction(ctx.Session, i, cmd, data)
+
+	err = m.run(ctx, cmd)
+
+	if !ctx.HasReplied() {
+		if err != nil {
+			// Surface the error instead of leaving the response hanging
+			ctx.Reply("❌ " + err.Error())
+		} else if delErr := ctx.Session.InteractionResponseDelete(i.Interaction); delErr != nil {
+			// Command answered through the channel directly
+			log.Printf("Error deleting deferred response: %v", delErr)
+		}
+	}
+
+	return err
+}
+
+// commandOptions converts a command's declared arguments into Discord options
+func commandOptions(cmd Command) []*discordgo.ApplicationCommandOption {
+	provider, ok := cmd.(ArgumentProvider)
+	if !ok {
+		return []*discordgo.ApplicationCommandOption{{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "arguments",
+			Description: truncateDescription("Usage: " + cmd.Usage()),
+		}}
+	}
+
+	options := []*discordgo.ApplicationCommandOption{}
+	for _, arg := range provider.Arguments() {
+		description := arg.Description
+		if description == "" {
+			description = arg.Name
+		}
+
+		option := &discordgo.ApplicationCommandOption{
+			Type:        argumentOptionTypes[arg.Type],
+			Name:        strings.ToLower(arg.Name),
+			Description: truncateDescription(description),
+			Required:    arg.Required,
+		}
+		for _, choice := range arg.Choices {
+			option.Choices = append(option.Choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  choice,
+				Value: choice,
+			})
+		}
+
+		options = append(options, option)
+	}
+	return options
+}
+
+// messageFromInteraction builds the message and argument list a prefix
+// invocation would have produced, so commands can't tell the difference
+func messageFromInteraction(s *discordgo.Session, i *discordgo.InteractionCreate, cmd Command, data discordgo.ApplicationCommandInteractionData) (*discordgo.MessageCreate, []string) {
+	author := i.User
+	if i.Member != nil && i.Member.User != nil {
+		author = i.Member.User
+	}
+
+	msg := &discordgo.Message{
+		ID:        i.ID,
+		ChannelID: i.ChannelID,
+		GuildID:   i.GuildID,
+		Author:    author,
+		Member:    i.Member,
+	}
+	if ts, err := discordgo.SnowflakeTimestamp(i.ID); err == nil {
+		msg.Timestamp = ts
+	} else {
+		msg.Timestamp = time.Now()
+	}
+
+	values := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, opt := range data.Options {
+		values[opt.Name] = opt
+	}
+
+	args := []string{}
+	provider, ok := cmd.(ArgumentProvider)
+	if !ok {
+		if opt, exists := values["arguments"]; exists {
+			args = strings.Fields(opt.StringValue())
+			addTypedMentions(s, msg, args)
+		}
+	} else {
+		for _, arg := range provider.Arguments() {
+			opt, exists := values[strings.ToLower(arg.Name)]
+			if !exists {
+				continue
+			}
+
+			if arg.Separator != "" {
+				args = append(args, arg.Separator)
+			}
+
+			switch arg.Type {
+			case ArgInteger:
+				args = append(args, strconv.FormatInt(opt.IntValue(), 10))
+			case ArgNumber:
+				args = append(args, strconv.FormatFloat(opt.FloatValue(), 'f', -1, 64))
+			case ArgBoolean:
+				args = append(args, strconv.FormatBool(opt.BoolValue()))
+			case ArgUser:
+				userID, _ := opt.Value.(string)
+				args = append(args, "<@"+userID+">")
+				if data.Resolved != nil {
+					if user, found := data.Resolved.Users[userID]; found {
+						msg.Mentions = append(msg.Mentions, user)
+					}
+				}
+			case ArgRole:
+				roleID, _ := opt.Value.(string)
+				args = append(args, "<@&"+roleID+">")
+				msg.MentionRoles = append(msg.MentionRoles, roleID)
+			case ArgChannel:
+				channelID, _ := opt.Value.(string)
+				args = append(args, "<#"+channelID+">")
+				if data.Resolved != nil {
+					if channel, found := data.Resolved.Channels[channelID]; found {
+						msg.MentionChannels = append(msg.MentionChannels, channel)
+					}
+				}
+			case ArgAttachment:
+				attachmentID, _ := opt.Value.(string)
+				if data.Resolved != nil {
+					if attachment, found := data.Resolved.Attachments[attachmentID]; found {
+						msg.Attachments = append(msg.Attachments, attachment)
+					}
+				}
+			default:
+				args = append(args, strings.Fields(opt.StringValue())...)
+			}
+		}
+	}
+
+	msg.Content = strings.TrimSpace("/" + data.Name + " " + strings.Join(args, " "))
+
+	return &discordgo.MessageCreate{Message: msg}, args
+}
+
+// addTypedMentions fills in the mentions Discord would have parsed from a
+// message, for commands whose arguments arrive as one free-form string.
+// Users and channels come from the state cache when possible; otherwise only
+// their IDs are known.
+func addTypedMentions(s *discordgo.Session, msg *discordgo.Message, args []string) {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "<") || !strings.HasSuffix(arg, ">") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(arg, "<@&"):
+			if roleID := parseRoleID(arg); isSnowflake(roleID) {
+				msg.MentionRoles = append(msg.MentionRoles, roleID)
+			}
+		case strings.HasPrefix(arg, "<@"):
+			userID := parseUserID(arg)
+			if !isSnowflake(userID) {
+				continue
+			}
+			user := &discordgo.User{ID: userID}
+			if member, err := s.State.Member(msg.GuildID, userID); err == nil && member.User != nil {
+				user = member.User
+			}
+			msg.Mentions = append(msg.Mentions, user)
+		case strings.HasPrefix(arg, "<#"):
+			channelID := parseChannelID(arg)
+			if !isSnowflake(channelID) {
+				continue
+			}
+			channel := &discordgo.Channel{ID: channelID, GuildID: msg.GuildID}
+			if cached, err := s.State.Channel(channelID); err == nil {
+				channel = cached
+			}
+			msg.MentionChannels = append(msg.MentionChannels, channel)
+		}
+	}
+}
+
+// truncateDescription fits a description into Discord's 100 character limit
+func truncateDescription(s string) string {
+	if s == "" {
+		return "No description"
+	}
+	runes := []rune(s)
+	if len(runes) > 100 {
+		return string(runes[:97]) + "..."
+	}
+	return s
+}
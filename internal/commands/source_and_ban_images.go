@@ -95,6 +95,12 @@ func (c *AddBanImageCommand) RequiredPermissions() []int64 {
 	return []int64{discordgo.PermissionBanMembers}
 }
 func (c *AddBanImageCommand) MasterOnly() bool { return false }
+func (c *AddBanImageCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "url", Description: "Link to the image", Type: ArgString},
+		{Name: "image", Description: "Image to upload instead of a link", Type: ArgAttachment},
+	}
+}
 
 func (c *AddBanImageCommand) Execute(ctx *Context) error {
 	if ctx.Message == nil || ctx.Message.GuildID == "" {
@@ -123,7 +129,7 @@ func (c *AddBanImageCommand) Execute(ctx *Context) error {
 		}
 	} else {
 		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID,
-			"❌ Usage: `add-ban-image <url>` or attach an image")
+			"❌ Usage: `"+ctx.GetPrefix()+"add-ban-image <url>` or attach an image")
 		return nil
 	}
 
@@ -161,7 +167,7 @@ func (c *AddBanImageCommand) Execute(ctx *Context) error {
 	if ext == "" || len(ext) > 5 {
 		ext = ".png"
 	}
-	filename := fmt.Sprintf("ban_%d%s", ctx.Message.ID, ext)
+	filename := fmt.Sprintf("ban_%s%s", ctx.Message.ID, ext)
 	filepath := filepath.Join(guildBanPath, filename)
 
 	// Save the image
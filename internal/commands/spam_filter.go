@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// SpamFilterSettings is a guild's copy of [spam_filter] from config.toml,
+// which supplies the defaults
+type SpamFilterSettings struct {
+	GuildID                       string
+	Enabled                       bool
+	MainChannelPrefix             string
+	NSFWChannelPrefix             string
+	AllowInvites                  bool
+	MaxConsecutiveMessages        int
+	WarningLifetime               int // Seconds
+	AutoBanOnUnauthorizedCommands bool
+	AutoBanOnHierarchyViolation   bool
+	AllowSameRoleModeration       bool
+	AutoBanOnEveryoneMention      bool
+	AutoBanOnHereMention          bool
+	ExemptRolesFromMentionBan     []string
+	Customised                    bool // Stored for this guild rather than taken from config.toml
+}
+
+// SpamFilterStore reads and writes per-guild spam filter settings
+type SpamFilterStore interface {
+	GetSpamFilterSettings(guildID string) *SpamFilterSettings
+	SaveSpamFilterSettings(settings *SpamFilterSettings) error
+	ResetSpamFilterSettings(guildID string) error
+}
+
+// SetSpamFilterCommand views and changes a guild's spam filter settings
+type SetSpamFilterCommand struct {
+	Settings SpamFilterStore
+}
+
+func (c *SetSpamFilterCommand) Name() string { return "set-spamfilter" }
+func (c *SetSpamFilterCommand) Aliases() []string {
+	return []string{"spamfilter", "togglespam", "spam-filter"}
+}
+func (c *SetSpamFilterCommand) Description() string {
+	return "View or change this server's spam filter settings"
+}
+func (c *SetSpamFilterCommand) Usage() string {
+	return "set-spamfilter [on|off|<setting> <value>|exempt add|remove <@role>|reset]"
+}
+func (c *SetSpamFilterCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionAdministrator}
+}
+func (c *SetSpamFilterCommand) MasterOnly() bool { return false }
+
+func (c *SetSpamFilterCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	guildID := ctx.Message.GuildID
+	settings := c.Settings.GetSpamFilterSettings(guildID)
+
+	if len(ctx.Args) == 0 {
+		return c.showStatus(ctx, settings)
+	}
+
+	setting := strings.ToLower(ctx.Args[0])
+	if setting == "reset" || setting == "defaults" {
+		if err := c.Settings.ResetSpamFilterSettings(guildID); err != nil {
+			ctx.Reply("❌ Failed to reset spam filter settings: " + err.Error())
+			return err
+		}
+		_, err := ctx.Reply("✅ Spam filter settings reset to the config.toml defaults")
+		return err
+	}
+
+	if enabled, ok := parseToggle(setting); ok && len(ctx.Args) == 1 {
+		settings.Enabled = enabled
+		if err := c.Settings.SaveSpamFilterSettings(settings); err != nil {
+			ctx.Reply("❌ Failed to save spam filter settings: " + err.Error())
+			return err
+		}
+		_, err := ctx.Reply("✅ Spam filter " + enabledWord(enabled))
+		return err
+	}
+
+	if len(ctx.Args) < 2 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`\nSettings: " + strings.Join(spamFilterSettingNames, ", "))
+		return nil
+	}
+	value := ctx.Args[1]
+	var result string
+
+	switch setting {
+	case "main-prefix", "nsfw-prefix":
+		prefix := strings.ToLower(value)
+		if prefix == "none" || prefix == "off" {
+			prefix = ""
+		}
+		if len(prefix) > 50 {
+			ctx.Reply("❌ Prefixes can be at most 50 characters")
+			return nil
+		}
+		if setting == "main-prefix" {
+			settings.MainChannelPrefix = prefix
+		} else {
+			settings.NSFWChannelPrefix = prefix
+		}
+		result = fmt.Sprintf("%s set to %s", setting, formatPrefixSetting(prefix))
+
+	case "max-messages":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 2 || n > 50 {
+			ctx.Reply("❌ max-messages must be between 2 and 50")
+			return nil
+		}
+		settings.MaxConsecutiveMessages = n
+		result = fmt.Sprintf("max-messages set to %d", n)
+
+	case "warning-lifetime":
+		seconds, err := strconv.Atoi(value)
+		if err != nil {
+			duration, ok := parseDuration(value)
+			if !ok {
+				ctx.Reply("❌ warning-lifetime takes seconds or a time like `30s`, `0` to keep warnings")
+				return nil
+			}
+			seconds = int(duration.Seconds())
+		}
+		if seconds < 0 || seconds > 3600 {
+			ctx.Reply("❌ warning-lifetime must be between 0 and 3600 seconds")
+			return nil
+		}
+		settings.WarningLifetime = seconds
+		result = "warning-lifetime set to " + formatWarningLifetime(seconds)
+
+	case "exempt", "exempt-roles":
+		if len(ctx.Args) != 3 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "set-spamfilter exempt add|remove <@role>`")
+			return nil
+		}
+		roleID := parseRoleID(ctx.Args[2])
+		if !isSnowflake(roleID) {
+			ctx.Reply("❌ Please mention a role or give its ID")
+			return nil
+		}
+		switch strings.ToLower(value) {
+		case "add":
+			for _, id := range settings.ExemptRolesFromMentionBan {
+				if id == roleID {
+					ctx.Reply("<@&" + roleID + "> is already exempt")
+					return nil
+				}
+			}
+			settings.ExemptRolesFromMentionBan = append(settings.ExemptRolesFromMentionBan, roleID)
+			result = "<@&" + roleID + "> is now exempt from mention bans"
+		case "remove":
+			kept := settings.ExemptRolesFromMentionBan[:0]
+			for _, id := range settings.ExemptRolesFromMentionBan {
+				if id != roleID {
+					kept = append(kept, id)
+				}
+			}
+			if len(kept) == len(settings.ExemptRolesFromMentionBan) {
+				ctx.Reply("<@&" + roleID + "> isn't exempt")
+				return nil
+			}
+			settings.ExemptRolesFromMentionBan = kept
+			result = "<@&" + roleID + "> is no longer exempt from mention bans"
+		default:
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "set-spamfilter exempt add|remove <@role>`")
+			return nil
+		}
+
+	default:
+		toggle := spamFilterToggle(settings, setting)
+		if toggle == nil {
+			ctx.Reply("❌ Unknown setting. Settings: " + strings.Join(spamFilterSettingNames, ", "))
+			return nil
+		}
+		enabled, ok := parseToggle(value)
+		if !ok {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "set-spamfilter " + setting + " on|off`")
+			return nil
+		}
+		*toggle = enabled
+		result = fmt.Sprintf("%s %s", setting, enabledWord(enabled))
+	}
+
+	if err := c.Settings.SaveSpamFilterSettings(settings); err != nil {
+		ctx.Reply("❌ Failed to save spam filter settings: " + err.Error())
+		return err
+	}
+
+	_, err := ctx.Reply("✅ " + result)
+	return err
+}
+
+// spamFilterSettingNames lists the settings set-spamfilter accepts
+var spamFilterSettingNames = []string{
+	"main-prefix", "nsfw-prefix", "allow-invites", "max-messages", "warning-lifetime",
+	"ban-unauthorized", "ban-hierarchy", "same-role-moderation", "ban-everyone", "ban-here", "exempt",
+}
+
+// spamFilterToggle returns the on/off setting with the given name
+func spamFilterToggle(settings *SpamFilterSettings, name string) *bool {
+	switch name {
+	case "allow-invites":
+		return &settings.AllowInvites
+	case "ban-unauthorized":
+		return &settings.AutoBanOnUnauthorizedCommands
+	case "ban-hierarchy":
+		return &settings.AutoBanOnHierarchyViolation
+	case "same-role-moderation":
+		return &settings.AllowSameRoleModeration
+	case "ban-everyone":
+		return &settings.AutoBanOnEveryoneMention
+	case "ban-here":
+		return &settings.AutoBanOnHereMention
+	}
+	return nil
+}
+
+func (c *SetSpamFilterCommand) showStatus(ctx *Context, settings *SpamFilterSettings) error {
+	exempt := "None"
+	if len(settings.ExemptRolesFromMentionBan) > 0 {
+		roles := make([]string, len(settings.ExemptRolesFromMentionBan))
+		for i, id := range settings.ExemptRolesFromMentionBan {
+			roles[i] = "<@&" + id + ">"
+		}
+		exempt = truncateField(strings.Join(roles, ", "))
+	}
+
+	source := "Using the config.toml defaults"
+	if settings.Customised {
+		source = "Customised for this server"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Spam Filter Settings",
+		Description: source,
+		Color:       0xFF51FF,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Spam Filter", Value: onOff(settings.Enabled), Inline: false},
+			{Name: "main-prefix", Value: formatPrefixSetting(settings.MainChannelPrefix), Inline: true},
+			{Name: "nsfw-prefix", Value: formatPrefixSetting(settings.NSFWChannelPrefix), Inline: true},
+			{Name: "allow-invites", Value: onOff(settings.AllowInvites), Inline: true},
+			{Name: "max-messages", Value: strconv.Itoa(settings.MaxConsecutiveMessages), Inline: true},
+			{Name: "warning-lifetime", Value: formatWarningLifetime(settings.WarningLifetime), Inline: true},
+			{Name: "same-role-moderation", Value: onOff(settings.AllowSameRoleModeration), Inline: true},
+			{Name: "ban-unauthorized", Value: onOff(settings.AutoBanOnUnauthorizedCommands), Inline: true},
+			{Name: "ban-hierarchy", Value: onOff(settings.AutoBanOnHierarchyViolation), Inline: true},
+			{Name: "ban-everyone", Value: onOff(settings.AutoBanOnEveryoneMention), Inline: true},
+			{Name: "ban-here", Value: onOff(settings.AutoBanOnHereMention), Inline: true},
+			{Name: "exempt", Value: exempt, Inline: false},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
+
+func formatPrefixSetting(prefix string) string {
+	if prefix == "" {
+		return "None"
+	}
+	return "`" + prefix + "`"
+}
+
+func formatWarningLifetime(seconds int) string {
+	if seconds == 0 {
+		return "Kept"
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
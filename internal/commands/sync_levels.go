@@ -11,7 +11,9 @@ import (
 )
 
 // SyncLevelsCommand syncs member levels based on role names
-type SyncLevelsCommand struct{}
+type SyncLevelsCommand struct {
+	DB XPDatabase
+}
 
 func (c *SyncLevelsCommand) Name() string        { return "sync-levels" }
 func (c *SyncLevelsCommand) Aliases() []string   { return []string{"synclvl", "syncxp"} }
@@ -109,8 +111,8 @@ func (c *SyncLevelsCommand) Execute(ctx *Context) error {
 	}
 
 	// Process members
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
-	
+	curve := c.DB.LevelCurve(ctx.Message.GuildID)
+
 	processed := 0
 	updated := 0
 	skipped := 0
@@ -142,19 +144,11 @@ func (c *SyncLevelsCommand) Execute(ctx *Context) error {
 			continue
 		}
 
-		// Calculate XP for this level (start of level)
-		xp := 0
-		for lvl := 0; lvl < highestLevel; lvl++ {
-			xp += 5*lvl*lvl + 50*lvl + 100
-		}
+		// XP at the start of this level on the guild's curve
+		xp := curve.ExpForLevel(highestLevel)
 
 		// Update database
-		_, err := db.Exec(`
-			INSERT OR REPLACE INTO glevel (guild_id, user_id, exp, level, enabled)
-			VALUES (?, ?, ?, ?, 'enabled')`,
-			ctx.Message.GuildID, member.User.ID, xp, highestLevel)
-
-		if err != nil {
+		if err := c.DB.SetUserXP(ctx.Message.GuildID, member.User.ID, xp, highestLevel); err != nil {
 			errors++
 		} else {
 			updated++
@@ -0,0 +1,412 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// WarnThreshold punishes a member once their active points reach Points
+type WarnThreshold struct {
+	Points int
+	Step   EscalationStep // timeout, kick or ban
+}
+
+func (t WarnThreshold) String() string {
+	return strconv.Itoa(t.Points) + "=" + t.Step.String()
+}
+
+// WarnSettings configures a guild's warning points
+type WarnSettings struct {
+	GuildID       string
+	Enabled       bool // Whether thresholds punish; points are always recorded
+	PointsPerWarn int
+	DecayDays     int // Points expire this long after the warning
+	Thresholds    []WarnThreshold
+}
+
+// WarningPoint is one warning's entry in the points ledger
+type WarningPoint struct {
+	ID         int
+	GuildID    string
+	UserID     string
+	Points     int
+	Reason     string
+	CaseNumber int
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+// WarnPointStore reads warning point settings and ledgers
+type WarnPointStore interface {
+	GetWarnSettings(guildID string) *WarnSettings
+	SaveWarnSettings(settings *WarnSettings) error
+	GetActiveWarningPoints(guildID, userID string) ([]WarningPoint, error)
+	ClearWarningPoints(guildID, userID string) (int, error)
+	WarnThresholdFor(guildID, moderatorID string, before, after int) (apply, withheld *WarnThreshold)
+}
+
+// TotalPoints adds up a ledger
+func TotalPoints(points []WarningPoint) int {
+	total := 0
+	for _, p := range points {
+		total += p.Points
+	}
+	return total
+}
+
+// ParseWarnThresholds reads thresholds like "3=timeout:1h 5=kick 8=ban"
+func ParseWarnThresholds(args []string) ([]WarnThreshold, error) {
+	var thresholds []WarnThreshold
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			pointsText, stepText, ok := strings.Cut(part, "=")
+			if !ok {
+				return nil, fmt.Errorf("expected <points>=<action>, got %q", part)
+			}
+			threshold, err := parseWarnThreshold(pointsText, stepText)
+			if err != nil {
+				return nil, err
+			}
+			thresholds = append(thresholds, threshold)
+		}
+	}
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i].Points < thresholds[j].Points })
+	return thresholds, nil
+}
+
+func parseWarnThreshold(pointsText, stepText string) (WarnThreshold, error) {
+	points, err := strconv.Atoi(strings.TrimSpace(pointsText))
+	if err != nil || points < 1 || points > 1000 {
+		return WarnThreshold{}, fmt.Errorf("points must be a number from 1 to 1000")
+	}
+	steps, err := ParseEscalation([]string{stepText})
+	if err != nil {
+		return WarnThreshold{}, err
+	}
+	if len(steps) != 1 {
+		return WarnThreshold{}, fmt.Errorf("give one action per threshold")
+	}
+	switch steps[0].Action {
+	case EscalateTimeout, EscalateKick, EscalateBan:
+	default:
+		return WarnThreshold{}, fmt.Errorf("thresholds can only timeout, kick or ban")
+	}
+	return WarnThreshold{Points: points, Step: steps[0]}, nil
+}
+
+// FormatWarnThresholds renders thresholds for display
+func FormatWarnThresholds(thresholds []WarnThreshold) string {
+	if len(thresholds) == 0 {
+		return "None"
+	}
+	lines := make([]string, len(thresholds))
+	for i, t := range thresholds {
+		lines[i] = fmt.Sprintf("%d points → %s", t.Points, t.Step)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CrossedThreshold returns the most severe threshold reached by going from
+// before to after points, or nil if none was crossed
+func CrossedThreshold(thresholds []WarnThreshold, before, after int) *WarnThreshold {
+	var crossed *WarnThreshold
+	for i := range thresholds {
+		t := &thresholds[i]
+		if before < t.Points && after >= t.Points && (crossed == nil || t.Points > crossed.Points) {
+			crossed = t
+		}
+	}
+	return crossed
+}
+
+// TimeoutThreshold returns the strongest timeout threshold reached at points,
+// or nil if there is none
+func TimeoutThreshold(thresholds []WarnThreshold, points int) *WarnThreshold {
+	var strongest *WarnThreshold
+	for i := range thresholds {
+		t := &thresholds[i]
+		if t.Step.Action == EscalateTimeout && points >= t.Points && (strongest == nil || t.Points > strongest.Points) {
+			strongest = t
+		}
+	}
+	return strongest
+}
+
+// WarnConfigCommand configures warning points and their punishments
+type WarnConfigCommand struct {
+	Points WarnPointStore
+}
+
+func (c *WarnConfigCommand) Name() string      { return "warn-config" }
+func (c *WarnConfigCommand) Aliases() []string { return []string{"warnconfig", "warn-points"} }
+func (c *WarnConfigCommand) Description() string {
+	return "Configure warning points, decay and automatic punishments"
+}
+func (c *WarnConfigCommand) Usage() string {
+	return "warn-config [on|off|points <n>|decay <days>|threshold <points> <timeout:1h|kick|ban|off>|thresholds <points>=<action>...]"
+}
+func (c *WarnConfigCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *WarnConfigCommand) MasterOnly() bool { return false }
+
+func (c *WarnConfigCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	settings := c.Points.GetWarnSettings(ctx.Message.GuildID)
+
+	if len(ctx.Args) == 0 {
+		return c.showStatus(ctx, settings)
+	}
+
+	subcommand := strings.ToLower(ctx.Args[0])
+	value := strings.TrimSpace(strings.Join(ctx.Args[1:], " "))
+	var result string
+
+	switch subcommand {
+	case "on", "off", "enable", "disable":
+		settings.Enabled, _ = parseToggle(subcommand)
+		result = "Automatic punishments " + enabledWord(settings.Enabled)
+
+	case "points", "per-warn":
+		points, err := strconv.Atoi(value)
+		if err != nil || points < 1 || points > 100 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "warn-config points <n>` (1-100)")
+			return nil
+		}
+		settings.PointsPerWarn = points
+		result = fmt.Sprintf("Each warning is worth %d point(s)", points)
+
+	case "decay", "expire":
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 || days > 3650 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "warn-config decay <days>` (0 keeps points forever)")
+			return nil
+		}
+		settings.DecayDays = days
+		if days == 0 {
+			result = "Warning points never expire"
+		} else {
+			result = fmt.Sprintf("Warning points expire after %d days", days)
+		}
+
+	case "threshold":
+		if len(ctx.Args) != 3 {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "warn-config threshold <points> <timeout:1h|kick|ban|off>`")
+			return nil
+		}
+		points, err := strconv.Atoi(ctx.Args[1])
+		if err != nil {
+			ctx.Reply("❌ Points must be a number")
+			return nil
+		}
+
+		// Drop any existing threshold at these points first
+		kept := settings.Thresholds[:0]
+		for _, t := range settings.Thresholds {
+			if t.Points != points {
+				kept = append(kept, t)
+			}
+		}
+		settings.Thresholds = kept
+
+		if strings.EqualFold(ctx.Args[2], "off") {
+			result = fmt.Sprintf("Removed the threshold at %d points", points)
+			break
+		}
+		threshold, err := parseWarnThreshold(ctx.Args[1], ctx.Args[2])
+		if err != nil {
+			ctx.Reply("❌ " + err.Error())
+			return nil
+		}
+		settings.Thresholds = append(settings.Thresholds, threshold)
+		sort.Slice(settings.Thresholds, func(i, j int) bool {
+			return settings.Thresholds[i].Points < settings.Thresholds[j].Points
+		})
+		result = fmt.Sprintf("At %d points: %s", threshold.Points, threshold.Step)
+
+	case "thresholds":
+		thresholds, err := ParseWarnThresholds(ctx.Args[1:])
+		if err != nil {
+			ctx.Reply("❌ " + err.Error() + "\nExample: `" + ctx.GetPrefix() + "warn-config thresholds 3=timeout:1h 5=kick 8=ban`")
+			return nil
+		}
+		settings.Thresholds = thresholds
+		result = "Thresholds set:\n" + FormatWarnThresholds(thresholds)
+
+	default:
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	if err := c.Points.SaveWarnSettings(settings); err != nil {
+		ctx.Reply("❌ Failed to save warning settings: " + err.Error())
+		return err
+	}
+
+	_, err := ctx.Reply("✅ " + result)
+	return err
+}
+
+func (c *WarnConfigCommand) showStatus(ctx *Context, settings *WarnSettings) error {
+	decay := "Never"
+	if settings.DecayDays > 0 {
+		decay = fmt.Sprintf("%d days", settings.DecayDays)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Warning Points",
+		Description: "Every warning, manual or automatic, adds points. Reaching a threshold punishes the member once.",
+		Color:       0xFF51FF,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Punishments", Value: onOff(settings.Enabled), Inline: true},
+			{Name: "Points per Warning", Value: strconv.Itoa(settings.PointsPerWarn), Inline: true},
+			{Name: "Points Expire", Value: decay, Inline: true},
+			{Name: "Thresholds", Value: FormatWarnThresholds(settings.Thresholds), Inline: false},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
+
+// PointsCommand shows a member's active warning points
+type PointsCommand struct {
+	Points WarnPointStore
+}
+
+func (c *PointsCommand) Name() string        { return "points" }
+func (c *PointsCommand) Aliases() []string   { return []string{"warnpoints", "warnings"} }
+func (c *PointsCommand) Description() string { return "Show a member's active warning points" }
+func (c *PointsCommand) Usage() string       { return "points <@user|id>" }
+func (c *PointsCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionModerateMembers}
+}
+func (c *PointsCommand) MasterOnly() bool { return false }
+func (c *PointsCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "user", Description: "Member to look up", Type: ArgUser, Required: true},
+	}
+}
+
+func (c *PointsCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) == 0 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+	userID := parseUserID(ctx.Args[0])
+	if !isSnowflake(userID) {
+		ctx.Reply("❌ Please mention a user or give their ID")
+		return nil
+	}
+
+	points, err := c.Points.GetActiveWarningPoints(ctx.Message.GuildID, userID)
+	if err != nil {
+		ctx.Reply("❌ Failed to load warning points: " + err.Error())
+		return err
+	}
+
+	settings := c.Points.GetWarnSettings(ctx.Message.GuildID)
+	total := TotalPoints(points)
+
+	next := "None"
+	for _, t := range settings.Thresholds {
+		if t.Points > total {
+			next = fmt.Sprintf("%s at %d points", t.Step, t.Points)
+			break
+		}
+	}
+
+	lines := make([]string, 0, len(points))
+	for _, p := range points {
+		line := fmt.Sprintf("**+%d** <t:%d:d>", p.Points, p.CreatedAt.Unix())
+		if p.CaseNumber > 0 {
+			line += fmt.Sprintf(" (Case #%d)", p.CaseNumber)
+		}
+		if p.Reason != "" {
+			line += " — " + p.Reason
+		}
+		if !p.ExpiresAt.IsZero() {
+			line += fmt.Sprintf(", expires <t:%d:R>", p.ExpiresAt.Unix())
+		}
+		lines = append(lines, line)
+	}
+	ledger := "No active warnings"
+	if len(lines) > 0 {
+		ledger = truncateField(strings.Join(lines, "\n"))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Warning Points",
+		Description: fmt.Sprintf("<@%s> has **%d** active point(s).", userID, total),
+		Color:       0xFFAA00,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Next Threshold", Value: next, Inline: false},
+			{Name: "Active Warnings", Value: ledger, Inline: false},
+		},
+	}
+
+	_, err = ctx.ReplyEmbed(embed)
+	return err
+}
+
+// ClearPointsCommand wipes a member's warning points, keeping their cases
+type ClearPointsCommand struct {
+	Points WarnPointStore
+}
+
+func (c *ClearPointsCommand) Name() string        { return "clearpoints" }
+func (c *ClearPointsCommand) Aliases() []string   { return []string{"clearwarnpoints", "resetpoints"} }
+func (c *ClearPointsCommand) Description() string { return "Clear a member's warning points" }
+func (c *ClearPointsCommand) Usage() string       { return "clearpoints <@user|id>" }
+func (c *ClearPointsCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionModerateMembers}
+}
+func (c *ClearPointsCommand) MasterOnly() bool { return false }
+
+func (c *ClearPointsCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	if len(ctx.Args) == 0 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+	userID := parseUserID(ctx.Args[0])
+	if !isSnowflake(userID) {
+		ctx.Reply("❌ Please mention a user or give their ID")
+		return nil
+	}
+
+	cleared, err := c.Points.ClearWarningPoints(ctx.Message.GuildID, userID)
+	if err != nil {
+		ctx.Reply("❌ Failed to clear warning points: " + err.Error())
+		return err
+	}
+	if cleared == 0 {
+		ctx.Reply(fmt.Sprintf("<@%s> has no active warning points", userID))
+		return nil
+	}
+
+	_, err = ctx.Reply(fmt.Sprintf("✅ Cleared %d warning point(s) from <@%s>. Their case history is unchanged.", cleared, userID))
+	return err
+}
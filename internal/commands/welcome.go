@@ -0,0 +1,365 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// WelcomeConfig holds the join/leave message settings for a guild
+type WelcomeConfig struct {
+	GuildID        string
+	Enabled        bool
+	ChannelID      string
+	ChannelEnabled bool
+	DMEnabled      bool
+	Message        string
+	EmbedColor     int
+	ImageURL       string
+
+	LeaveEnabled   bool
+	LeaveChannelID string
+	LeaveMessage   string
+}
+
+// WelcomeStore is what the welcome commands need from the bot
+type WelcomeStore interface {
+	GetWelcomeConfig(guildID string) *WelcomeConfig
+	SaveWelcomeConfig(config *WelcomeConfig) error
+}
+
+// welcomePlaceholders documents the template variables for help output
+const welcomePlaceholders = "`{member}` mention, `{user}` name, `{tag}` user#tag, `{id}` user ID, " +
+	"`{guild}` server name, `{count}` member count, `{count_ordinal}` e.g. 42nd"
+
+// RenderWelcomeTemplate fills in the placeholders of a welcome/goodbye template
+func RenderWelcomeTemplate(template string, user *discordgo.User, guildName string, memberCount int) string {
+	return strings.NewReplacer(
+		"{member}", user.Mention(),
+		"{mention}", user.Mention(),
+		"{user}", user.Username,
+		"{username}", user.Username,
+		"{tag}", user.String(),
+		"{id}", user.ID,
+		"{guild}", guildName,
+		"{server}", guildName,
+		"{count}", strconv.Itoa(memberCount),
+		"{count_ordinal}", ordinal(memberCount),
+	).Replace(template)
+}
+
+// BuildWelcomeMessage renders a join or leave message as an embed
+func BuildWelcomeMessage(config *WelcomeConfig, leaving bool, user *discordgo.User, guildName string, memberCount int) *discordgo.MessageSend {
+	template := config.Message
+	if leaving {
+		template = config.LeaveMessage
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Description: RenderWelcomeTemplate(template, user, guildName, memberCount),
+		Color:       config.EmbedColor,
+		Thumbnail: &discordgo.MessageEmbedThumbnail{
+			URL: user.AvatarURL("256"),
+		},
+	}
+
+	send := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+
+	if leaving {
+		embed.Color = 0x808080
+		return send
+	}
+
+	if config.ImageURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{URL: config.ImageURL}
+	}
+
+	// Mentions inside embeds don't ping, so greet in the content too
+	if strings.Contains(template, "{member}") || strings.Contains(template, "{mention}") {
+		send.Content = user.Mention()
+	}
+
+	return send
+}
+
+// ordinal formats 1 as "1st", 22 as "22nd" and so on
+func ordinal(n int) string {
+	suffix := "th"
+	switch n % 100 {
+	case 11, 12, 13:
+	default:
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// guildInfo returns the name and member count used for previews
+func guildInfo(ctx *Context) (string, int) {
+	if guild, err := ctx.Session.State.Guild(ctx.Message.GuildID); err == nil {
+		return guild.Name, guild.MemberCount
+	}
+	if guild, err := ctx.Session.GuildWithCounts(ctx.Message.GuildID); err == nil {
+		return guild.Name, guild.ApproximateMemberCount
+	}
+	return "this server", 0
+}
+
+// WelcomeCommand configures the join message
+type WelcomeCommand struct {
+	Welcome WelcomeStore
+}
+
+func (c *WelcomeCommand) Name() string        { return "welcome" }
+func (c *WelcomeCommand) Aliases() []string   { return []string{"greet", "join-message"} }
+func (c *WelcomeCommand) Description() string { return "Configure the message sent when members join" }
+func (c *WelcomeCommand) Usage() string {
+	return "welcome [on|off|channel <#channel|none>|dm on|off|message <text>|color <#hex>|image <url|none>|preview]"
+}
+func (c *WelcomeCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *WelcomeCommand) MasterOnly() bool { return false }
+
+func (c *WelcomeCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	config := c.Welcome.GetWelcomeConfig(ctx.Message.GuildID)
+
+	if len(ctx.Args) == 0 {
+		return c.showStatus(ctx, config)
+	}
+
+	subcommand := strings.ToLower(ctx.Args[0])
+	value := strings.TrimSpace(strings.Join(ctx.Args[1:], " "))
+	var result string
+
+	switch subcommand {
+	case "on", "off", "enable", "disable":
+		config.Enabled, _ = parseToggle(subcommand)
+		result = "Welcome messages " + enabledWord(config.Enabled)
+
+	case "channel":
+		if value == "" {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "welcome channel <#channel|none>`")
+			return nil
+		}
+		if strings.EqualFold(value, "none") || strings.EqualFold(value, "off") {
+			config.ChannelEnabled = false
+			result = "Welcome messages will no longer be posted in a channel"
+			break
+		}
+		channelID := parseChannelID(value)
+		channel, err := ctx.Session.Channel(channelID)
+		if err != nil || channel.GuildID != ctx.Message.GuildID {
+			ctx.Reply("❌ Invalid channel! Please mention a valid channel in this server.")
+			return nil
+		}
+		config.ChannelID = channelID
+		config.ChannelEnabled = true
+		result = fmt.Sprintf("Welcome messages will be posted in <#%s>", channelID)
+
+	case "dm":
+		enabled, ok := parseToggle(value)
+		if !ok {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "welcome dm on|off`")
+			return nil
+		}
+		config.DMEnabled = enabled
+		result = "Welcome DMs " + enabledWord(enabled)
+
+	case "message", "msg":
+		if value == "" {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "welcome message <text>`\nPlaceholders: " + welcomePlaceholders)
+			return nil
+		}
+		config.Message = value
+		result = "Welcome message updated"
+
+	case "color", "colour":
+		color, ok := parseColor(value)
+		if !ok {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "welcome color <#hex>` (e.g. `#FF51FF`)")
+			return nil
+		}
+		config.EmbedColor = color
+		result = fmt.Sprintf("Embed colour set to `#%06X`", color)
+
+	case "image", "banner":
+		if value == "" {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "welcome image <url|none>`")
+			return nil
+		}
+		if strings.EqualFold(value, "none") || strings.EqualFold(value, "off") {
+			config.ImageURL = ""
+			result = "Welcome image removed"
+			break
+		}
+		if !strings.HasPrefix(value, "http://") && !strings.HasPrefix(value, "https://") {
+			ctx.Reply("❌ The image must be an http(s) URL")
+			return nil
+		}
+		config.ImageURL = value
+		result = "Welcome image updated"
+
+	case "preview", "test":
+		name, count := guildInfo(ctx)
+		_, err := ctx.ReplyComplex(BuildWelcomeMessage(config, false, ctx.Message.Author, name, count))
+		return err
+
+	default:
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	if err := c.Welcome.SaveWelcomeConfig(config); err != nil {
+		ctx.Reply("❌ Failed to save welcome settings: " + err.Error())
+		return err
+	}
+
+	_, err := ctx.Reply("✅ " + result)
+	return err
+}
+
+func (c *WelcomeCommand) showStatus(ctx *Context, config *WelcomeConfig) error {
+	channel := "Server system channel"
+	if !config.ChannelEnabled {
+		channel = "Disabled"
+	} else if config.ChannelID != "" {
+		channel = fmt.Sprintf("<#%s>", config.ChannelID)
+	}
+
+	image := "None"
+	if config.ImageURL != "" {
+		image = config.ImageURL
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Welcome Messages",
+		Color: config.EmbedColor,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Status", Value: onOff(config.Enabled), Inline: true},
+			{Name: "Channel", Value: channel, Inline: true},
+			{Name: "DM", Value: onOff(config.DMEnabled), Inline: true},
+			{Name: "Message", Value: config.Message, Inline: false},
+			{Name: "Image", Value: image, Inline: false},
+			{Name: "Placeholders", Value: welcomePlaceholders, Inline: false},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	}
+
+	_, err := ctx.ReplyEmbed(embed)
+	return err
+}
+
+// GoodbyeCommand configures the leave message
+type GoodbyeCommand struct {
+	Welcome WelcomeStore
+}
+
+func (c *GoodbyeCommand) Name() string        { return "goodbye" }
+func (c *GoodbyeCommand) Aliases() []string   { return []string{"leave-message", "farewell"} }
+func (c *GoodbyeCommand) Description() string { return "Configure the message sent when members leave" }
+func (c *GoodbyeCommand) Usage() string {
+	return "goodbye [on|off|channel <#channel|welcome>|message <text>|preview]"
+}
+func (c *GoodbyeCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *GoodbyeCommand) MasterOnly() bool { return false }
+
+func (c *GoodbyeCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	config := c.Welcome.GetWelcomeConfig(ctx.Message.GuildID)
+
+	if len(ctx.Args) == 0 {
+		channel := "Same as welcome"
+		if config.LeaveChannelID != "" {
+			channel = fmt.Sprintf("<#%s>", config.LeaveChannelID)
+		}
+
+		_, err := ctx.ReplyEmbed(&discordgo.MessageEmbed{
+			Title: "Goodbye Messages",
+			Color: 0x808080,
+			Fields: []*discordgo.MessageEmbedField{
+				{Name: "Status", Value: onOff(config.LeaveEnabled), Inline: true},
+				{Name: "Channel", Value: channel, Inline: true},
+				{Name: "Message", Value: config.LeaveMessage, Inline: false},
+				{Name: "Placeholders", Value: welcomePlaceholders, Inline: false},
+			},
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+			},
+		})
+		return err
+	}
+
+	subcommand := strings.ToLower(ctx.Args[0])
+	value := strings.TrimSpace(strings.Join(ctx.Args[1:], " "))
+	var result string
+
+	switch subcommand {
+	case "on", "off", "enable", "disable":
+		config.LeaveEnabled, _ = parseToggle(subcommand)
+		result = "Goodbye messages " + enabledWord(config.LeaveEnabled)
+
+	case "channel":
+		if value == "" {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "goodbye channel <#channel|welcome>`")
+			return nil
+		}
+		if strings.EqualFold(value, "welcome") || strings.EqualFold(value, "none") {
+			config.LeaveChannelID = ""
+			result = "Goodbye messages will use the welcome channel"
+			break
+		}
+		channelID := parseChannelID(value)
+		channel, err := ctx.Session.Channel(channelID)
+		if err != nil || channel.GuildID != ctx.Message.GuildID {
+			ctx.Reply("❌ Invalid channel! Please mention a valid channel in this server.")
+			return nil
+		}
+		config.LeaveChannelID = channelID
+		result = fmt.Sprintf("Goodbye messages will be posted in <#%s>", channelID)
+
+	case "message", "msg":
+		if value == "" {
+			ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + "goodbye message <text>`\nPlaceholders: " + welcomePlaceholders)
+			return nil
+		}
+		config.LeaveMessage = value
+		result = "Goodbye message updated"
+
+	case "preview", "test":
+		name, count := guildInfo(ctx)
+		_, err := ctx.ReplyComplex(BuildWelcomeMessage(config, true, ctx.Message.Author, name, count))
+		return err
+
+	default:
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	if err := c.Welcome.SaveWelcomeConfig(config); err != nil {
+		ctx.Reply("❌ Failed to save goodbye settings: " + err.Error())
+		return err
+	}
+
+	_, err := ctx.Reply("✅ " + result)
+	return err
+}
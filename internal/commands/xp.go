@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/bwmarrin/discordgo"
+	"yuno-go/internal/leveling"
 )
 
 // DBInterface defines what commands need from the database
@@ -14,7 +15,9 @@ type DBInterface interface {
 }
 
 // XPCommand shows XP information
-type XPCommand struct{}
+type XPCommand struct {
+	DB XPDatabase
+}
 
 func (c *XPCommand) Name() string        { return "xp" }
 func (c *XPCommand) Aliases() []string   { return []string{"rank", "level", "exp"} }
@@ -22,6 +25,9 @@ func (c *XPCommand) Description() string { return "Show your XP and level" }
 func (c *XPCommand) Usage() string       { return "xp [@user]" }
 func (c *XPCommand) RequiredPermissions() []int64 { return nil }
 func (c *XPCommand) MasterOnly() bool    { return false }
+func (c *XPCommand) Arguments() []Argument {
+	return []Argument{{Name: "user", Description: "Member to look up", Type: ArgUser}}
+}
 
 func (c *XPCommand) Execute(ctx *Context) error {
 	if ctx.Message == nil || ctx.Message.GuildID == "" {
@@ -35,29 +41,19 @@ func (c *XPCommand) Execute(ctx *Context) error {
 	}
 
 	if targetUser.Bot {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "🤖 Bots don't have XP!")
+		ctx.Reply("🤖 Bots don't have XP!")
 		return nil
 	}
 
-	// Get database from bot
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
-
-	// Query XP data directly from database
-	var xp, level int
-	err := db.QueryRow(`
-		SELECT exp, level FROM glevel 
-		WHERE guild_id = ? AND user_id = ?`,
-		ctx.Message.GuildID, targetUser.ID).Scan(&xp, &level)
-	
+	xp, _, err := c.DB.GetUserXP(ctx.Message.GuildID, targetUser.ID)
 	if err != nil {
 		// User not found - they have 0 XP
 		xp = 0
-		level = 0
 	}
 
-	// Calculate XP needed for next level
-	neededXP := 5*level*level + 50*level + 100
-	remaining := neededXP - xp
+	// Level and progress come from the guild's curve
+	level, into, span := leveling.Progress(c.DB.LevelCurve(ctx.Message.GuildID), xp)
+	remaining := span - into
 
 	// Get avatar URL
 	avatarURL := targetUser.AvatarURL("256")
@@ -77,23 +73,25 @@ func (c *XPCommand) Execute(ctx *Context) error {
 			},
 			{
 				Name:   "Current exp",
-				Value:  strconv.Itoa(xp),
+				Value:  strconv.FormatInt(xp, 10),
 				Inline: true,
 			},
 			{
 				Name:   fmt.Sprintf("Exp needed until next level (%d)", level+1),
-				Value:  strconv.Itoa(remaining),
+				Value:  strconv.FormatInt(remaining, 10),
 				Inline: false,
 			},
 		},
 	}
 
-	_, err = ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+	_, err = ctx.ReplyEmbed(embed)
 	return err
 }
 
 // SetLevelCommand sets a user's level (master only)
-type SetLevelCommand struct{}
+type SetLevelCommand struct {
+	DB XPDatabase
+}
 
 func (c *SetLevelCommand) Name() string        { return "set-level" }
 func (c *SetLevelCommand) Aliases() []string   { return []string{"slvl", "setlevel"} }
@@ -108,14 +106,14 @@ func (c *SetLevelCommand) Execute(ctx *Context) error {
 	}
 
 	if len(ctx.Args) < 1 {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "❌ Usage: `set-level <level> [@user]`")
+		ctx.Reply("❌ Usage: `"+ctx.GetPrefix()+"set-level <level> [@user]`")
 		return nil
 	}
 
 	// Parse level
 	level, err := strconv.Atoi(ctx.Args[0])
-	if err != nil || level < 0 {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "❌ Level must be a positive number")
+	if err != nil || level < 0 || level > leveling.MaxLevel {
+		ctx.Reply(fmt.Sprintf("❌ Level must be a number between 0 and %d", leveling.MaxLevel))
 		return nil
 	}
 
@@ -126,23 +124,18 @@ func (c *SetLevelCommand) Execute(ctx *Context) error {
 	}
 
 	if targetUser.Bot {
-		ctx.Session.ChannelMessageSend(ctx.Message.ChannelID, "🤖 Bots don't have XP!")
+		ctx.Reply("🤖 Bots don't have XP!")
 		return nil
 	}
 
-	// Get database from bot
-	db := ctx.Bot.(interface{ GetDB() DBInterface }).GetDB()
-
-	// Set level with 0 XP
-	_, err = db.Exec(`INSERT OR REPLACE INTO glevel (guild_id, user_id, exp, level, enabled) 
-		VALUES (?, ?, 0, ?, 'enabled')`,
-		ctx.Message.GuildID, targetUser.ID, level)
-	
-	if err != nil {
+	// Move XP to the start of the level so the next XP grant doesn't undo it
+	curve := c.DB.LevelCurve(ctx.Message.GuildID)
+	exp := curve.ExpForLevel(level)
+	if err := c.DB.SetUserXP(ctx.Message.GuildID, targetUser.ID, exp, level); err != nil {
 		return err
 	}
 
-	neededXP := 5*level*level + 50*level + 100
+	neededXP := curve.ExpForLevel(level+1) - exp
 
 	// Create response embed
 	embed := &discordgo.MessageEmbed{
@@ -160,17 +153,17 @@ func (c *SetLevelCommand) Execute(ctx *Context) error {
 			},
 			{
 				Name:   "Current exp",
-				Value:  "0",
+				Value:  strconv.FormatInt(exp, 10),
 				Inline: true,
 			},
 			{
 				Name:   fmt.Sprintf("Exp needed until next level (%d)", level+1),
-				Value:  strconv.Itoa(neededXP),
+				Value:  strconv.FormatInt(neededXP, 10),
 				Inline: false,
 			},
 		},
 	}
 
-	_, err = ctx.Session.ChannelMessageSendEmbed(ctx.Message.ChannelID, embed)
+	_, err = ctx.ReplyEmbed(embed)
 	return err
 }
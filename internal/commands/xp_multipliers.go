@@ -0,0 +1,265 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// XP multiplier targets
+const (
+	MultiplierChannel = "channel"
+	MultiplierRole    = "role"
+)
+
+// maxXPMultiplier caps multipliers so a typo can't flood the leaderboard
+const maxXPMultiplier = 10
+
+// XPMultiplier scales XP earned in a channel or by members with a role.
+// A multiplier of 0 makes the channel (or role) a no-XP zone.
+type XPMultiplier struct {
+	GuildID    string
+	TargetType string
+	TargetID   string
+	Multiplier float64
+}
+
+// XPMultiplierStore reads and writes a guild's XP multipliers
+type XPMultiplierStore interface {
+	GetXPMultipliers(guildID string) []XPMultiplier
+	SetXPMultiplier(multiplier *XPMultiplier) error
+	RemoveXPMultiplier(guildID, targetType, targetID string) (bool, error)
+}
+
+// ResolveXPMultiplier combines a guild's multipliers for one grant. channelIDs
+// is the channel XP was earned in followed by its parents (thread → channel →
+// category); the closest configured one applies. Among the member's roles the
+// highest multiplier applies, except that any 0x role blocks XP entirely.
+func ResolveXPMultiplier(multipliers []XPMultiplier, channelIDs, roleIDs []string) float64 {
+	if len(multipliers) == 0 {
+		return 1
+	}
+
+	channels := make(map[string]float64)
+	roles := make(map[string]float64)
+	for _, m := range multipliers {
+		if m.TargetType == MultiplierRole {
+			roles[m.TargetID] = m.Multiplier
+		} else {
+			channels[m.TargetID] = m.Multiplier
+		}
+	}
+
+	result := 1.0
+	for _, channelID := range channelIDs {
+		if value, ok := channels[channelID]; ok {
+			result = value
+			break
+		}
+	}
+
+	roleMultiplier, matched := 0.0, false
+	for _, roleID := range roleIDs {
+		value, ok := roles[roleID]
+		if !ok {
+			continue
+		}
+		if value == 0 {
+			return 0
+		}
+		if !matched || value > roleMultiplier {
+			roleMultiplier, matched = value, true
+		}
+	}
+	if matched {
+		result *= roleMultiplier
+	}
+
+	return result
+}
+
+// XPMultiplierCommand lists and edits XP multipliers
+type XPMultiplierCommand struct {
+	Multipliers XPMultiplierStore
+}
+
+func (c *XPMultiplierCommand) Name() string      { return "xp-multiplier" }
+func (c *XPMultiplierCommand) Aliases() []string { return []string{"xpmult", "xp-boost"} }
+func (c *XPMultiplierCommand) Description() string {
+	return "Set XP multipliers for channels and roles"
+}
+func (c *XPMultiplierCommand) Usage() string {
+	return "xp-multiplier [<#channel|@role> <multiplier|off|reset>]"
+}
+func (c *XPMultiplierCommand) RequiredPermissions() []int64 {
+	return []int64{discordgo.PermissionManageGuild}
+}
+func (c *XPMultiplierCommand) MasterOnly() bool { return false }
+func (c *XPMultiplierCommand) Arguments() []Argument {
+	return []Argument{
+		{Name: "target", Description: "Channel or role (mention or ID)", Type: ArgString},
+		{Name: "multiplier", Description: "e.g. 2, 1.5, 0.5; off for no XP; reset to remove", Type: ArgString},
+	}
+}
+
+func (c *XPMultiplierCommand) Execute(ctx *Context) error {
+	if ctx.Message == nil || ctx.Message.GuildID == "" {
+		return fmt.Errorf("this command can only be used in a server")
+	}
+
+	guildID := ctx.Message.GuildID
+
+	if len(ctx.Args) == 0 {
+		return c.showList(ctx)
+	}
+	if len(ctx.Args) != 2 {
+		ctx.Reply("❌ Usage: `" + ctx.GetPrefix() + c.Usage() + "`")
+		return nil
+	}
+
+	targetType, targetID, ok := resolveMultiplierTarget(ctx, ctx.Args[0])
+	if !ok {
+		ctx.Reply("❌ Please mention a channel or role in this server (or give its ID)")
+		return nil
+	}
+	mention := multiplierTargetMention(targetType, targetID)
+
+	value := strings.TrimSuffix(strings.ToLower(ctx.Args[1]), "x")
+	switch value {
+	case "reset", "remove", "default":
+		removed, err := c.Multipliers.RemoveXPMultiplier(guildID, targetType, targetID)
+		if err != nil {
+			ctx.Reply("❌ Failed to remove multiplier: " + err.Error())
+			return err
+		}
+		if !removed {
+			_, err = ctx.Reply(fmt.Sprintf("%s has no XP multiplier", mention))
+			return err
+		}
+		_, err = ctx.Reply(fmt.Sprintf("✅ Removed the XP multiplier for %s", mention))
+		return err
+	case "off", "none", "noxp":
+		value = "0"
+	}
+
+	multiplier, err := strconv.ParseFloat(value, 64)
+	if err != nil || multiplier < 0 || multiplier > maxXPMultiplier {
+		ctx.Reply(fmt.Sprintf("❌ Multiplier must be a number between 0 and %d (0 = no XP)", maxXPMultiplier))
+		return nil
+	}
+
+	err = c.Multipliers.SetXPMultiplier(&XPMultiplier{
+		GuildID:    guildID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Multiplier: multiplier,
+	})
+	if err != nil {
+		ctx.Reply("❌ Failed to save multiplier: " + err.Error())
+		return err
+	}
+
+	if multiplier == 0 {
+		_, err = ctx.Reply(fmt.Sprintf("✅ %s no longer earns XP", mention))
+		return err
+	}
+	_, err = ctx.Reply(fmt.Sprintf("✅ %s now earns **%sx** XP", mention, formatMultiplier(multiplier)))
+	return err
+}
+
+func (c *XPMultiplierCommand) showList(ctx *Context) error {
+	multipliers := c.Multipliers.GetXPMultipliers(ctx.Message.GuildID)
+	sort.Slice(multipliers, func(i, j int) bool {
+		return multipliers[i].Multiplier > multipliers[j].Multiplier
+	})
+
+	var channels, roles []string
+	for _, m := range multipliers {
+		line := fmt.Sprintf("%s — **%sx**", multiplierTargetMention(m.TargetType, m.TargetID), formatMultiplier(m.Multiplier))
+		if m.Multiplier == 0 {
+			line = fmt.Sprintf("%s — **no XP**", multiplierTargetMention(m.TargetType, m.TargetID))
+		}
+		if m.TargetType == MultiplierRole {
+			roles = append(roles, line)
+		} else {
+			channels = append(channels, line)
+		}
+	}
+
+	orNone := func(lines []string) string {
+		if len(lines) == 0 {
+			return "None"
+		}
+		return truncateField(strings.Join(lines, "\n"))
+	}
+
+	_, err := ctx.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "XP Multipliers",
+		Description: "Channel multipliers (closest of thread, channel, category) are multiplied by the member's best role multiplier. Any no-XP role blocks XP.",
+		Color:       0xFF51FF,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Channels", Value: orNone(channels), Inline: false},
+			{Name: "Roles", Value: orNone(roles), Inline: false},
+		},
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Usage: " + ctx.GetPrefix() + c.Usage(),
+		},
+	})
+	return err
+}
+
+// resolveMultiplierTarget works out whether arg names a channel or a role
+func resolveMultiplierTarget(ctx *Context, arg string) (targetType, targetID string, ok bool) {
+	guildID := ctx.Message.GuildID
+
+	switch {
+	case strings.HasPrefix(arg, "<#"):
+		targetID = parseChannelID(arg)
+		targetType = MultiplierChannel
+	case strings.HasPrefix(arg, "<@&"):
+		targetID = parseRoleID(arg)
+		targetType = MultiplierRole
+	default:
+		targetID = arg
+	}
+	if !isSnowflake(targetID) {
+		return "", "", false
+	}
+
+	if targetType != MultiplierChannel {
+		if _, err := ctx.Session.State.Role(guildID, targetID); err == nil {
+			return MultiplierRole, targetID, true
+		}
+		if targetType == MultiplierRole {
+			return "", "", false
+		}
+	}
+
+	channel, err := ctx.Session.Channel(targetID)
+	if err != nil || channel.GuildID != guildID {
+		return "", "", false
+	}
+	return MultiplierChannel, targetID, true
+}
+
+func multiplierTargetMention(targetType, targetID string) string {
+	if targetType == MultiplierRole {
+		return "<@&" + targetID + ">"
+	}
+	return "<#" + targetID + ">"
+}
+
+func formatMultiplier(multiplier float64) string {
+	return strconv.FormatFloat(multiplier, 'f', -1, 64)
+}
+
+// truncateField keeps an embed field value under Discord's 1024 character limit
+func truncateField(value string) string {
+	if len(value) > 1024 {
+		return value[:1021] + "..."
+	}
+	return value
+}
@@ -0,0 +1,233 @@
+// Package leveling holds the XP → level curves. Every place that turns XP into
+// a level (message XP, voice XP, the xp card, mass XP tools, level syncs) goes
+// through a Curve so a guild's choice of formula is applied consistently.
+package leveling
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Curve types
+const (
+	Triangular = "triangular"
+	MEE6       = "mee6"
+	Linear     = "linear"
+	Polynomial = "polynomial"
+)
+
+// MaxLevel caps every curve so a huge XP value can't loop forever
+const MaxLevel = 10000
+
+// maxParam keeps ExpForLevel(MaxLevel) well inside int64
+const maxParam = 1000000
+
+// Curve converts between total XP and levels
+type Curve interface {
+	// ExpForLevel is the total XP needed to reach a level
+	ExpForLevel(level int) int64
+	// LevelForExp is the level reached with a total amount of XP
+	LevelForExp(exp int64) int
+	// Describe renders the formula for humans
+	Describe() string
+}
+
+// Settings is the stored form of a guild's curve
+type Settings struct {
+	Curve string
+	A     float64
+	B     float64
+	C     float64
+}
+
+// DefaultSettings returns the parameters a curve type uses when none are given
+func DefaultSettings(curve string) Settings {
+	switch curve {
+	case MEE6:
+		return Settings{Curve: MEE6, A: 5, B: 50, C: 100}
+	case Linear:
+		return Settings{Curve: Linear, A: 100}
+	case Polynomial:
+		return Settings{Curve: Polynomial, A: 5, B: 50, C: 100}
+	default:
+		// The original yuno formula: floor((sqrt(1 + 8xp/50) - 1) / 2)
+		return Settings{Curve: Triangular, A: 50}
+	}
+}
+
+// IsCurve reports whether name is a known curve type
+func IsCurve(name string) bool {
+	switch name {
+	case Triangular, MEE6, Linear, Polynomial:
+		return true
+	}
+	return false
+}
+
+// New builds the curve described by settings, falling back to triangular
+func New(s Settings) Curve {
+	switch s.Curve {
+	case MEE6:
+		return polynomialCurve{a: 5, b: 50, c: 100, name: MEE6}
+	case Linear:
+		if s.A < 1 {
+			s.A = 100
+		}
+		return linearCurve{perLevel: s.A}
+	case Polynomial:
+		if s.A < 0 || s.B < 0 || s.C < 1 {
+			s = DefaultSettings(Polynomial)
+		}
+		return polynomialCurve{a: s.A, b: s.B, c: s.C, name: Polynomial}
+	default:
+		if s.A < 1 {
+			s.A = 50
+		}
+		return triangularCurve{step: s.A}
+	}
+}
+
+// ParseSettings reads a curve name and its optional parameters from command args
+func ParseSettings(name string, params []string) (Settings, error) {
+	name = strings.ToLower(name)
+	if !IsCurve(name) {
+		return Settings{}, fmt.Errorf("unknown curve %q (use %s, %s, %s or %s)", name, Triangular, MEE6, Linear, Polynomial)
+	}
+
+	s := DefaultSettings(name)
+	if name == MEE6 {
+		return s, nil
+	}
+
+	values := make([]float64, 0, len(params))
+	for _, p := range params {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil || math.IsNaN(v) || v < 0 || v > maxParam {
+			return Settings{}, fmt.Errorf("%q is not a valid curve parameter", p)
+		}
+		values = append(values, v)
+	}
+
+	switch name {
+	case Triangular, Linear:
+		if len(values) > 1 {
+			return Settings{}, fmt.Errorf("%s takes a single parameter", name)
+		}
+		if len(values) == 1 {
+			if values[0] < 1 {
+				return Settings{}, fmt.Errorf("the %s step must be at least 1", name)
+			}
+			s.A = values[0]
+		}
+	case Polynomial:
+		if len(values) != 0 && len(values) != 3 {
+			return Settings{}, fmt.Errorf("polynomial takes three parameters: a b c (XP per level = a·l² + b·l + c)")
+		}
+		if len(values) == 3 {
+			if values[2] < 1 {
+				return Settings{}, fmt.Errorf("the constant term c must be at least 1")
+			}
+			s.A, s.B, s.C = values[0], values[1], values[2]
+		}
+	}
+	return s, nil
+}
+
+// Progress splits a total XP amount into level, XP into that level and XP the level spans
+func Progress(c Curve, exp int64) (level int, into, span int64) {
+	level = c.LevelForExp(exp)
+	start := c.ExpForLevel(level)
+	return level, exp - start, c.ExpForLevel(level+1) - start
+}
+
+// searchLevel finds the highest level whose XP requirement is <= exp
+func searchLevel(c Curve, exp int64) int {
+	if exp <= 0 {
+		return 0
+	}
+	lo, hi := 0, MaxLevel
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if c.ExpForLevel(mid) <= exp {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo
+}
+
+func clampLevel(level int) int {
+	if level < 0 {
+		return 0
+	}
+	if level > MaxLevel {
+		return MaxLevel
+	}
+	return level
+}
+
+// triangularCurve: level L needs step·L(L+1)/2 XP, so each level costs step more than the last
+type triangularCurve struct {
+	step float64
+}
+
+func (t triangularCurve) ExpForLevel(level int) int64 {
+	l := float64(clampLevel(level))
+	return int64(t.step * l * (l + 1) / 2)
+}
+
+func (t triangularCurve) LevelForExp(exp int64) int {
+	return searchLevel(t, exp)
+}
+
+func (t triangularCurve) Describe() string {
+	return fmt.Sprintf("Triangular — level L needs %s·L(L+1)/2 XP", formatParam(t.step))
+}
+
+// linearCurve: every level costs the same amount of XP
+type linearCurve struct {
+	perLevel float64
+}
+
+func (l linearCurve) ExpForLevel(level int) int64 {
+	return int64(l.perLevel * float64(clampLevel(level)))
+}
+
+func (l linearCurve) LevelForExp(exp int64) int {
+	return searchLevel(l, exp)
+}
+
+func (l linearCurve) Describe() string {
+	return fmt.Sprintf("Linear — %s XP per level", formatParam(l.perLevel))
+}
+
+// polynomialCurve: going from level l to l+1 costs a·l² + b·l + c XP (MEE6 is 5, 50, 100)
+type polynomialCurve struct {
+	a, b, c float64
+	name    string
+}
+
+func (p polynomialCurve) ExpForLevel(level int) int64 {
+	// Closed form of Σ_{l=0}^{L-1} (a·l² + b·l + c)
+	l := float64(clampLevel(level))
+	return int64(p.a*(l-1)*l*(2*l-1)/6 + p.b*l*(l-1)/2 + p.c*l)
+}
+
+func (p polynomialCurve) LevelForExp(exp int64) int {
+	return searchLevel(p, exp)
+}
+
+func (p polynomialCurve) Describe() string {
+	formula := fmt.Sprintf("%s·l² + %s·l + %s XP per level", formatParam(p.a), formatParam(p.b), formatParam(p.c))
+	if p.name == MEE6 {
+		return "MEE6 — " + formula
+	}
+	return "Polynomial — " + formula
+}
+
+func formatParam(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
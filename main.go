@@ -31,7 +31,8 @@ func displayStartupBanner() {
 		// Fallback if file doesn't exist
 		fmt.Println("\n╔══════════════════════════════════════╗")
 		fmt.Println("║             YUNO BOT GO              ║")
-		fmt.Println("╚══════════════════════════════════════╝\n")
+		fmt.Println("╚══════════════════════════════════════╝")
+		fmt.Println()
 		return
 	}
 